@@ -45,6 +45,12 @@ func LoadAssets() error {
 
 	return nil
 }
+
+// LoadHeadlessAssets loads only the images, which size the entities, for games run without a window or audio
+func LoadHeadlessAssets() error {
+	return loadImages()
+}
+
 func loadFonts() error {
 	s, err := text.NewGoTextFaceSource(bytes.NewReader(fonts.MPlus1pRegular_ttf))
 	if err != nil {
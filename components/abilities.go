@@ -0,0 +1,253 @@
+package components
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+
+	"tower-defense/assets"
+	"tower-defense/config"
+	"tower-defense/util"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"github.com/yohamta/donburi"
+)
+
+type AbilityKind int
+
+const (
+	Airstrike AbilityKind = iota
+	Freeze
+	Repair
+)
+
+var abilityNames = []string{"Airstrike", "Freeze", "Repair"}
+
+// hotkeys for each ability, the airstrike lands on the cursor
+var abilityKeys = []ebiten.Key{ebiten.KeyA, ebiten.KeyZ, ebiten.KeyE}
+var abilityKeyLabels = []string{"A", "Z", "E"}
+var abilitySounds = []string{"explosion", "shoot3", "shoot1"}
+
+const (
+	abilityButtonWidth  = 120
+	abilityButtonHeight = 18
+	abilityButtonGap    = 4
+	// game ticks the airstrike blast stays drawn
+	strikeFlashTicks = 10
+)
+
+var abilityReadyColor = color.RGBA{30, 90, 40, 255}
+var abilityWaitingColor = color.RGBA{60, 60, 60, 255}
+var strikeColor = color.RGBA{255, 140, 40, 255}
+var strikeFlashColor = color.RGBA{255, 140, 40, 96}
+
+// AbilitiesData is the cooldowns of the base's active abilities, the cooldowns tick at game speed
+type AbilitiesData struct {
+	// Cooldowns are the game ticks left before each ability can be used again, synced for the HUD
+	Cooldowns []int
+	// Strike is where the last airstrike landed, drawn while StrikeFlash ticks down
+	Strike      image.Point
+	StrikeFlash int
+	timers      []*util.CooldownTimer
+	// aiming is set by the airstrike button, the next click on the board calls the strike in
+	aiming bool
+}
+
+var Abilities = donburi.NewComponentType[AbilitiesData]()
+
+func (k AbilityKind) String() string {
+	if k < 0 || int(k) >= len(abilityNames) {
+		return ""
+	}
+	return abilityNames[k]
+}
+
+func NewAbilitiesData(balance *config.BalanceData) *AbilitiesData {
+	a := &AbilitiesData{Cooldowns: make([]int, len(abilityNames)), timers: make([]*util.CooldownTimer, len(abilityNames))}
+	for i := range abilityNames {
+		_, cooldown := abilityCost(balance, AbilityKind(i))
+		a.timers[i] = util.NewCooldownTimer(cooldown)
+	}
+	return a
+}
+
+// abilityCost is the money and cooldown of the ability in the balance
+func abilityCost(balance *config.BalanceData, kind AbilityKind) (int, int) {
+	abilities := balance.Player.Abilities
+	switch kind {
+	case Airstrike:
+		return abilities.Airstrike.Cost, abilities.Airstrike.Cooldown
+	case Freeze:
+		return abilities.Freeze.Cost, abilities.Freeze.Cooldown
+	case Repair:
+		return abilities.Repair.Cost, abilities.Repair.Cooldown
+	}
+	return 0, 0
+}
+
+// Ready is true when the ability is off cooldown
+func (a *AbilitiesData) Ready(kind AbilityKind) bool {
+	return a.Cooldowns[kind] == 0
+}
+
+// Update ticks the cooldowns and the airstrike flash
+func (a *AbilitiesData) Update() {
+	for i, timer := range a.timers {
+		timer.IncrementTicker()
+		timer.CheckCooldown()
+		a.Cooldowns[i] = timer.GetDisplay()
+	}
+	a.StrikeFlash = max(a.StrikeFlash-1, 0)
+}
+
+// CanUseAbility is true when the ability is off cooldown and the player can pay for it
+func (p *PlayerData) CanUseAbility(entry *donburi.Entry, kind AbilityKind) bool {
+	cost, _ := abilityCost(config.GetBalance(entry.World), kind)
+	return Abilities.Get(entry).Ready(kind) && p.Money >= cost
+}
+
+// TryUseAbility uses the ability if it is ready and the player has the money, x, y is where an airstrike lands.
+// Freeze without creeps and repair without damaged towers do nothing and cost nothing.
+func (p *PlayerData) TryUseAbility(entry *donburi.Entry, kind AbilityKind, x, y int, sound, debug bool) (bool, error) {
+	abilities := Abilities.Get(entry)
+	cost, _ := abilityCost(config.GetBalance(entry.World), kind)
+	if !p.CanUseAbility(entry, kind) {
+		if debug {
+			fmt.Printf("Can't use %v cost %v, remaining %v, cooldown %v\n", kind, cost, p.Money, abilities.Cooldowns[kind])
+		}
+		if sound {
+			assets.PlaySound("invalid2")
+		}
+		return false, nil
+	}
+	var used bool
+	switch kind {
+	case Airstrike:
+		// the strike is called in even if it misses
+		if _, err := abilities.airstrike(entry, x, y); err != nil {
+			return false, err
+		}
+		used = true
+	case Freeze:
+		used = freezeCreeps(entry.World) > 0
+	case Repair:
+		used = repairTowers(entry.World) > 0
+	}
+	if !used {
+		return false, nil
+	}
+	if debug {
+		fmt.Printf("used %v for %v\n", kind, cost)
+	}
+	p.Money -= cost
+	GetGameStats().UpdateStat("MoneySpent", cost)
+	GetGameStats().IncrementStat("AbilitiesUsed")
+	abilities.timers[kind].StartCooldown()
+	abilities.Cooldowns[kind] = abilities.timers[kind].GetDisplay()
+	if sound {
+		assets.PlaySound(abilitySounds[kind])
+	}
+	return true, nil
+}
+
+// airstrike blasts every creep, flying or not, within the balance radius of x, y, returns the number hit
+func (a *AbilitiesData) airstrike(entry *donburi.Entry, x, y int) (int, error) {
+	strike := config.GetBalance(entry.World).Player.Abilities.Airstrike
+	damageType, _ := ParseDamageType(strike.DamageType)
+	attack := &AttackData{Power: strike.Power, AreaFalloff: strike.Falloff, DamageType: damageType, AntiAir: true}
+	a.Strike = image.Pt(x, y)
+	a.StrikeFlash = strikeFlashTicks
+	return attack.AttackArea(entry, image.Rect(x, y, x, y), strike.Radius, func(entry, enemy *donburi.Entry) {
+		OnKillCreep(entry, enemy)
+		GetGameStats().IncrementStat("AirstrikeKills")
+	}, Creep)
+}
+
+// freezeCreeps stuns every creep on the board, returns the number frozen
+func freezeCreeps(world donburi.World) int {
+	balance := config.GetBalance(world)
+	freeze := AttackEffect{Kind: Stun, Duration: balance.Player.Abilities.Freeze.Duration}
+	frozen := 0
+	StatusEffects.Each(world, func(entry *donburi.Entry) {
+		if !entry.HasComponent(Creep) {
+			return
+		}
+		StatusEffects.Get(entry).Apply(balance.Effects, freeze)
+		frozen++
+	})
+	GetGameStats().UpdateStat("CreepsFrozen", frozen)
+	return frozen
+}
+
+// repairTowers heals every damaged tower by the balance percent of its max health, returns the number repaired
+func repairTowers(world donburi.World) int {
+	percent := config.GetBalance(world).Player.Abilities.Repair.HealPercent
+	repaired := 0
+	Tower.Each(world, func(entry *donburi.Entry) {
+		health := Health.Get(entry)
+		if health.Health >= health.MaxHealth {
+			return
+		}
+		health.Health = min(health.Health+max(health.MaxHealth*percent/100, 1), health.MaxHealth)
+		repaired++
+	})
+	GetGameStats().UpdateStat("TowersRepaired", repaired)
+	return repaired
+}
+
+// abilityButtonRect is the HUD button for the ability, the buttons sit in a row below the right of the base
+func abilityButtonRect(board *BoardData, kind AbilityKind) image.Rectangle {
+	x := board.Width - (len(abilityNames)-int(kind))*(abilityButtonWidth+abilityButtonGap)
+	y := board.Height - abilityButtonHeight - 2
+	return image.Rect(x, y, x+abilityButtonWidth, y+abilityButtonHeight)
+}
+
+// abilityButtonAt finds the ability whose HUD button is under x, y
+func abilityButtonAt(board *BoardData, x, y int) (AbilityKind, bool) {
+	for i := range abilityNames {
+		if image.Pt(x, y).In(abilityButtonRect(board, AbilityKind(i))) {
+			return AbilityKind(i), true
+		}
+	}
+	return 0, false
+}
+
+// Draw draws the ability buttons with their cost or remaining cooldown, the last airstrike and where the next one is aimed
+func (a *AbilitiesData) Draw(screen *ebiten.Image, entry *donburi.Entry) {
+	board := Board.Get(Board.MustFirst(entry.World))
+	balance := config.GetBalance(entry.World)
+	money := Player.Get(entry).Money
+	for i, name := range abilityNames {
+		kind := AbilityKind(i)
+		rect := abilityButtonRect(board, kind)
+		cost, _ := abilityCost(balance, kind)
+		fill := abilityReadyColor
+		str := fmt.Sprintf("%s %s $%d", abilityKeyLabels[i], name, cost)
+		if !a.Ready(kind) {
+			fill = abilityWaitingColor
+			str = fmt.Sprintf("%s %s %d", abilityKeyLabels[i], name, a.Cooldowns[i])
+		} else if money < cost {
+			fill = abilityWaitingColor
+		}
+		vector.DrawFilledRect(screen, float32(rect.Min.X), float32(rect.Min.Y), float32(rect.Dx()), float32(rect.Dy()), fill, false)
+		if kind == Airstrike && a.aiming {
+			vector.StrokeRect(screen, float32(rect.Min.X), float32(rect.Min.Y), float32(rect.Dx()), float32(rect.Dy()), 1, strikeColor, false)
+		}
+		op := &text.DrawOptions{}
+		op.PrimaryAlign = text.AlignCenter
+		op.SecondaryAlign = text.AlignCenter
+		op.GeoM.Translate(float64(rect.Min.X+rect.Dx()/2), float64(rect.Min.Y+rect.Dy()/2))
+		text.Draw(screen, str, assets.InfoFace, op)
+	}
+
+	radius := float32(balance.Player.Abilities.Airstrike.Radius)
+	if a.StrikeFlash > 0 {
+		vector.DrawFilledCircle(screen, float32(a.Strike.X), float32(a.Strike.Y), radius, strikeFlashColor, true)
+	}
+	if a.aiming {
+		x, y := ebiten.CursorPosition()
+		vector.StrokeCircle(screen, float32(x), float32(y), radius, 1, strikeColor, true)
+	}
+}
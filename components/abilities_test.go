@@ -0,0 +1,118 @@
+package components
+
+import (
+	"testing"
+
+	"tower-defense/config"
+
+	"github.com/yohamta/donburi"
+)
+
+func newAbilitiesTestPlayer(t *testing.T, world donburi.World, money int) (*donburi.Entry, *PlayerData) {
+	t.Helper()
+	pe := Player.MustFirst(world)
+	pe.AddComponent(Abilities)
+	Abilities.Set(pe, NewAbilitiesData(config.GetBalance(world)))
+	player := Player.Get(pe)
+	player.Money = money
+	return pe, player
+}
+
+func TestPlayerData_TryUseAbilityAirstrike(t *testing.T) {
+	world := newAttackTestWorld(t)
+	pe, player := newAbilitiesTestPlayer(t, world, 100)
+
+	// the default airstrike does 12 explosive damage falling off by half at its 40 pixel radius
+	center := newAttackTestCreep(world, 0, 0, 20)
+	flyer := newAttackTestCreep(world, 20, 0, 20)
+	flyer.AddComponent(Flying)
+	killed := newAttackTestCreep(world, 0, 20, 5)
+	outside := newAttackTestCreep(world, 100, 0, 20)
+
+	if used, err := player.TryUseAbility(pe, Airstrike, 5, 5, false, false); err != nil || !used {
+		t.Fatalf("TryUseAbility(Airstrike) = %v, %v, want true", used, err)
+	}
+	if got := Health.Get(center).Health; got != 8 {
+		t.Errorf("center creep health = %v, want 8", got)
+	}
+	if got := Health.Get(flyer).Health; got != 10 {
+		t.Errorf("flying creep health = %v, want 10", got)
+	}
+	if killed.Valid() {
+		t.Error("creep in the blast survived, want killed")
+	}
+	if got := Health.Get(outside).Health; got != 20 {
+		t.Errorf("outside creep health = %v, want 20", got)
+	}
+	// 40 for the strike and 10 back for the kill
+	if player.Money != 70 {
+		t.Errorf("money = %v, want 70", player.Money)
+	}
+	stats := GetGameStats()
+	if stats.GetStat("AirstrikeKills") != 1 || stats.GetStat("AbilitiesUsed") != 1 {
+		t.Errorf("AirstrikeKills = %v, AbilitiesUsed = %v, want 1, 1", stats.GetStat("AirstrikeKills"), stats.GetStat("AbilitiesUsed"))
+	}
+
+	// on cooldown until the game ticks run it down
+	if used, _ := player.TryUseAbility(pe, Airstrike, 5, 5, false, false); used {
+		t.Fatal("TryUseAbility(Airstrike) on cooldown = true, want false")
+	}
+	abilities := Abilities.Get(pe)
+	for range config.GetBalance(world).Player.Abilities.Airstrike.Cooldown {
+		abilities.Update()
+	}
+	if !abilities.Ready(Airstrike) || player.Money != 70 {
+		t.Errorf("after the cooldown ready = %v money = %v, want ready with 70", abilities.Ready(Airstrike), player.Money)
+	}
+}
+
+func TestPlayerData_TryUseAbilityFreezeAndRepair(t *testing.T) {
+	world := newAttackTestWorld(t)
+	pe, player := newAbilitiesTestPlayer(t, world, 200)
+	abilities := Abilities.Get(pe)
+
+	// nothing to freeze or repair costs nothing and starts no cooldown
+	froze, _ := player.TryUseAbility(pe, Freeze, 0, 0, false, false)
+	repaired, _ := player.TryUseAbility(pe, Repair, 0, 0, false, false)
+	if froze || repaired {
+		t.Fatal("TryUseAbility() with nothing to affect = true, want false")
+	}
+	if player.Money != 200 || !abilities.Ready(Freeze) || !abilities.Ready(Repair) {
+		t.Fatalf("money = %v freeze ready %v repair ready %v, want 200 and both ready", player.Money, abilities.Ready(Freeze), abilities.Ready(Repair))
+	}
+
+	creep := newAttackTestCreep(world, 50, 50, 10)
+	creep.AddComponent(StatusEffects)
+	if used, err := player.TryUseAbility(pe, Freeze, 0, 0, false, false); err != nil || !used {
+		t.Fatalf("TryUseAbility(Freeze) = %v, %v, want true", used, err)
+	}
+	if !StatusEffects.Get(creep).Has(Stun) {
+		t.Error("creep not stunned by freeze")
+	}
+
+	tower := newTestTower(t, world, 0, 0, "Ranged")
+	health := Health.Get(tower)
+	health.Health = 1
+	if used, err := player.TryUseAbility(pe, Repair, 0, 0, false, false); err != nil || !used {
+		t.Fatalf("TryUseAbility(Repair) = %v, %v, want true", used, err)
+	}
+	if want := 1 + health.MaxHealth/2; health.Health != want {
+		t.Errorf("tower health = %v, want %v", health.Health, want)
+	}
+	if player.Money != 40 || abilities.Ready(Freeze) || abilities.Ready(Repair) {
+		t.Errorf("money = %v freeze ready %v repair ready %v, want 40 and both on cooldown", player.Money, abilities.Ready(Freeze), abilities.Ready(Repair))
+	}
+}
+
+func Test_abilityButtonAt(t *testing.T) {
+	board := &BoardData{Width: 600, Height: 800}
+	if kind, ok := abilityButtonAt(board, 500, 790); !ok || kind != Repair {
+		t.Errorf("abilityButtonAt(500, 790) = %v, %v, want Repair", kind, ok)
+	}
+	if kind, ok := abilityButtonAt(board, 240, 790); !ok || kind != Airstrike {
+		t.Errorf("abilityButtonAt(240, 790) = %v, %v, want Airstrike", kind, ok)
+	}
+	if _, ok := abilityButtonAt(board, 10, 790); ok {
+		t.Error("abilityButtonAt(10, 790) found a button, want none")
+	}
+}
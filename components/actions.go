@@ -0,0 +1,39 @@
+package components
+
+// PlayerActionKind is something the player did, recorded in replays
+type PlayerActionKind string
+
+const (
+	// SelectTowerAction picks the tower type at Value in the balance order
+	SelectTowerAction PlayerActionKind = "select"
+	// ClickAction is a left click: placing or dropping a tower, choosing an upgrade branch, an ability button or an airstrike target
+	ClickAction    PlayerActionKind = "click"
+	HealAction     PlayerActionKind = "heal"
+	UpgradeAction  PlayerActionKind = "upgrade"
+	ResupplyAction PlayerActionKind = "resupply"
+	TargetAction   PlayerActionKind = "target"
+	SellAction     PlayerActionKind = "sell"
+	// MoveAction picks up the tower at the position, or puts back the one being moved
+	MoveAction PlayerActionKind = "move"
+	// AbilityAction uses the base ability of kind Value aimed at the position
+	AbilityAction PlayerActionKind = "ability"
+
+	// the battle handles these rather than the player
+
+	PauseAction PlayerActionKind = "pause"
+	// SpeedAction sets the game speed to Value
+	SpeedAction PlayerActionKind = "speed"
+	// SendSuperCreepAction sends a super creep to the other player
+	SendSuperCreepAction PlayerActionKind = "sendSuperCreep"
+	// SuperCreepAction is a super creep arriving from the other player
+	SuperCreepAction PlayerActionKind = "superCreep"
+)
+
+// PlayerAction is one thing the player did, at a board position for the actions that need one
+type PlayerAction struct {
+	Kind PlayerActionKind `json:"kind"`
+	X    int              `json:"x,omitempty"`
+	Y    int              `json:"y,omitempty"`
+	// Value is the tower type index, ability kind or game speed
+	Value int `json:"value,omitempty"`
+}
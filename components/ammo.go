@@ -0,0 +1,102 @@
+package components
+
+import (
+	"fmt"
+	"image/color"
+
+	"tower-defense/assets"
+	"tower-defense/config"
+	"tower-defense/util"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"github.com/yohamta/donburi"
+)
+
+// AmmoData is a tower's magazine when the balance separates ammo from health, synced so the info overlay can show it
+type AmmoData struct {
+	Ammo    int
+	MaxAmmo int
+	reload  *util.CooldownTimer
+}
+
+var Ammo = donburi.NewComponentType[AmmoData]()
+
+var ammoColor = color.RGBA{80, 160, 255, 255}
+
+func NewAmmoData(balance *config.BalanceData, typeBalance *config.TowerTypeBalance) *AmmoData {
+	return &AmmoData{Ammo: typeBalance.Ammo, MaxAmmo: typeBalance.Ammo, reload: util.NewCooldownTimer(balance.Tower.Ammo.ReloadInterval)}
+}
+
+func getTowerResupplyCost(world donburi.World, name string) int {
+	balance := config.GetBalance(world).Tower
+	return balance.Costs[name] / max(balance.Ammo.ResupplyCostDivisor, 1)
+}
+
+// Reload passively adds the balance reload amount every reload interval while the magazine isn't full
+func (a *AmmoData) Reload(world donburi.World) {
+	ammo := config.GetBalance(world).Tower.Ammo
+	if a.Ammo >= a.MaxAmmo || ammo.ReloadInterval <= 0 || ammo.ReloadAmount <= 0 {
+		return
+	}
+	if !a.reload.InCooldown {
+		a.reload.StartCooldown()
+	}
+	a.reload.IncrementTicker()
+	a.reload.CheckCooldown()
+	if !a.reload.InCooldown {
+		reloaded := min(ammo.ReloadAmount, a.MaxAmmo-a.Ammo)
+		a.Ammo += reloaded
+		GetGameStats().UpdateStat("AmmoReloaded", reloaded)
+	}
+}
+
+// consume uses a round for a shot
+func (a *AmmoData) consume() {
+	a.Ammo = max(a.Ammo-1, 0)
+	if a.Ammo == 0 {
+		GetGameStats().IncrementStat("TowersOutOfAmmo")
+	}
+}
+
+// TryResupplyTower refills the tower's magazine if it isn't full and the player has the money
+func (p *PlayerData) TryResupplyTower(entry *donburi.Entry, sound, debug bool) bool {
+	if !entry.HasComponent(Ammo) {
+		return false
+	}
+	ammo := Ammo.Get(entry)
+	if ammo.Ammo >= ammo.MaxAmmo {
+		return false
+	}
+	cost := getTowerResupplyCost(entry.World, Tower.Get(entry).Type)
+	if p.Money < cost {
+		if debug {
+			fmt.Printf("Not enough money to resupply tower cost %v, remaining %v\n", cost, p.Money)
+		}
+		if sound {
+			assets.PlaySound("invalid2")
+		}
+		return false
+	}
+	if debug {
+		fmt.Printf("tower resupplied from %v to %v\n", ammo.Ammo, ammo.MaxAmmo)
+	}
+	ammo.Ammo = ammo.MaxAmmo
+	p.Money -= cost
+	GetGameStats().UpdateStat("MoneySpent", cost)
+	GetGameStats().IncrementStat("TowersResupplied")
+	return true
+}
+
+// drawAmmoBar draws the magazine as a bar along the bottom inside the tower
+func (a *AmmoData) drawAmmoBar(screen *ebiten.Image, entry *donburi.Entry) {
+	if a.MaxAmmo <= 0 {
+		return
+	}
+	const barHeight = 3
+	rect := GetRect(entry)
+	y := float32(rect.Max.Y - barHeight)
+	percent := float32(a.Ammo) / float32(a.MaxAmmo)
+	vector.StrokeRect(screen, float32(rect.Min.X), y, float32(rect.Dx()), barHeight, 1, ammoColor, true)
+	vector.DrawFilledRect(screen, float32(rect.Min.X), y, float32(rect.Dx())*percent, barHeight, ammoColor, true)
+}
@@ -0,0 +1,112 @@
+package components
+
+import (
+	"testing"
+
+	"tower-defense/config"
+
+	"github.com/yohamta/donburi"
+	"github.com/yohamta/donburi/filter"
+)
+
+func newAmmoTestTower(t *testing.T) (donburi.World, *donburi.Entry) {
+	t.Helper()
+	world := newAttackTestWorld(t)
+	balance := *config.DefaultBalance()
+	balance.Tower.Ammo.SeparateAmmo = true
+	config.NewBalance(world, &balance)
+	return world, newTestTower(t, world, 0, 0, "Ranged")
+}
+
+func TestAfterTowerAttack_SeparateAmmoSparesHealth(t *testing.T) {
+	world, tower := newAmmoTestTower(t)
+	ammo := Ammo.Get(tower)
+	health := Health.Get(tower)
+	if ammo.Ammo != 20 || ammo.MaxAmmo != 20 {
+		t.Fatalf("ammo = %v/%v, want the Ranged magazine of 20/20", ammo.Ammo, ammo.MaxAmmo)
+	}
+
+	AfterTowerAttack(tower)
+	if ammo.Ammo != 19 || health.Health != 20 {
+		t.Errorf("after a shot ammo = %v health = %v, want 19 and 20", ammo.Ammo, health.Health)
+	}
+
+	// an empty tower stays on the board but holds its fire
+	ammo.Ammo = 1
+	AfterTowerAttack(tower)
+	if !tower.Valid() || ammo.Ammo != 0 {
+		t.Fatalf("after the last round valid = %v ammo = %v, want a valid tower with 0", tower.Valid(), ammo.Ammo)
+	}
+	if got := GetGameStats().GetStat("TowersOutOfAmmo"); got != 1 {
+		t.Errorf("TowersOutOfAmmo = %v, want 1", got)
+	}
+	newAttackTestCreep(world, 20, 0, 10)
+	if err := Tower.Get(tower).Update(tower); err != nil {
+		t.Fatal(err)
+	}
+	if donburi.NewQuery(filter.Contains(Bullet)).Count(world) != 0 {
+		t.Error("empty tower fired a bullet")
+	}
+
+	// the first tick above counted towards the default reload of 1 round every 60 ticks
+	for range 58 {
+		ammo.Reload(world)
+	}
+	if ammo.Ammo != 0 {
+		t.Fatalf("ammo after 59 ticks = %v, want 0", ammo.Ammo)
+	}
+	ammo.Reload(world)
+	if ammo.Ammo != 1 {
+		t.Errorf("ammo after 60 ticks = %v, want 1", ammo.Ammo)
+	}
+	if got := GetGameStats().GetStat("AmmoReloaded"); got != 1 {
+		t.Errorf("AmmoReloaded = %v, want 1", got)
+	}
+
+	Player.Get(Player.MustFirst(world)).TowerLevels = 20
+	if !Tower.Get(tower).Upgrade(tower, false) {
+		t.Fatal("Upgrade() = false, want true")
+	}
+	if ammo.Ammo != 25 || ammo.MaxAmmo != 25 {
+		t.Errorf("ammo after Upgrade() = %v/%v, want 25/25", ammo.Ammo, ammo.MaxAmmo)
+	}
+}
+
+func TestPlayerData_TryResupplyTower(t *testing.T) {
+	world, tower := newAmmoTestTower(t)
+	player := Player.Get(Player.MustFirst(world))
+	player.Money = 20
+	ammo := Ammo.Get(tower)
+
+	if player.TryResupplyTower(tower, false, false) {
+		t.Fatal("TryResupplyTower() with a full magazine = true, want false")
+	}
+
+	// resupply costs the tower cost of 50 divided by 4
+	ammo.Ammo = 3
+	if !player.TryResupplyTower(tower, false, false) {
+		t.Fatal("TryResupplyTower() = false, want true")
+	}
+	if ammo.Ammo != 20 || player.Money != 8 {
+		t.Errorf("after resupply ammo = %v money = %v, want 20 and 8", ammo.Ammo, player.Money)
+	}
+
+	ammo.Ammo = 0
+	if player.TryResupplyTower(tower, false, false) || ammo.Ammo != 0 {
+		t.Errorf("TryResupplyTower() without the money refilled to %v, want 0", ammo.Ammo)
+	}
+	if got := GetGameStats().GetStat("TowersResupplied"); got != 1 {
+		t.Errorf("TowersResupplied = %v, want 1", got)
+	}
+}
+
+func TestNewTower_HealthIsAmmoByDefault(t *testing.T) {
+	world := newAttackTestWorld(t)
+	tower, err := NewTower(world, 0, 0, "Ranged")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tower.HasComponent(Ammo) {
+		t.Error("tower has a separate magazine with the default balance, want health as ammo")
+	}
+}
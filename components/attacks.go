@@ -1,8 +1,11 @@
 package components
 
 import (
+	"fmt"
 	"image"
 	"image/color"
+	"math"
+	"slices"
 	"tower-defense/assets"
 	"tower-defense/config"
 	"tower-defense/util"
@@ -16,6 +19,8 @@ import (
 type HealthData struct {
 	Health    int
 	MaxHealth int
+	// Shield points are lost before health, given by support creeps
+	Shield int
 }
 type AttackType int
 
@@ -26,12 +31,51 @@ const (
 	RangedArea
 )
 
+var attackTypeNames = map[string]AttackType{
+	"MeleeSingle":  MeleeSingle,
+	"RangedSingle": RangedSingle,
+	"MeleeArea":    MeleeArea,
+	"RangedArea":   RangedArea,
+}
+
+// ParseAttackType converts a balance attack type name, empty defaults to RangedSingle
+func ParseAttackType(name string) (AttackType, error) {
+	if name == "" {
+		return RangedSingle, nil
+	}
+	attackType, ok := attackTypeNames[name]
+	if !ok {
+		return RangedSingle, fmt.Errorf("unknown attack type %q", name)
+	}
+	return attackType, nil
+}
+
+func (at AttackType) IsArea() bool {
+	return at == MeleeArea || at == RangedArea
+}
+
 type AttackData struct {
-	Power      int
-	Range      int
-	cooldown   *util.CooldownTimer
-	AttackType AttackType
-	noLead     bool
+	Power       int
+	Range       int
+	cooldown    *util.CooldownTimer
+	AttackType  AttackType
+	AreaRadius  int
+	AreaFalloff float64
+	Effect      AttackEffect
+	Hazard      HazardSpec
+	Targeting   TargetPriority
+	DamageType  DamageType
+	// AntiAir attacks can hit flying creeps as well as ground ones
+	AntiAir bool
+	// Projectile is how ranged attacks reach their target, Pierce the number of enemies a piercing round can hit
+	Projectile ProjectileKind
+	Pierce     int
+	// LastEffectiveness is the percent of power that got through to the last enemy attacked, 0 before the first attack.
+	// It is synced so viewers label towers with what the server actually targeted.
+	LastEffectiveness int
+	noLead            bool
+	// ignoreDefense damage goes straight through armor and resistances, like poison
+	ignoreDefense bool
 }
 
 type LevelData struct {
@@ -46,6 +90,8 @@ var Health = donburi.NewComponentType[HealthData]()
 var Level = donburi.NewComponentType[LevelData]()
 var RangeRender = donburi.NewComponentType[RangeRenderData]()
 
+var antiAirRangeColor = color.RGBA{120, 220, 255, 255}
+
 func NewHealthData(health int) *HealthData {
 	return &HealthData{Health: health, MaxHealth: health}
 }
@@ -58,6 +104,11 @@ func (a *AttackData) GetExpandedRect(e *donburi.Entry) image.Rectangle {
 	return rect
 }
 
+// CanTarget reports whether the attack can hit the enemy, flying creeps need an anti-air attack
+func (a *AttackData) CanTarget(enemy *donburi.Entry) bool {
+	return a.AntiAir || !enemy.HasComponent(Flying)
+}
+
 func (rr *RangeRenderData) Draw(screen *ebiten.Image, entry *donburi.Entry) {
 	config := config.GetConfig(entry.World)
 
@@ -66,29 +117,46 @@ func (rr *RangeRenderData) Draw(screen *ebiten.Image, entry *donburi.Entry) {
 		aRect := a.GetExpandedRect(entry)
 		aPt := util.MidpointRect(aRect)
 
-		vector.StrokeCircle(screen, float32(aPt.X), float32(aPt.Y), float32(aRect.Dx()/2), 1, color.White, true)
+		var clr color.Color = color.White
+		if a.AntiAir && entry.HasComponent(Tower) {
+			clr = antiAirRangeColor
+		}
+		if entry.HasComponent(Tower) && getTowerAura(entry).Active() {
+			clr = auraColor
+		}
+		vector.StrokeCircle(screen, float32(aPt.X), float32(aPt.Y), float32(aRect.Dx()/2), 1, clr, true)
+		if entry.HasComponent(AuraBuff) {
+			AuraBuff.Get(entry).drawAuraBuff(screen, aRect)
+		}
+		if entry.HasComponent(Creep) {
+			drawCreepBehaviors(screen, entry)
+		}
 		//vector.StrokeRect(screen, float32(aRect.Min.X), float32(aRect.Min.Y), float32(aRect.Dx()), float32(aRect.Dy()), 1, color.White, true)
 	}
 }
 
 func (a *AttackData) FindEnemyRange(entry *donburi.Entry, enemyType ...component.IComponentType) *donburi.Entry {
-	// query for enemies then find the closest one
+	// query for enemies in range then pick the best one for our targeting priority, falling back to the closest
 	aRect := a.GetExpandedRect(entry)
 	// this just sets an upper bounds on the distance
 	minDist := 2000.0
+	bestScore := 0
 	// maxRange := float64(aRect.Dx()/2 + aRect.Dy()/2)
 	var foundEnemy *donburi.Entry = nil
 	query := donburi.NewQuery(util.CreateOrFilter(enemyType...))
 	query.Each(entry.World, func(enemyEntry *donburi.Entry) {
 		// fmt.Printf("checking distance of %v\n", enemyEntry)
 		eRect := GetRect(enemyEntry)
+		if !aRect.Overlaps(eRect) || !a.CanTarget(enemyEntry) {
+			return
+		}
 
 		dist := util.DistanceRects(aRect, eRect)
-
-		// this will fire at the closest enemy but we might want to prioritize others such as lowest health or things below us
-		if aRect.Overlaps(eRect) && dist < minDist {
+		score := a.Targeting.score(enemyEntry)
+		if foundEnemy == nil || score > bestScore || (score == bestScore && dist < minDist) {
 			// fmt.Printf("enemy at distance %v\n", dist)
 			minDist = dist
+			bestScore = score
 			foundEnemy = enemyEntry
 			// fmt.Println("found enemy")
 		}
@@ -109,7 +177,7 @@ func (a *AttackData) FindEnemyIntersect(entry *donburi.Entry, enemyType ...compo
 		// fmt.Printf("checking distance of %v\n", enemyEntry)
 		eRect := GetRect(enemyEntry)
 
-		if rect.Overlaps(eRect) {
+		if rect.Overlaps(eRect) && a.CanTarget(enemyEntry) {
 			foundEnemy = enemyEntry
 			// fmt.Println("found enemy")
 		}
@@ -117,7 +185,7 @@ func (a *AttackData) FindEnemyIntersect(entry *donburi.Entry, enemyType ...compo
 	return foundEnemy
 }
 
-func (a *AttackData) AttackEnemyRange(entry *donburi.Entry, afterAttack func(*donburi.Entry), enemyType ...component.IComponentType) {
+func (a *AttackData) AttackEnemyRange(entry *donburi.Entry, afterKill func(*donburi.Entry, *donburi.Entry), afterAttack func(*donburi.Entry) error, enemyType ...component.IComponentType) error {
 	a.cooldown.CheckCooldown()
 	defer a.cooldown.IncrementTicker()
 	if !a.cooldown.InCooldown {
@@ -125,17 +193,34 @@ func (a *AttackData) AttackEnemyRange(entry *donburi.Entry, afterAttack func(*do
 		// look for a enemy in range to shoot at
 		enemy := a.FindEnemyRange(entry, enemyType...)
 		if enemy != nil {
-			a.LaunchBullet(entry, enemy)
+			a.LastEffectiveness = a.Effectiveness(enemy)
+			var err error
+			switch a.AttackType {
+			case MeleeSingle:
+				_, err = a.DamageEnemy(entry, enemy, a.Power, afterKill)
+			case MeleeArea:
+				// pulse outwards from our own edges hitting everything within range
+				_, err = a.AttackArea(entry, GetRect(entry), a.Range, afterKill, enemyType...)
+			default:
+				if a.Projectile == InstantBeam {
+					err = a.FireBeam(entry, enemy, afterKill, enemyType...)
+				} else {
+					err = a.LaunchBullet(entry, enemy)
+				}
+			}
+			if err != nil {
+				return err
+			}
 			a.cooldown.StartCooldown()
 			if afterAttack != nil {
-				afterAttack(entry)
+				return afterAttack(entry)
 			}
 		}
 	}
-
+	return nil
 }
 
-func (a *AttackData) LaunchBullet(entry *donburi.Entry, enemy *donburi.Entry) {
+func (a *AttackData) LaunchBullet(entry *donburi.Entry, enemy *donburi.Entry) error {
 	// create a bullet path from the midpoint of the launcher to the midpoint of the enemy
 	ownRect := GetRect(entry)
 	enemyRect := GetRect(enemy)
@@ -143,7 +228,8 @@ func (a *AttackData) LaunchBullet(entry *donburi.Entry, enemy *donburi.Entry) {
 	start := util.MidpointRect(ownRect)
 	end := util.MidpointRect(enemyRect)
 	const bulletSpeed = 8
-	if !a.noLead && enemy.HasComponent(Velocity) {
+	// homing missiles chase the enemy so don't need to lead it
+	if !a.noLead && a.Projectile != HomingMissile && enemy.HasComponent(Velocity) {
 		v := Velocity.Get(enemy)
 		if !v.blocked {
 			// how far ahead to lead, distance to target divided by speed
@@ -158,12 +244,17 @@ func (a *AttackData) LaunchBullet(entry *donburi.Entry, enemy *donburi.Entry) {
 	}
 
 	creep := entry.HasComponent(Creep)
+	bullet, err := NewBullet(entry.World, start, end, a, bulletSpeed, creep)
+	if err != nil {
+		return err
+	}
+	Bullet.Get(bullet).source = entry.Entity()
+	Bullet.Get(bullet).target = enemy.Entity()
 	if creep {
 		GetGameStats().IncrementStat("CreepBulletsFired")
 	} else {
 		GetGameStats().IncrementStat("TowerBulletsFired")
 	}
-	NewBullet(entry.World, start, end, a.Power, bulletSpeed, creep)
 	if config.GetConfig(entry.World).Sound {
 		var sound string
 		if creep {
@@ -173,9 +264,10 @@ func (a *AttackData) LaunchBullet(entry *donburi.Entry, enemy *donburi.Entry) {
 		}
 		assets.PlaySound(sound)
 	}
+	return nil
 }
 
-func (a *AttackData) AttackEnemyIntersect(entry *donburi.Entry, afterKill func(*donburi.Entry, *donburi.Entry), afterAttack func(*donburi.Entry), enemyType ...component.IComponentType) {
+func (a *AttackData) AttackEnemyIntersect(entry *donburi.Entry, afterKill func(*donburi.Entry, *donburi.Entry), afterAttack func(*donburi.Entry) error, enemyType ...component.IComponentType) error {
 	a.cooldown.CheckCooldown()
 	defer a.cooldown.IncrementTicker()
 	if !a.cooldown.InCooldown {
@@ -183,33 +275,133 @@ func (a *AttackData) AttackEnemyIntersect(entry *donburi.Entry, afterKill func(*
 		// look for a enemy we interect
 		enemy := a.FindEnemyIntersect(entry, enemyType...)
 		if enemy != nil {
-			enemyHealth := Health.Get(enemy)
-			attack := Attack.Get(entry)
-			enemyHealth.Health = enemyHealth.Health - attack.Power
-			if enemyHealth.Health <= 0 {
-				// kill enemy, remove from board, plays sound
-				if config.GetConfig(entry.World).Sound {
-					assets.PlaySound("explosion")
-				}
-
-				// do some other stuff in a callback
-				if afterKill != nil {
-					afterKill(entry, enemy)
-				}
-				// HACK Don't remove player upon kill, TODO find a better way to handle this
-				if !enemy.HasComponent(Player) {
-					enemy.Remove()
-					if enemy.HasComponent(Creep) {
-						GetGameStats().IncrementStat("CreepsKilled")
-					} else {
-						GetGameStats().IncrementStat("TowersKilled")
-					}
-				}
+			var err error
+			if a.AttackType.IsArea() {
+				// blow up where we hit, damaging everything in the blast radius including the enemy we hit
+				_, err = a.AttackArea(entry, GetRect(entry), a.AreaRadius, afterKill, enemyType...)
+			} else {
+				_, err = a.DamageEnemy(entry, enemy, a.Power, afterKill)
+			}
+			if err != nil {
+				return err
 			}
 			a.cooldown.StartCooldown()
 			if afterAttack != nil {
-				afterAttack(entry)
+				return afterAttack(entry)
+			}
+		}
+	}
+	return nil
+}
+
+// AttackArea damages every enemy within radius of the origin rect, with damage falling off towards the edge, returns the number of enemies hit
+func (a *AttackData) AttackArea(entry *donburi.Entry, origin image.Rectangle, radius int, afterKill func(*donburi.Entry, *donburi.Entry), enemyType ...component.IComponentType) (int, error) {
+	hit, err := a.attackAreaExcept(entry, origin, radius, nil, afterKill, enemyType...)
+	return len(hit), err
+}
+
+// attackAreaExcept is AttackArea sparing the enemies in skip, returns the enemies it damaged
+func (a *AttackData) attackAreaExcept(entry *donburi.Entry, origin image.Rectangle, radius int, skip []donburi.Entity, afterKill func(*donburi.Entry, *donburi.Entry), enemyType ...component.IComponentType) ([]donburi.Entity, error) {
+	type target struct {
+		entry *donburi.Entry
+		dist  float64
+	}
+	// collect the targets first since kills remove entries from the world
+	targets := make([]target, 0)
+	query := donburi.NewQuery(util.CreateOrFilter(enemyType...))
+	query.Each(entry.World, func(enemyEntry *donburi.Entry) {
+		dist := util.GapRects(origin, GetRect(enemyEntry))
+		if dist <= float64(radius) && a.CanTarget(enemyEntry) && !slices.Contains(skip, enemyEntry.Entity()) {
+			targets = append(targets, target{enemyEntry, dist})
+		}
+	})
+
+	hit := make([]donburi.Entity, 0, len(targets))
+	for _, t := range targets {
+		if !t.entry.Valid() {
+			continue
+		}
+		hit = append(hit, t.entry.Entity())
+		// a kill removes the entry, so check what it is first
+		isCreep := t.entry.HasComponent(Creep)
+		killed, err := a.DamageEnemy(entry, t.entry, AreaDamage(a.Power, t.dist, radius, a.AreaFalloff), afterKill)
+		if err != nil {
+			return hit, err
+		}
+		if killed && isCreep {
+			GetGameStats().IncrementStat("AreaKills")
+		}
+	}
+	return hit, nil
+}
+
+// AreaDamage scales power down linearly by falloff at the edge of the radius, a hit always does at least 1 damage
+func AreaDamage(power int, dist float64, radius int, falloff float64) int {
+	if power <= 0 {
+		return 0
+	}
+	scale := 1.0
+	if radius > 0 {
+		scale -= falloff * min(dist/float64(radius), 1)
+	}
+	return max(int(math.Round(float64(power)*scale)), 1)
+}
+
+// DamageEnemy applies damage to the enemy and handles its death, returns true if the enemy was killed
+func (a *AttackData) DamageEnemy(entry *donburi.Entry, enemy *donburi.Entry, damage int, afterKill func(*donburi.Entry, *donburi.Entry)) (bool, error) {
+	damage = a.damageAgainst(enemy, damage)
+	enemyHealth := Health.Get(enemy)
+	if tower := creditedTower(entry); tower != nil && enemy.HasComponent(Creep) {
+		// overkill doesn't count towards experience
+		dealt := min(damage, enemyHealth.Health+enemyHealth.Shield)
+		Veterancy.Get(tower).AddXP(tower, dealt*config.GetBalance(entry.World).Tower.Veterancy.DamageXP)
+	}
+	if absorbed := min(enemyHealth.Shield, max(damage, 0)); absorbed > 0 {
+		enemyHealth.Shield -= absorbed
+		damage -= absorbed
+		GetGameStats().UpdateStat("ShieldAbsorbed", absorbed)
+	}
+	enemyHealth.Health = enemyHealth.Health - damage
+	if enemyHealth.Health > 0 {
+		a.ApplyEffect(enemy)
+		return false, nil
+	}
+	// kill enemy, remove from board, plays sound
+	if config.GetConfig(entry.World).Sound {
+		assets.PlaySound("explosion")
+	}
+
+	// do some other stuff in a callback
+	if afterKill != nil {
+		afterKill(entry, enemy)
+	}
+	// HACK Don't remove player upon kill, TODO find a better way to handle this
+	if !enemy.HasComponent(Player) {
+		if enemy.HasComponent(Creep) {
+			GetGameStats().IncrementStat("CreepsKilled")
+			if enemy.HasComponent(Boss) {
+				GetGameStats().IncrementStat("BossKills")
+			}
+			if enemy.HasComponent(Flying) {
+				GetGameStats().IncrementStat("FlyingKills")
+			}
+			creep := Creep.Get(enemy)
+			if creep.Type != "" {
+				GetGameStats().IncrementStat("Killed" + creep.Type)
+			}
+			if creep.IsSplit() {
+				GetGameStats().IncrementStat("SplitKills")
 			}
+			// area attacks collect their targets first so children can't be hit by the blast that killed their parent,
+			// a child killed later in the same tick splits again in turn
+			_, err := creep.Split(enemy)
+			enemy.Remove()
+			return true, err
 		}
+		GetGameStats().IncrementStat("TowersKilled")
+		MarkNavGridDirty(enemy.World)
+		MarkAurasDirty(enemy.World)
+		enemy.Remove()
 	}
+	return true, nil
 }
@@ -0,0 +1,134 @@
+package components
+
+import (
+	"image"
+	"testing"
+	"tower-defense/config"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/yohamta/donburi"
+)
+
+func TestParseAttackType(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    AttackType
+		wantErr bool
+	}{
+		{"", RangedSingle, false},
+		{"MeleeSingle", MeleeSingle, false},
+		{"RangedSingle", RangedSingle, false},
+		{"MeleeArea", MeleeArea, false},
+		{"RangedArea", RangedArea, false},
+		{"Laser", RangedSingle, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseAttackType(tt.name)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseAttackType() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseAttackType() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAreaDamage(t *testing.T) {
+	tests := []struct {
+		name    string
+		power   int
+		dist    float64
+		radius  int
+		falloff float64
+		want    int
+	}{
+		{"center does full damage", 4, 0, 30, 0.5, 4},
+		{"edge loses falloff", 4, 30, 30, 0.5, 2},
+		{"halfway loses half the falloff", 4, 15, 30, 0.5, 3},
+		{"beyond radius is clamped to the edge", 4, 60, 30, 0.5, 2},
+		{"full falloff still does 1 damage", 4, 30, 30, 1, 1},
+		{"no radius does full damage", 4, 10, 0, 0.5, 4},
+		{"no power does no damage", 0, 0, 30, 0.5, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := AreaDamage(tt.power, tt.dist, tt.radius, tt.falloff); got != tt.want {
+				t.Errorf("AreaDamage() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func newAttackTestWorld(t *testing.T) donburi.World {
+	t.Helper()
+
+	world := donburi.NewWorld()
+	config.NewConfig(world, false, false, false)
+	playerEntity := world.Create(Player)
+	Player.Set(world.Entry(playerEntity), &PlayerData{})
+
+	SetGameStats(NewGameStats(nil))
+	t.Cleanup(func() { SetGameStats(nil) })
+	return world
+}
+
+func newAttackTestCreep(world donburi.World, x, y, health int) *donburi.Entry {
+	entry := world.Entry(world.Create(Creep, Position, Health, SpriteRender))
+	Creep.Set(entry, &CreepData{scoreValue: 10})
+	Position.Set(entry, &PositionData{X: x, Y: y})
+	Health.Set(entry, NewHealthData(health))
+	SpriteRender.Set(entry, &SpriteRenderData{image: ebiten.NewImage(10, 10)})
+	return entry
+}
+
+// newTestTower places a tower of the type with a stand-in sprite image since tests can't load the assets
+func newTestTower(t *testing.T, world donburi.World, x, y int, towerType string) *donburi.Entry {
+	t.Helper()
+	tower, err := NewTower(world, x, y, towerType)
+	if err != nil {
+		t.Fatal(err)
+	}
+	SpriteRender.Get(tower).image = ebiten.NewImage(10, 10)
+	return tower
+}
+
+func TestAttackData_AttackAreaDamagesAndCreditsKills(t *testing.T) {
+	world := newAttackTestWorld(t)
+	attacker := world.Entry(world.Create(Attack))
+	attack := &AttackData{Power: 4, AttackType: RangedArea, AreaRadius: 20, AreaFalloff: 0.5}
+	Attack.Set(attacker, attack)
+
+	hit := newAttackTestCreep(world, 0, 0, 3)
+	edge := newAttackTestCreep(world, 15, 0, 5)
+	outside := newAttackTestCreep(world, 60, 0, 5)
+
+	got, err := attack.AttackArea(attacker, image.Rect(5, 5, 5, 5), attack.AreaRadius, OnKillCreep, Creep)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != 2 {
+		t.Fatalf("AttackArea() hit %v enemies, want 2", got)
+	}
+	if hit.Valid() {
+		t.Error("creep at the blast center still valid, want killed")
+	}
+	if health := Health.Get(edge).Health; health != 2 {
+		t.Errorf("edge creep health = %v, want 2 after falloff damage", health)
+	}
+	if health := Health.Get(outside).Health; health != 5 {
+		t.Errorf("outside creep health = %v, want 5", health)
+	}
+
+	player := Player.Get(Player.MustFirst(world))
+	if player.Money != 10 || player.Score != 10 {
+		t.Errorf("player money/score = %v/%v, want 10/10 from OnKillCreep", player.Money, player.Score)
+	}
+	if got := GetGameStats().GetStat("CreepsKilled"); got != 1 {
+		t.Errorf("CreepsKilled = %v, want 1", got)
+	}
+	if got := GetGameStats().GetStat("AreaKills"); got != 1 {
+		t.Errorf("AreaKills = %v, want 1", got)
+	}
+}
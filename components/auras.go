@@ -0,0 +1,176 @@
+package components
+
+import (
+	"cmp"
+	"fmt"
+	"image"
+	"image/color"
+	"slices"
+	"strings"
+
+	"tower-defense/assets"
+	"tower-defense/config"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+	"github.com/yohamta/donburi"
+	"github.com/yohamta/donburi/filter"
+)
+
+// AuraBuffData is the bonus a tower gets from the support towers in range of it, synced so the debug range rendering can show it.
+// The bonus is already added to the tower's attack, it is taken back off whenever the buffs are recomputed.
+type AuraBuffData struct {
+	Power int
+	Range int
+	// CooldownReduction is what was actually taken off the cooldown, which never goes below 1
+	CooldownReduction int
+	// Auras is the number of support towers buffing the tower
+	Auras int
+}
+
+var AuraBuff = donburi.NewComponentType[AuraBuffData]()
+
+// AuraStateData tracks whether the aura buffs need recomputing, there is one per world and it is only used on the server
+type AuraStateData struct {
+	dirty bool
+}
+
+var AuraState = donburi.NewComponentType[AuraStateData]()
+
+var auraColor = color.RGBA{255, 200, 60, 255}
+
+func getAuraState(world donburi.World) *AuraStateData {
+	entry, ok := AuraState.First(world)
+	if !ok {
+		entry = world.Entry(world.Create(AuraState))
+		AuraState.Set(entry, &AuraStateData{dirty: true})
+	}
+	return AuraState.Get(entry)
+}
+
+// MarkAurasDirty forces the aura buffs to be recomputed on the next update, call it whenever a tower is placed, removed or changes its stats
+func MarkAurasDirty(world donburi.World) {
+	getAuraState(world).dirty = true
+}
+
+// UpdateAuras recomputes every tower's aura buff if towers changed since the last time
+func UpdateAuras(world donburi.World) {
+	state := getAuraState(world)
+	if !state.dirty {
+		return
+	}
+	state.dirty = false
+	recomputeAuras(world)
+}
+
+// getTowerAura returns the aura the tower projects, the zero aura when it isn't a support tower
+func getTowerAura(entry *donburi.Entry) config.AuraBalance {
+	return Tower.Get(entry).GetTypeBalance(entry.World).Aura
+}
+
+// inAura reports whether the target tower is inside the support tower's aura, which reaches as far as its attack range
+func inAura(source, target *donburi.Entry) bool {
+	return Attack.Get(source).GetExpandedRect(source).Overlaps(GetRect(target))
+}
+
+func recomputeAuras(world donburi.World) {
+	rules := config.GetBalance(world).Tower.Auras
+	towers := make([]*donburi.Entry, 0)
+	donburi.NewQuery(filter.Contains(Tower, AuraBuff)).Each(world, func(entry *donburi.Entry) {
+		towers = append(towers, entry)
+	})
+	// oldest first so capped stacks always pick the same auras
+	slices.SortFunc(towers, func(a, b *donburi.Entry) int {
+		return cmp.Compare(a.Entity().Id(), b.Entity().Id())
+	})
+
+	for _, entry := range towers {
+		buff := AuraBuff.Get(entry)
+		hadAuras := buff.Auras > 0
+		buff.remove(entry)
+		// blocking and support towers don't fire so they have nothing to buff
+		if Attack.Get(entry).Power <= 0 {
+			continue
+		}
+		var next AuraBuffData
+		for _, source := range towers {
+			aura := getTowerAura(source)
+			if source == entry || !aura.Active() || !inAura(source, entry) {
+				continue
+			}
+			if rules.Stacking == "stack" {
+				if rules.MaxStacks > 0 && next.Auras >= rules.MaxStacks {
+					continue
+				}
+				next.Power += aura.PowerAdd
+				next.Range += aura.RangeAdd
+				next.CooldownReduction += aura.CooldownReduction
+			} else {
+				next.Power = max(next.Power, aura.PowerAdd)
+				next.Range = max(next.Range, aura.RangeAdd)
+				next.CooldownReduction = max(next.CooldownReduction, aura.CooldownReduction)
+			}
+			next.Auras++
+		}
+		buff.apply(entry, next)
+		if !hadAuras && buff.Auras > 0 {
+			GetGameStats().IncrementStat("TowersBuffed")
+		}
+	}
+}
+
+// apply adds the buff to the tower's attack and records what was added so it can be taken off again
+func (b *AuraBuffData) apply(entry *donburi.Entry, next AuraBuffData) {
+	attack := Attack.Get(entry)
+	attack.Power += next.Power
+	attack.Range += next.Range
+	next.CooldownReduction = min(next.CooldownReduction, max(attack.cooldown.Cooldown-1, 0))
+	attack.cooldown.Cooldown -= next.CooldownReduction
+	*b = next
+}
+
+// remove takes the buff back off the tower's attack
+func (b *AuraBuffData) remove(entry *donburi.Entry) {
+	attack := Attack.Get(entry)
+	attack.Power -= b.Power
+	attack.Range -= b.Range
+	attack.cooldown.Cooldown += b.CooldownReduction
+	*b = AuraBuffData{}
+}
+
+// clearAuraBuff takes the tower's aura buff off before its own stats change, so their floors apply to the unbuffed stats.
+// The buff is put back on the next update.
+func clearAuraBuff(entry *donburi.Entry) {
+	if entry.HasComponent(AuraBuff) {
+		AuraBuff.Get(entry).remove(entry)
+		MarkAurasDirty(entry.World)
+	}
+}
+
+// label is the short summary of the buff drawn by the debug range rendering
+func (b *AuraBuffData) label() string {
+	parts := make([]string, 0, 3)
+	if b.Power > 0 {
+		parts = append(parts, fmt.Sprintf("+%dP", b.Power))
+	}
+	if b.Range > 0 {
+		parts = append(parts, fmt.Sprintf("+%dR", b.Range))
+	}
+	if b.CooldownReduction > 0 {
+		parts = append(parts, fmt.Sprintf("-%dCD", b.CooldownReduction))
+	}
+	return strings.Join(parts, " ")
+}
+
+// drawAuraBuff labels a buffed tower's range circle with its bonuses, centered just inside the top of the circle
+func (b *AuraBuffData) drawAuraBuff(screen *ebiten.Image, rangeRect image.Rectangle) {
+	if b.Auras == 0 {
+		return
+	}
+	op := &text.DrawOptions{}
+	op.PrimaryAlign = text.AlignCenter
+	op.SecondaryAlign = text.AlignStart
+	op.GeoM.Translate(float64(rangeRect.Min.X+rangeRect.Dx()/2), float64(rangeRect.Min.Y+2))
+	op.ColorScale.ScaleWithColor(auraColor)
+	text.Draw(screen, b.label(), assets.InfoFace, op)
+}
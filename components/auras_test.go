@@ -0,0 +1,92 @@
+package components
+
+import (
+	"testing"
+
+	"tower-defense/config"
+)
+
+func TestUpdateAuras_HighestBonusOfEachKind(t *testing.T) {
+	world := newAttackTestWorld(t)
+	tower := newTestTower(t, world, 0, 0, "Ranged")
+	wall := newTestTower(t, world, 0, 20, "Wall")
+	// the default Beacon aura of +1 power, +10 range and -4 cooldown reaches 40 pixels
+	beacon1 := newTestTower(t, world, 30, 0, "Beacon")
+	beacon2 := newTestTower(t, world, 0, -30, "Beacon")
+	newTestTower(t, world, 200, 0, "Beacon")
+	UpdateAuras(world)
+
+	attack := Attack.Get(tower)
+	if attack.Power != 2 || attack.Range != 60 || attack.cooldown.Cooldown != 26 {
+		t.Errorf("buffed attack power %v range %v cooldown %v, want 2 60 26", attack.Power, attack.Range, attack.cooldown.Cooldown)
+	}
+	if buff := AuraBuff.Get(tower); buff.Auras != 2 || buff.label() != "+1P +10R -4CD" {
+		t.Errorf("buff = %+v label %q, want 2 auras", *buff, buff.label())
+	}
+	if AuraBuff.Get(wall).Auras != 0 || Attack.Get(wall).Power != 0 {
+		t.Error("blocking only wall was buffed")
+	}
+	if AuraBuff.Get(beacon1).Auras != 0 {
+		t.Error("beacon was buffed by the other beacon")
+	}
+
+	// the buff goes once the beacons are gone
+	player := Player.Get(Player.MustFirst(world))
+	player.SellTower(beacon1, false)
+	UpdateAuras(world)
+	if AuraBuff.Get(tower).Auras != 1 || attack.Power != 2 {
+		t.Errorf("after selling a beacon auras = %v power = %v, want 1 and 2", AuraBuff.Get(tower).Auras, attack.Power)
+	}
+	player.SellTower(beacon2, false)
+	UpdateAuras(world)
+	if attack.Power != 1 || attack.Range != 50 || attack.cooldown.Cooldown != 30 {
+		t.Errorf("unbuffed attack power %v range %v cooldown %v, want 1 50 30", attack.Power, attack.Range, attack.cooldown.Cooldown)
+	}
+	if got := GetGameStats().GetStat("TowersBuffed"); got != 1 {
+		t.Errorf("TowersBuffed = %v, want 1", got)
+	}
+}
+
+func TestUpdateAuras_StackingIsCapped(t *testing.T) {
+	world := newAttackTestWorld(t)
+	balance := *config.DefaultBalance()
+	balance.Tower.Auras = config.AuraRulesBalance{Stacking: "stack", MaxStacks: 2}
+	config.NewBalance(world, &balance)
+
+	tower := newTestTower(t, world, 0, 0, "Ranged")
+	for _, pt := range [][2]int{{-30, 0}, {30, 0}, {0, 30}} {
+		newTestTower(t, world, pt[0], pt[1], "Beacon")
+	}
+	UpdateAuras(world)
+	attack := Attack.Get(tower)
+	if attack.Power != 3 || attack.Range != 70 || attack.cooldown.Cooldown != 22 {
+		t.Errorf("stacked attack power %v range %v cooldown %v, want 3 70 22", attack.Power, attack.Range, attack.cooldown.Cooldown)
+	}
+
+	// the cooldown never drops below 1, and only what was taken off is given back
+	attack.cooldown.Cooldown = 3
+	AuraBuff.Get(tower).CooldownReduction = 0
+	MarkAurasDirty(world)
+	UpdateAuras(world)
+	if attack.cooldown.Cooldown != 1 || AuraBuff.Get(tower).CooldownReduction != 2 {
+		t.Errorf("cooldown %v reduction %v, want 1 and 2", attack.cooldown.Cooldown, AuraBuff.Get(tower).CooldownReduction)
+	}
+}
+
+func TestTowerData_UpgradeUnderAura(t *testing.T) {
+	world := newAttackTestWorld(t)
+	Player.Get(Player.MustFirst(world)).TowerLevels = 20
+	tower := newTestTower(t, world, 0, 0, "Ranged")
+	newTestTower(t, world, 30, 0, "Beacon")
+	UpdateAuras(world)
+
+	// the upgrade applies to the tower's own stats, the aura is added back on top
+	if !Tower.Get(tower).Upgrade(tower, false) {
+		t.Fatal("Upgrade() = false, want true")
+	}
+	UpdateAuras(world)
+	attack := Attack.Get(tower)
+	if attack.Range != 63 || attack.cooldown.Cooldown != 23 {
+		t.Errorf("upgraded attack range %v cooldown %v, want 63 23", attack.Range, attack.cooldown.Cooldown)
+	}
+}
@@ -0,0 +1,197 @@
+package components
+
+import (
+	"fmt"
+	"image/color"
+
+	"tower-defense/config"
+	"tower-defense/util"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"github.com/yohamta/donburi"
+	"github.com/yohamta/donburi/filter"
+)
+
+// CreepBehavior is a support ability a creep uses on other creeps each tick on top of moving and attacking,
+// creep types pick their behaviors by kind in the balance
+type CreepBehavior interface {
+	Update(entry *donburi.Entry) error
+}
+
+var creepBehaviors = map[string]func(config.CreepBehaviorBalance) CreepBehavior{
+	"Heal": func(b config.CreepBehaviorBalance) CreepBehavior {
+		return &healBehavior{b, util.NewCooldownTimer(b.Interval)}
+	},
+	"Shield": func(b config.CreepBehaviorBalance) CreepBehavior {
+		return &shieldBehavior{b, util.NewCooldownTimer(b.Interval)}
+	},
+	"Summon": func(b config.CreepBehaviorBalance) CreepBehavior {
+		// wait a full interval before the first summon so the creep doesn't arrive with an escort
+		cooldown := util.NewCooldownTimer(b.Interval)
+		cooldown.StartCooldown()
+		return &summonBehavior{b, cooldown, nil}
+	},
+}
+
+// debug colors for each behavior's range
+var creepBehaviorColors = map[string]color.RGBA{
+	"Heal":   {60, 255, 60, 255},
+	"Shield": {80, 160, 255, 255},
+	"Summon": {220, 80, 255, 255},
+}
+
+// NewCreepBehaviors creates the behaviors for a creep type
+func NewCreepBehaviors(balance []config.CreepBehaviorBalance) ([]CreepBehavior, error) {
+	behaviors := make([]CreepBehavior, 0, len(balance))
+	for _, b := range balance {
+		newBehavior, ok := creepBehaviors[b.Kind]
+		if !ok {
+			return nil, fmt.Errorf("unknown creep behavior %q", b.Kind)
+		}
+		behaviors = append(behaviors, newBehavior(b))
+	}
+	return behaviors, nil
+}
+
+// alliesInRange returns the other creeps within range of the creep's edges
+func alliesInRange(entry *donburi.Entry, rng int) []*donburi.Entry {
+	allies := make([]*donburi.Entry, 0)
+	for _, ally := range DetectCollisionsAll(entry.World, GetRect(entry).Inset(-rng), filter.Contains(Creep, Health)) {
+		if ally.Entity() != entry.Entity() {
+			allies = append(allies, ally)
+		}
+	}
+	return allies
+}
+
+// useWhenReady runs the ability when off cooldown, the cooldown only starts when the ability did something
+func useWhenReady(cooldown *util.CooldownTimer, ability func() (bool, error)) error {
+	cooldown.CheckCooldown()
+	defer cooldown.IncrementTicker()
+	if cooldown.InCooldown {
+		return nil
+	}
+	used, err := ability()
+	if used {
+		cooldown.StartCooldown()
+	}
+	return err
+}
+
+// healBehavior restores the health of hurt creeps nearby
+type healBehavior struct {
+	config.CreepBehaviorBalance
+	cooldown *util.CooldownTimer
+}
+
+func (h *healBehavior) Update(entry *donburi.Entry) error {
+	return useWhenReady(h.cooldown, func() (bool, error) {
+		healed := 0
+		for _, ally := range alliesInRange(entry, h.Range) {
+			health := Health.Get(ally)
+			amount := min(h.Power, health.MaxHealth-health.Health)
+			if amount > 0 {
+				health.Health += amount
+				healed += amount
+			}
+		}
+		GetGameStats().UpdateStat("CreepHealing", healed)
+		return healed > 0, nil
+	})
+}
+
+// shieldBehavior gives creeps nearby shield points that are lost before health
+type shieldBehavior struct {
+	config.CreepBehaviorBalance
+	cooldown *util.CooldownTimer
+}
+
+func (s *shieldBehavior) Update(entry *donburi.Entry) error {
+	return useWhenReady(s.cooldown, func() (bool, error) {
+		shielded := false
+		for _, ally := range alliesInRange(entry, s.Range) {
+			health := Health.Get(ally)
+			if health.Shield < s.MaxShield {
+				health.Shield = min(health.Shield+s.Power, s.MaxShield)
+				shielded = true
+			}
+		}
+		return shielded, nil
+	})
+}
+
+// summonBehavior spawns minions beside the creep, keeping track of them so there are never too many
+type summonBehavior struct {
+	config.CreepBehaviorBalance
+	cooldown *util.CooldownTimer
+	minions  []donburi.Entity
+}
+
+func (s *summonBehavior) Update(entry *donburi.Entry) error {
+	return useWhenReady(s.cooldown, func() (bool, error) {
+		alive := s.minions[:0]
+		for _, minion := range s.minions {
+			if entry.World.Valid(minion) {
+				alive = append(alive, minion)
+			}
+		}
+		s.minions = alive
+		count := min(s.MinionCount, s.MaxMinions-len(s.minions))
+		if count <= 0 {
+			return false, nil
+		}
+		minions, err := spawnBeside(entry, s.MinionType, count, Creep.Get(entry).level)
+		for _, minion := range minions {
+			s.minions = append(s.minions, minion.Entity())
+		}
+		GetGameStats().UpdateStat("CreepsSummoned", len(minions))
+		return len(minions) > 0, err
+	})
+}
+
+// spawnBeside places creeps in pairs on either side of the entry, an empty type picks random types
+func spawnBeside(entry *donburi.Entry, typeName string, count, creepLevel int) ([]*donburi.Entry, error) {
+	board := Board.Get(Board.MustFirst(entry.World))
+	rect := GetRect(entry)
+	spawned := make([]*donburi.Entry, 0, count)
+	for i := range count {
+		side := 1
+		if i%2 == 1 {
+			side = -1
+		}
+		x := rect.Min.X + side*(i/2+1)*(rect.Dx()+4)
+		x = min(max(x, 0), board.Width-rect.Dx())
+
+		var creep *donburi.Entry
+		var err error
+		if typeName == "" {
+			creep, err = NewCreep(entry.World, x, rect.Min.Y, creepLevel)
+		} else {
+			creep, err = NewCreepOfType(entry.World, x, rect.Min.Y, typeName, creepLevel)
+		}
+		if err != nil {
+			return spawned, err
+		}
+		spawned = append(spawned, creep)
+	}
+	return spawned, nil
+}
+
+// drawCreepBehaviors shows the range and kind of a creep's behaviors in debug mode, looked up by type so it also works in the viewer
+func drawCreepBehaviors(screen *ebiten.Image, entry *donburi.Entry) {
+	creepType := config.GetBalance(entry.World).Creep.GetType(Creep.Get(entry).Type)
+	if creepType == nil {
+		return
+	}
+	rect := GetRect(entry)
+	mid := util.MidpointRect(rect)
+	for i, behavior := range creepType.Behaviors {
+		clr := creepBehaviorColors[behavior.Kind]
+		if behavior.Range > 0 {
+			vector.StrokeCircle(screen, float32(mid.X), float32(mid.Y), float32(rect.Dx()/2+behavior.Range), 1, clr, true)
+		}
+		ebitenutil.DebugPrintAt(screen, behavior.Kind, rect.Max.X+2, rect.Min.Y+i*12)
+	}
+}
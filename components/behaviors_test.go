@@ -0,0 +1,129 @@
+package components
+
+import (
+	"testing"
+
+	"tower-defense/config"
+
+	"github.com/yohamta/donburi"
+	"github.com/yohamta/donburi/filter"
+)
+
+func TestHealBehavior_HealsHurtAlliesInRange(t *testing.T) {
+	world := newAttackTestWorld(t)
+	healer := newAttackTestCreep(world, 100, 100, 5)
+	Health.Get(healer).Health = 1
+	near := newAttackTestCreep(world, 115, 100, 5)
+	Health.Get(near).Health = 2
+	far := newAttackTestCreep(world, 300, 100, 5)
+	Health.Get(far).Health = 2
+
+	behaviors, err := NewCreepBehaviors([]config.CreepBehaviorBalance{{Kind: "Heal", Range: 10, Interval: 3, Power: 2}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for range 4 {
+		if err := behaviors[0].Update(healer); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// healed once right away and again after the interval, capped at max health
+	if got := Health.Get(near).Health; got != 5 {
+		t.Errorf("ally health = %v, want healed to max 5", got)
+	}
+	if got := Health.Get(far).Health; got != 2 {
+		t.Errorf("out of range health = %v, want 2", got)
+	}
+	if got := Health.Get(healer).Health; got != 1 {
+		t.Errorf("healer health = %v, want it not to heal itself", got)
+	}
+	if got := GetGameStats().GetStat("CreepHealing"); got != 3 {
+		t.Errorf("CreepHealing = %v, want 3", got)
+	}
+}
+
+func TestShieldBehavior_ShieldAbsorbsDamage(t *testing.T) {
+	world := newAttackTestWorld(t)
+	warden := newAttackTestCreep(world, 100, 100, 5)
+	ally := newAttackTestCreep(world, 115, 100, 5)
+
+	behaviors, err := NewCreepBehaviors([]config.CreepBehaviorBalance{{Kind: "Shield", Range: 10, Interval: 1, Power: 2, MaxShield: 3}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for range 4 {
+		if err := behaviors[0].Update(warden); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := Health.Get(ally).Shield; got != 3 {
+		t.Fatalf("ally shield = %v, want capped at 3", got)
+	}
+
+	attacker := world.Entry(world.Create(Attack))
+	attack := &AttackData{Power: 4}
+	attack.DamageEnemy(attacker, ally, attack.Power, nil)
+	health := Health.Get(ally)
+	if health.Shield != 0 || health.Health != 4 {
+		t.Errorf("after a 4 damage hit shield = %v health = %v, want 0 and 4", health.Shield, health.Health)
+	}
+	if got := GetGameStats().GetStat("ShieldAbsorbed"); got != 3 {
+		t.Errorf("ShieldAbsorbed = %v, want 3", got)
+	}
+}
+
+func TestSummonBehavior_KeepsMinionsUnderMax(t *testing.T) {
+	world := newWaveTestWorld(t)
+	caller := newAttackTestCreep(world, 200, 100, 5)
+
+	behaviors, err := NewCreepBehaviors([]config.CreepBehaviorBalance{{Kind: "Summon", Interval: 2, MinionType: "Scout", MinionCount: 2, MaxMinions: 3}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	minions := func() []*donburi.Entry {
+		found := make([]*donburi.Entry, 0)
+		donburi.NewQuery(filter.Contains(Creep)).Each(world, func(entry *donburi.Entry) {
+			if entry != caller && Creep.Get(entry).Type == "Scout" {
+				found = append(found, entry)
+			}
+		})
+		return found
+	}
+	update := func(ticks int) {
+		t.Helper()
+		for range ticks {
+			if err := behaviors[0].Update(caller); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	update(2)
+	if got := len(minions()); got != 0 {
+		t.Fatalf("summoned %v minions before the first interval, want 0", got)
+	}
+	update(1)
+	if got := len(minions()); got != 2 {
+		t.Fatalf("summoned %v minions, want 2", got)
+	}
+	update(3)
+	if got := len(minions()); got != 3 {
+		t.Fatalf("summoned %v minions, want capped at 3", got)
+	}
+
+	minions()[0].Remove()
+	update(3)
+	if got := len(minions()); got != 3 {
+		t.Errorf("minions after one died = %v, want replaced up to 3", got)
+	}
+	if got := GetGameStats().GetStat("CreepsSummoned"); got != 4 {
+		t.Errorf("CreepsSummoned = %v, want 4", got)
+	}
+}
+
+func TestNewCreepBehaviors_UnknownKind(t *testing.T) {
+	if _, err := NewCreepBehaviors([]config.CreepBehaviorBalance{{Kind: "Dance", Interval: 1}}); err == nil {
+		t.Error("NewCreepBehaviors() of unknown kind returned no error")
+	}
+}
@@ -0,0 +1,123 @@
+package components
+
+import (
+	"fmt"
+	"image/color"
+
+	"tower-defense/assets"
+	"tower-defense/config"
+	"tower-defense/util"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"github.com/yohamta/donburi"
+	"github.com/yohamta/donburi/filter"
+)
+
+// BossData marks a creep as a boss, Phase is how many of the balance boss phases it has entered
+type BossData struct {
+	Phase       int
+	minionTicks int
+	shotTicks   int
+}
+
+var Boss = donburi.NewComponentType[BossData]()
+
+const spreadShotSpeed = 6
+
+var bossBarColor = color.RGBA{200, 30, 30, 255}
+var bossBarBackground = color.RGBA{60, 60, 60, 200}
+
+// NewBoss spawns a creep of the named type with its health multiplied that goes through the balance boss phases
+func NewBoss(world donburi.World, x, y int, typeName string, creepLevel, healthMultiplier int) (*donburi.Entry, error) {
+	boss, err := newCreepOfType(world, x, y, typeName, creepLevel, Boss)
+	if err != nil {
+		return nil, err
+	}
+	Health.Set(boss, NewHealthData(Health.Get(boss).MaxHealth*healthMultiplier))
+	Boss.Set(boss, &BossData{})
+	GetGameStats().IncrementStat("BossesSpawned")
+	return boss, nil
+}
+
+func (b *BossData) Update(entry *donburi.Entry) error {
+	phases := config.GetBalance(entry.World).Boss.Phases
+	health := Health.Get(entry)
+	for b.Phase < len(phases) && health.Health*100 <= phases[b.Phase].HealthPercent*health.MaxHealth {
+		phase := phases[b.Phase]
+		b.Phase++
+		b.minionTicks, b.shotTicks = 0, 0
+		GetGameStats().IncrementStat("BossPhases")
+		Velocity.Get(entry).Y += phase.SpeedBonus
+		if err := b.spawnMinions(entry, phase); err != nil {
+			return err
+		}
+	}
+	if b.Phase == 0 || StatusEffects.Get(entry).Has(Stun) {
+		return nil
+	}
+
+	phase := phases[b.Phase-1]
+	b.minionTicks++
+	if phase.MinionInterval > 0 && b.minionTicks >= phase.MinionInterval {
+		b.minionTicks = 0
+		if err := b.spawnMinions(entry, phase); err != nil {
+			return err
+		}
+	}
+	b.shotTicks++
+	if phase.SpreadShot.Bullets > 0 && b.shotTicks >= phase.SpreadShot.Interval {
+		b.shotTicks = 0
+		return b.fireSpreadShot(entry, phase.SpreadShot)
+	}
+	return nil
+}
+
+func (b *BossData) spawnMinions(entry *donburi.Entry, phase config.BossPhaseBalance) error {
+	minions, err := spawnBeside(entry, phase.MinionType, phase.MinionCount, Creep.Get(entry).level)
+	GetGameStats().UpdateStat("BossMinions", len(minions))
+	return err
+}
+
+// fireSpreadShot fans bullets at the closest tower or base anywhere on the board
+func (b *BossData) fireSpreadShot(entry *donburi.Entry, shot config.SpreadShotBalance) error {
+	board := Board.Get(Board.MustFirst(entry.World))
+	attack := Attack.Get(entry)
+	finder := &AttackData{Range: board.Height}
+	target := finder.FindEnemyRange(entry, Tower, Player)
+	if target == nil {
+		return nil
+	}
+	GetGameStats().UpdateStat("CreepBulletsFired", shot.Bullets)
+	if config.GetConfig(entry.World).Sound {
+		assets.PlaySound("shoot1")
+	}
+	start := util.MidpointRect(GetRect(entry))
+	end := util.MidpointRect(GetRect(target))
+	return NewSpreadShot(entry.World, start, end, &AttackData{Power: attack.Power}, spreadShotSpeed, true, shot.Bullets, shot.Angle)
+}
+
+// DrawBossBars draws a health bar across the top of the board for every boss
+func DrawBossBars(screen *ebiten.Image, world donburi.World, width float64) {
+	const barHeight = 8
+	const barBorder = 100
+	y := 40.0
+	donburi.NewQuery(filter.Contains(Boss, Health)).Each(world, func(entry *donburi.Entry) {
+		health := Health.Get(entry)
+		name := "BOSS"
+		if entry.HasComponent(Creep) {
+			name = fmt.Sprintf("%s BOSS", Creep.Get(entry).Type)
+		}
+		if phase := Boss.Get(entry).Phase; phase > 0 {
+			name = fmt.Sprintf("%s phase %d", name, phase)
+		}
+		barY := DrawTextLines(screen, assets.InfoFace, name, width, y, text.AlignCenter, text.AlignStart)
+
+		barWidth := float32(width - 2*barBorder)
+		fill := barWidth * float32(max(health.Health, 0)) / float32(max(health.MaxHealth, 1))
+		vector.DrawFilledRect(screen, barBorder, float32(barY), barWidth, barHeight, bossBarBackground, false)
+		vector.DrawFilledRect(screen, barBorder, float32(barY), fill, barHeight, bossBarColor, false)
+		y = barY + barHeight + 4
+	})
+}
@@ -0,0 +1,143 @@
+package components
+
+import (
+	"image"
+	"testing"
+
+	"tower-defense/config"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/yohamta/donburi"
+	"github.com/yohamta/donburi/filter"
+)
+
+func newBossTestWorld(t *testing.T, phases []config.BossPhaseBalance) donburi.World {
+	t.Helper()
+
+	world := newWaveTestWorld(t)
+	balance := *config.DefaultBalance()
+	balance.Boss.Phases = phases
+	config.NewBalance(world, &balance)
+
+	pe := Player.MustFirst(world)
+	pe.AddComponent(Position)
+	pe.AddComponent(SpriteRender)
+	Position.Set(pe, &PositionData{X: 0, Y: 730})
+	SpriteRender.Set(pe, &SpriteRenderData{image: ebiten.NewImage(600, 48)})
+	return world
+}
+
+func TestNewBoss(t *testing.T) {
+	world := newWaveTestWorld(t)
+
+	boss, err := NewBoss(world, 100, 60, "Brute", 1, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := Health.Get(boss).MaxHealth; got != 50 {
+		t.Errorf("boss health = %v, want 10 times the brute's 5", got)
+	}
+	if !boss.HasComponent(Boss) {
+		t.Error("boss has no Boss component")
+	}
+	if got := GetGameStats().GetStat("BossesSpawned"); got != 1 {
+		t.Errorf("BossesSpawned = %v, want 1", got)
+	}
+
+	attacker := world.Entry(world.Create(Attack))
+	attack := &AttackData{Power: 100}
+	attack.DamageEnemy(attacker, boss, attack.Power, nil)
+	if got := GetGameStats().GetStat("BossKills"); got != 1 {
+		t.Errorf("BossKills = %v, want 1", got)
+	}
+}
+
+func TestBossData_UpdateEntersPhasesByHealth(t *testing.T) {
+	world := newBossTestWorld(t, []config.BossPhaseBalance{
+		{HealthPercent: 75, SpeedBonus: 2},
+		{HealthPercent: 50, MinionType: "Scout", MinionCount: 2},
+		{HealthPercent: 25, SpeedBonus: 5},
+	})
+	boss, err := NewBoss(world, 200, 60, "Brute", 1, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	SpriteRender.Get(boss).image = ebiten.NewImage(48, 48)
+	speed := Velocity.Get(boss).Y
+
+	if err := Boss.Get(boss).Update(boss); err != nil {
+		t.Fatal(err)
+	}
+	if got := Boss.Get(boss).Phase; got != 0 {
+		t.Fatalf("phase = %v at full health, want 0", got)
+	}
+
+	// dropping past two thresholds at once enters both phases
+	Health.Get(boss).Health = 20
+	if err := Boss.Get(boss).Update(boss); err != nil {
+		t.Fatal(err)
+	}
+	if got := Boss.Get(boss).Phase; got != 2 {
+		t.Errorf("phase = %v at 40%% health, want 2", got)
+	}
+	if got := Velocity.Get(boss).Y; got != speed+2 {
+		t.Errorf("speed = %v, want %v after the first phase", got, speed+2)
+	}
+	minions := 0
+	donburi.NewQuery(filter.Contains(Creep)).Each(world, func(entry *donburi.Entry) {
+		if !entry.HasComponent(Boss) {
+			minions++
+			if got := Creep.Get(entry).Type; got != "Scout" {
+				t.Errorf("minion type = %v, want Scout", got)
+			}
+		}
+	})
+	if minions != 2 {
+		t.Errorf("spawned %v minions, want 2", minions)
+	}
+	if got := GetGameStats().GetStat("BossPhases"); got != 2 {
+		t.Errorf("BossPhases = %v, want 2", got)
+	}
+}
+
+func TestBossData_UpdateFiresSpreadShot(t *testing.T) {
+	world := newBossTestWorld(t, []config.BossPhaseBalance{
+		{HealthPercent: 90, SpreadShot: config.SpreadShotBalance{Bullets: 3, Angle: 30, Interval: 2}},
+	})
+	tower := world.Entry(world.Create(Tower, Position, SpriteRender))
+	Position.Set(tower, &PositionData{X: 200, Y: 500})
+	SpriteRender.Set(tower, &SpriteRenderData{image: ebiten.NewImage(40, 20)})
+
+	boss, err := NewBoss(world, 200, 60, "Brute", 1, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	SpriteRender.Get(boss).image = ebiten.NewImage(48, 48)
+	Health.Get(boss).Health = 10
+	for range 2 {
+		if err := Boss.Get(boss).Update(boss); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := donburi.NewQuery(filter.Contains(Bullet)).Count(world); got != 3 {
+		t.Errorf("fired %v bullets, want a spread of 3", got)
+	}
+}
+
+func TestNewSpreadShot(t *testing.T) {
+	world := newWaveTestWorld(t)
+	start := image.Pt(100, 100)
+	if err := NewSpreadShot(world, start, image.Pt(100, 200), &AttackData{Power: 1}, 6, true, 3, 90); err != nil {
+		t.Fatal(err)
+	}
+	ends := make(map[image.Point]bool)
+	donburi.NewQuery(filter.Contains(Bullet)).Each(world, func(entry *donburi.Entry) {
+		ends[Bullet.Get(entry).end] = true
+	})
+	// straight down plus 45 degrees either side at the same distance
+	for _, want := range []image.Point{{100, 200}, {29, 171}, {171, 171}} {
+		if !ends[want] {
+			t.Errorf("no bullet aimed at %v, got %v", want, ends)
+		}
+	}
+}
@@ -0,0 +1,163 @@
+package components
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"strings"
+
+	"tower-defense/assets"
+	"tower-defense/config"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"github.com/yohamta/donburi"
+)
+
+const (
+	branchOptionWidth  = 80
+	branchOptionHeight = 16
+)
+
+var branchPopupColor = color.RGBA{20, 20, 60, 230}
+var branchHoverColor = color.RGBA{60, 60, 140, 230}
+var branchPendingColor = color.RGBA{255, 220, 60, 255}
+
+// branchAt returns the upgrade branch offered at the level, nil when there isn't one
+func (t *TowerData) branchAt(world donburi.World, level int) *config.UpgradeBranchBalance {
+	branches := config.GetBalance(world).Tower.GetBranches(t.Type)
+	for i := range branches {
+		if branches[i].Level == level {
+			return &branches[i]
+		}
+	}
+	return nil
+}
+
+// PendingBranch returns the upgrade choice the tower is waiting on, nil when it isn't choosing
+func (t *TowerData) PendingBranch(entry *donburi.Entry) *config.UpgradeBranchBalance {
+	if !t.ChoosingBranch {
+		return nil
+	}
+	return t.branchAt(entry.World, Level.Get(entry).Level)
+}
+
+// ChooseBranch takes the option at index of the branch the tower is waiting on and adds its bonuses, choosing is free
+func (t *TowerData) ChooseBranch(entry *donburi.Entry, index int, debug bool) bool {
+	branch := t.PendingBranch(entry)
+	if branch == nil || index < 0 || index >= len(branch.Options) {
+		return false
+	}
+	option := branch.Options[index]
+	clearAuraBuff(entry)
+	attack := Attack.Get(entry)
+	attack.Power += option.PowerAdd
+	attack.Range += option.RangeAdd
+	attack.cooldown.Cooldown = max(1, attack.cooldown.Cooldown-option.CooldownReduction)
+	health := Health.Get(entry)
+	health.MaxHealth += option.MaxHealthAdd
+	health.Health += option.MaxHealthAdd
+	if option.AreaRadius > 0 {
+		switch attack.AttackType {
+		case RangedSingle:
+			attack.AttackType = RangedArea
+		case MeleeSingle:
+			attack.AttackType = MeleeArea
+		}
+		attack.AreaRadius += option.AreaRadius
+		if attack.AreaFalloff == 0 {
+			attack.AreaFalloff = option.AreaFalloff
+		}
+	}
+	if debug {
+		fmt.Printf("%v tower took the %v upgrade at level %v\n", t.Type, option.Name, branch.Level)
+	}
+	t.Branches = append(t.Branches, option.Name)
+	t.ChoosingBranch = false
+	GetGameStats().IncrementStat("BranchesChosen")
+	return true
+}
+
+// branchLabels are the short labels of the upgrade paths the tower took, in the order it took them
+func (t *TowerData) branchLabels(world donburi.World) string {
+	branches := config.GetBalance(world).Tower.GetBranches(t.Type)
+	labels := make([]string, 0, len(t.Branches))
+	for i, name := range t.Branches {
+		label := name
+		if i < len(branches) {
+			for _, option := range branches[i].Options {
+				if option.Name == name && option.Label != "" {
+					label = option.Label
+				}
+			}
+		}
+		labels = append(labels, label)
+	}
+	return strings.Join(labels, " ")
+}
+
+// GetChoosingTower returns the tower whose upgrade choice popup is open, or nil if there isn't one or it already chose
+func (p *PlayerData) GetChoosingTower(world donburi.World) *donburi.Entry {
+	if p.choosingTower == donburi.Null || !world.Valid(p.choosingTower) {
+		return nil
+	}
+	entry := world.Entry(p.choosingTower)
+	if !Tower.Get(entry).ChoosingBranch {
+		return nil
+	}
+	return entry
+}
+
+// branchOptionRects lays the popup options out in a column above the tower, or below it when there is no room above
+func branchOptionRects(board *BoardData, towerRect image.Rectangle, count int) []image.Rectangle {
+	x := towerRect.Min.X + towerRect.Dx()/2 - branchOptionWidth/2
+	x = max(0, min(x, board.Width-branchOptionWidth))
+	y := towerRect.Min.Y - count*branchOptionHeight - 2
+	if y < 0 {
+		y = towerRect.Max.Y + 2
+	}
+	rects := make([]image.Rectangle, count)
+	for i := range rects {
+		rects[i] = image.Rect(x, y+i*branchOptionHeight, x+branchOptionWidth, y+(i+1)*branchOptionHeight)
+	}
+	return rects
+}
+
+// branchOptionAt returns the index of the popup option under x, y, -1 when there isn't one
+func branchOptionAt(towerEntry *donburi.Entry, x, y int) int {
+	branch := Tower.Get(towerEntry).PendingBranch(towerEntry)
+	if branch == nil {
+		return -1
+	}
+	board := Board.Get(Board.MustFirst(towerEntry.World))
+	for i, rect := range branchOptionRects(board, GetRect(towerEntry), len(branch.Options)) {
+		if image.Pt(x, y).In(rect) {
+			return i
+		}
+	}
+	return -1
+}
+
+// drawBranchPopup draws the upgrade options of the tower's pending branch, highlighting the one under the cursor
+func drawBranchPopup(screen *ebiten.Image, towerEntry *donburi.Entry) {
+	branch := Tower.Get(towerEntry).PendingBranch(towerEntry)
+	if branch == nil {
+		return
+	}
+	board := Board.Get(Board.MustFirst(towerEntry.World))
+	x, y := ebiten.CursorPosition()
+	for i, rect := range branchOptionRects(board, GetRect(towerEntry), len(branch.Options)) {
+		fill := branchPopupColor
+		if image.Pt(x, y).In(rect) {
+			fill = branchHoverColor
+		}
+		vector.DrawFilledRect(screen, float32(rect.Min.X), float32(rect.Min.Y), float32(rect.Dx()), float32(rect.Dy()), fill, false)
+		vector.StrokeRect(screen, float32(rect.Min.X), float32(rect.Min.Y), float32(rect.Dx()), float32(rect.Dy()), 1, branchPendingColor, false)
+		op := &text.DrawOptions{}
+		op.PrimaryAlign = text.AlignCenter
+		op.SecondaryAlign = text.AlignCenter
+		op.GeoM.Translate(float64(rect.Min.X+rect.Dx()/2), float64(rect.Min.Y+rect.Dy()/2))
+		text.Draw(screen, branch.Options[i].Name, assets.InfoFace, op)
+	}
+}
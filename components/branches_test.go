@@ -0,0 +1,105 @@
+package components
+
+import (
+	"image"
+	"testing"
+)
+
+func TestTowerData_ChooseBranchAtBranchLevels(t *testing.T) {
+	world := newAttackTestWorld(t)
+	tower := newTestTower(t, world, 0, 0, "Ranged")
+	towerData := Tower.Get(tower)
+	player := Player.Get(Player.MustFirst(world))
+	player.Money = 1000
+
+	// the default branches are offered at levels 3 and 5
+	if !player.TryUpgradeTower(tower, false, false) || towerData.ChoosingBranch {
+		t.Fatalf("upgrade to level 2 choosing = %v, want an upgrade with no choice", towerData.ChoosingBranch)
+	}
+	if !player.TryUpgradeTower(tower, false, false) || !towerData.ChoosingBranch {
+		t.Fatalf("upgrade to level 3 choosing = %v, want a choice", towerData.ChoosingBranch)
+	}
+	if player.GetChoosingTower(world) != tower {
+		t.Error("GetChoosingTower() did not return the tower waiting on a choice")
+	}
+	if player.TryUpgradeTower(tower, false, false) || Level.Get(tower).Level != 3 {
+		t.Fatalf("upgrade while choosing reached level %v, want blocked at 3", Level.Get(tower).Level)
+	}
+	if player.Money != 900 {
+		t.Errorf("money = %v, want 900 with the blocked upgrade free", player.Money)
+	}
+
+	if towerData.ChooseBranch(tower, 3, false) {
+		t.Fatal("ChooseBranch() with an unknown option = true, want false")
+	}
+	if !towerData.ChooseBranch(tower, 2, false) {
+		t.Fatal("ChooseBranch(Splash) = false, want true")
+	}
+	attack := Attack.Get(tower)
+	if attack.AttackType != RangedArea || attack.AreaRadius != 20 || attack.AreaFalloff != 0.5 {
+		t.Errorf("after Splash attack = %v radius %v falloff %v, want RangedArea 20 0.5", attack.AttackType, attack.AreaRadius, attack.AreaFalloff)
+	}
+	if towerData.ChoosingBranch || player.GetChoosingTower(world) != nil {
+		t.Error("tower still choosing after ChooseBranch()")
+	}
+
+	player.TryUpgradeTower(tower, false, false)
+	player.TryUpgradeTower(tower, false, false)
+	if !towerData.ChoosingBranch || !towerData.ChooseBranch(tower, 0, false) {
+		t.Fatal("level 5 Power choice was not offered")
+	}
+	// 1 to start, +1 at levels 3, 4 and 5 from the upgrade curve and +2 from Power
+	if attack.Power != 6 {
+		t.Errorf("power = %v, want 6", attack.Power)
+	}
+	if got := towerData.branchLabels(world); got != "SPL PWR" {
+		t.Errorf("branchLabels() = %q, want %q", got, "SPL PWR")
+	}
+	if got := GetGameStats().GetStat("BranchesChosen"); got != 2 {
+		t.Errorf("BranchesChosen = %v, want 2", got)
+	}
+}
+
+func TestTowerData_TypeBranchesReplaceTowerWide(t *testing.T) {
+	world := newAttackTestWorld(t)
+	player := Player.Get(Player.MustFirst(world))
+	player.Money = 1000
+
+	for _, tt := range []struct {
+		towerType string
+		choosing  bool
+	}{
+		{"Pulse", true},
+		{"Wall", false},
+	} {
+		tower := newTestTower(t, world, 0, 0, tt.towerType)
+		player.TryUpgradeTower(tower, false, false)
+		player.TryUpgradeTower(tower, false, false)
+		towerData := Tower.Get(tower)
+		if towerData.ChoosingBranch != tt.choosing {
+			t.Errorf("%v at level 3 choosing = %v, want %v", tt.towerType, towerData.ChoosingBranch, tt.choosing)
+		}
+		if branch := towerData.PendingBranch(tower); tt.choosing && branch.Options[0].Name != "Reach" {
+			t.Errorf("%v first option = %v, want Reach", tt.towerType, branch.Options[0].Name)
+		}
+	}
+}
+
+func Test_branchOptionRects(t *testing.T) {
+	board := &BoardData{Width: 600, Height: 800}
+
+	// stacked above the tower when there is room
+	rects := branchOptionRects(board, image.Rect(200, 300, 248, 348), 2)
+	if want := image.Rect(184, 266, 264, 282); rects[0] != want {
+		t.Errorf("first option = %v, want %v", rects[0], want)
+	}
+
+	// kept on the board, below a tower at the top left corner
+	rects = branchOptionRects(board, image.Rect(0, 10, 48, 58), 3)
+	if want := image.Rect(0, 60, 80, 76); rects[0] != want {
+		t.Errorf("first option = %v, want %v", rects[0], want)
+	}
+	if want := image.Rect(0, 92, 80, 108); rects[2] != want {
+		t.Errorf("last option = %v, want %v", rects[2], want)
+	}
+}
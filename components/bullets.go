@@ -3,6 +3,7 @@ package components
 import (
 	"image"
 	"image/color"
+	"math"
 	"tower-defense/config"
 	"tower-defense/util"
 
@@ -16,6 +17,16 @@ type BulletData struct {
 	start, end image.Point
 	speed      int
 	creep      bool // TODO switch to using a component tag EnemyTag https://pkg.go.dev/github.com/yohamta/donburi@v1.4.4#readme-tags
+	// source is the tower that fired the bullet, credited with its damage and kills
+	source donburi.Entity
+	kind   ProjectileKind
+	// target is the enemy a homing missile turns towards, heading its last move and flown how far it has gone
+	target  donburi.Entity
+	heading image.Point
+	flown   float64
+	// pierce is how many enemies a piercing round can hit, hit the ones it already has
+	pierce int
+	hit    []donburi.Entity
 }
 
 type BulletRenderData struct {
@@ -28,8 +39,10 @@ var BulletRender = donburi.NewComponentType[BulletRenderData]()
 
 var creepBulletColor = color.RGBA{255, 0, 0, 255}
 var towerBulletColor = color.RGBA{40, 255, 40, 255}
+var areaBulletColor = color.RGBA{255, 160, 0, 255}
 
-func NewBullet(world donburi.World, start, end image.Point, power, speed int, creep bool) (*donburi.Entry, error) {
+// NewBullet creates a bullet carrying the power, area and status effect of the attack that launched it
+func NewBullet(world donburi.World, start, end image.Point, attack *AttackData, speed int, creep bool) (*donburi.Entry, error) {
 	bulletEntity := world.Create(Bullet, Position, Velocity, Attack, BulletRender)
 	err := srvsync.NetworkSync(world, &bulletEntity, Bullet, Position, Attack, BulletRender)
 	if err != nil {
@@ -40,9 +53,20 @@ func NewBullet(world donburi.World, start, end image.Point, power, speed int, cr
 	Position.Set(bullet, &PositionData{start.X, start.Y})
 	Velocity.Set(bullet, &VelocityData{X: 6, Y: 6})
 
+	attackType := RangedSingle
 	var color color.RGBA
 	var size int
-	if creep {
+	if attack.AttackType == RangedArea {
+		attackType = RangedArea
+		color = areaBulletColor
+		size = 5
+	} else if attack.Projectile == HomingMissile {
+		color = homingBulletColor
+		size = 4
+	} else if attack.Projectile == PiercingRound {
+		color = piercingBulletColor
+		size = 3
+	} else if creep {
 		color = creepBulletColor
 		size = 3
 	} else {
@@ -50,11 +74,34 @@ func NewBullet(world donburi.World, start, end image.Point, power, speed int, cr
 		size = 4
 	}
 	BulletRender.Set(bullet, NewBulletRender(size, color))
-	Attack.Set(bullet, &AttackData{Power: power, AttackType: RangedSingle, Range: 1, cooldown: util.NewCooldownTimer(30)})
-	Bullet.Set(bullet, &BulletData{start: start, end: end, speed: speed, creep: creep})
+	Attack.Set(bullet, &AttackData{Power: attack.Power, AttackType: attackType, Range: 1, AreaRadius: attack.AreaRadius, AreaFalloff: attack.AreaFalloff, Effect: attack.Effect, Hazard: attack.Hazard, DamageType: attack.DamageType, AntiAir: attack.AntiAir, cooldown: util.NewCooldownTimer(30)})
+	bulletData := &BulletData{start: start, end: end, speed: speed, creep: creep, kind: attack.Projectile, pierce: attack.Pierce}
+	if dist := util.DistancePoints(start, end); dist > 0 {
+		ratio := dist / float64(speed)
+		bulletData.heading = image.Pt(int(float64(end.X-start.X)/ratio), int(float64(end.Y-start.Y)/ratio))
+	}
+	Bullet.Set(bullet, bulletData)
 	return bullet, nil
 }
 
+// NewSpreadShot fires a fan of count bullets spread evenly across spread degrees, centered on the line from start to end
+func NewSpreadShot(world donburi.World, start, end image.Point, attack *AttackData, speed int, creep bool, count, spread int) error {
+	dist := util.DistancePoints(start, end)
+	angle := math.Atan2(float64(end.Y-start.Y), float64(end.X-start.X))
+	for i := range count {
+		offset := 0.0
+		if count > 1 {
+			offset = float64(spread)*float64(i)/float64(count-1) - float64(spread)/2
+		}
+		a := angle + offset*math.Pi/180
+		target := start.Add(image.Pt(int(math.Round(math.Cos(a)*dist)), int(math.Round(math.Sin(a)*dist))))
+		if _, err := NewBullet(world, start, target, attack, speed, creep); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func NewBulletRender(size int, clr color.Color) *BulletRenderData {
 	brd := BulletRenderData{Size: size}
 	r, g, b, a := clr.RGBA()
@@ -70,31 +117,60 @@ func (bd *BulletData) Update(entry *donburi.Entry) error {
 	pos := Position.Get(entry)
 	dist := util.DistancePoints(bd.start, bd.end)
 	// if the bullet has traveled past its range (plus a little buffer), remove it
-	// TODO add special bullets that do something when they expire (like a slow-down effect)
-	if util.DistancePoints(bd.start, image.Pt(pos.X, pos.Y)) > dist*3/2 {
-		entry.Remove()
-		GetGameStats().IncrementStat("BulletsExpired")
+	expired := util.DistancePoints(bd.start, image.Pt(pos.X, pos.Y)) > dist*3/2
+	if bd.kind == HomingMissile {
+		// missiles turn so go by how far they flew, with room to chase their target
+		expired = bd.flown > dist*2
+	}
+	if expired {
+		// special bullets leave a hazard on the ground where they miss
+		if hazard := Attack.Get(entry).Hazard; hazard.OnExpire && len(bd.hit) == 0 {
+			if _, err := NewHazard(entry.World, image.Pt(pos.X, pos.Y), hazard); err != nil {
+				return err
+			}
+		}
+		bd.expire(entry)
 		return nil
 	}
-	ratio := dist / float64(bd.speed)
-	// fmt.Printf("dist: %v, ratio: %v, start: %v, end: %v\n", dist, ratio, bd.start, bd.end)
 
-	newX := pos.X + int(float64(bd.end.X-bd.start.X)/ratio)
-	newY := pos.Y + int(float64(bd.end.Y-bd.start.Y)/ratio)
+	var newX, newY int
+	if bd.kind == HomingMissile {
+		step := bd.homingStep(entry, pos)
+		newX, newY = pos.X+step.X, pos.Y+step.Y
+	} else {
+		ratio := dist / float64(bd.speed)
+		// fmt.Printf("dist: %v, ratio: %v, start: %v, end: %v\n", dist, ratio, bd.start, bd.end)
+
+		newX = pos.X + int(float64(bd.end.X-bd.start.X)/ratio)
+		newY = pos.Y + int(float64(bd.end.Y-bd.start.Y)/ratio)
+	}
 	// fmt.Printf("newX, newY: %v, %v\n", newX, newY)
 	be := Board.MustFirst(entry.World)
 	board := Board.Get(be)
 
 	if newX < 0 || newX > board.Width || newY < 0 || newY > board.Height {
-		entry.Remove()
-		GetGameStats().IncrementStat("BulletsExpired")
+		bd.expire(entry)
 	} else {
 		// if enemy in range, attack it
 		a := Attack.Get(entry)
-		if bd.IsCreep() {
-			a.AttackEnemyIntersect(entry, nil, AfterBulletAttack, Tower, Player)
+		if bd.kind == PiercingRound {
+			var err error
+			if bd.IsCreep() {
+				err = bd.pierceEnemies(entry, nil, Tower, Player)
+			} else {
+				err = bd.pierceEnemies(entry, OnKillCreep, Creep)
+			}
+			if err != nil {
+				return err
+			}
+		} else if bd.IsCreep() {
+			if err := a.AttackEnemyIntersect(entry, nil, AfterBulletAttack, Tower, Player); err != nil {
+				return err
+			}
 		} else {
-			a.AttackEnemyIntersect(entry, OnKillCreep, AfterBulletAttack, Creep)
+			if err := a.AttackEnemyIntersect(entry, OnKillCreep, AfterBulletAttack, Creep); err != nil {
+				return err
+			}
 		}
 
 		pos.X = newX
@@ -102,8 +178,25 @@ func (bd *BulletData) Update(entry *donburi.Entry) error {
 	}
 	return nil
 }
-func AfterBulletAttack(bulletEntry *donburi.Entry) {
+
+// expire removes a bullet that ran out of range or off the board, counted as a miss unless it was a piercing round that already hit
+func (bd *BulletData) expire(entry *donburi.Entry) {
+	entry.Remove()
+	if len(bd.hit) == 0 {
+		GetGameStats().IncrementStat("BulletsExpired")
+	}
+}
+
+func AfterBulletAttack(bulletEntry *donburi.Entry) error {
+	GetGameStats().IncrementStat("BulletHits")
+	if hazard := Attack.Get(bulletEntry).Hazard; hazard.OnHit {
+		pos := Position.Get(bulletEntry)
+		if _, err := NewHazard(bulletEntry.World, image.Pt(pos.X, pos.Y), hazard); err != nil {
+			return err
+		}
+	}
 	bulletEntry.Remove()
+	return nil
 }
 
 func (brd *BulletRenderData) Draw(screen *ebiten.Image, entry *donburi.Entry) {
@@ -115,6 +208,11 @@ func (brd *BulletRenderData) Draw(screen *ebiten.Image, entry *donburi.Entry) {
 	config := config.GetConfig(entry.World)
 	if config.Debug {
 		vector.StrokeLine(screen, float32(bullet.start.X), float32(bullet.start.Y), float32(bullet.end.X), float32(bullet.end.Y), 1, color, true)
+		if entry.HasComponent(Attack) {
+			if a := Attack.Get(entry); a.AttackType.IsArea() {
+				vector.StrokeCircle(screen, float32(pos.X), float32(pos.Y), float32(a.AreaRadius), 1, color, true)
+			}
+		}
 	}
 }
 
@@ -13,78 +13,151 @@ import (
 )
 
 type CreepData struct {
+	// Type is the name of the creep's archetype in the balance
+	Type       string
 	scoreValue int
+	// pathAround creeps follow the nav grid around towers, otherwise they walk straight down and attack through them
+	pathAround bool
+	super      bool
+	// level is the creep level it spawned at
+	level     int
+	behaviors []CreepBehavior
+	// generation counts how many splits the creep came from, zero for creeps that weren't split from another
+	generation int
+}
+
+// FlyingData marks a creep that flies over towers and other creeps, only anti-air attacks can hit it
+type FlyingData struct {
 }
 
 var Creep = donburi.NewComponentType[CreepData]()
+var Flying = donburi.NewComponentType[FlyingData]()
 
+// NewCreep spawns a creep of a random type chosen by the spawn weights for the creep level
 func NewCreep(world donburi.World, x, y, creepLevel int) (*donburi.Entry, error) {
-	entity := world.Create(Creep, Position, Velocity, Health, Attack, SpriteRender, RangeRender, InfoRender)
-	err := srvsync.NetworkSync(world, &entity, Creep, Position, Health, Attack, SpriteRender, RangeRender, InfoRender)
+	creepType, err := chooseCreepType(GetRandom(world), config.GetBalance(world).Creep.Types, creepLevel)
 	if err != nil {
 		return nil, err
 	}
-	creep := world.Entry(entity)
-	Position.Set(creep, &PositionData{X: x, Y: y})
+	return NewCreepOfType(world, x, y, creepType, creepLevel)
+}
 
-	balance := config.GetBalance(world).Creep
-	choose := balance.SmallCreepFirstChoice
-	augment := 1
-	if rand.Float32() < balance.BigCreepChance {
-		choose = balance.BigCreepChoice
-		augment = balance.BigCreepAugment
-	} else {
-		choose += rand.IntN(balance.SmallCreepVariants)
+// NewSuperCreep spawns the creep type that other players send in multiplayer
+func NewSuperCreep(world donburi.World, x, y, creepLevel int) (*donburi.Entry, error) {
+	creep, err := NewCreepOfType(world, x, y, config.GetBalance(world).Multiplayer.SuperCreepType, creepLevel)
+	if err != nil {
+		return nil, err
 	}
-	Velocity.Set(creep, &VelocityData{X: 0, Y: balance.BaseVelocityY - augment + creepLevel/2})
-	name := fmt.Sprintf("creep%v", choose)
-	Creep.Set(creep, &CreepData{scoreValue: balance.ScoreValueBase * augment})
-	Health.Set(creep, NewHealthData(balance.HealthBase+balance.HealthAugmentMultiplier*augment+creepLevel/balance.HealthLevelDivisor))
-	Attack.Set(creep, &AttackData{
-		Power:      balance.AttackPowerBase + (creepLevel-balance.AttackPowerLevelOffset)*augment/balance.AttackPowerLevelDivisor,
-		AttackType: RangedSingle,
-		Range:      balance.AttackRangeBase + balance.AttackRangeAugmentMultiplier*augment,
-		cooldown:   util.NewCooldownTimer(balance.AttackCooldownBase + balance.AttackCooldownAugmentMultiplier*augment),
-	})
-	SpriteRender.Set(creep, &SpriteRenderData{Name: name})
-	RangeRender.Set(creep, &RangeRenderData{})
-	InfoRender.Set(creep, &InfoRenderData{})
+	Creep.Get(creep).super = true
 	return creep, nil
 }
-func NewSuperCreep(world donburi.World, x, y int) (*donburi.Entry, error) {
-	entity := world.Create(Creep, Position, Velocity, Health, Attack, SpriteRender, RangeRender, InfoRender)
-	err := srvsync.NetworkSync(world, &entity, Creep, Position, Health, Attack, SpriteRender, RangeRender, InfoRender)
+
+// NewCreepOfType spawns a creep of the named balance type scaled to the creep level
+func NewCreepOfType(world donburi.World, x, y int, typeName string, creepLevel int) (*donburi.Entry, error) {
+	return newCreepOfType(world, x, y, typeName, creepLevel)
+}
+
+// newCreepOfType creates the creep with extra components that are also synced, the caller sets their values
+func newCreepOfType(world donburi.World, x, y int, typeName string, creepLevel int, extra ...donburi.IComponentType) (*donburi.Entry, error) {
+	creepType := config.GetBalance(world).Creep.GetType(typeName)
+	if creepType == nil {
+		return nil, fmt.Errorf("unknown creep type %q", typeName)
+	}
+	attackType, err := ParseAttackType(creepType.AttackType)
+	if err != nil {
+		return nil, err
+	}
+	behaviors, err := NewCreepBehaviors(creepType.Behaviors)
+	if err != nil {
+		return nil, err
+	}
+	defense, err := NewDefenseData(creepType)
+	if err != nil {
+		return nil, err
+	}
+	if creepType.Flying {
+		extra = append(extra, Flying)
+	}
+
+	entity := world.Create(append([]donburi.IComponentType{Creep, Position, Velocity, Health, Defense, Attack, StatusEffects, SpriteRender, RangeRender, InfoRender}, extra...)...)
+	err = srvsync.NetworkSync(world, &entity, append([]donburi.IComponentType{Creep, Position, Health, Defense, Attack, StatusEffects, SpriteRender, RangeRender, InfoRender}, extra...)...)
 	if err != nil {
 		return nil, err
 	}
 	creep := world.Entry(entity)
 	Position.Set(creep, &PositionData{X: x, Y: y})
-	balance := config.GetBalance(world).SuperCreep
-	Velocity.Set(creep, &VelocityData{X: balance.VelocityX, Y: balance.VelocityY})
-	name := "supercreep"
-	Creep.Set(creep, &CreepData{scoreValue: balance.ScoreValue})
-	Health.Set(creep, NewHealthData(balance.Health))
-	Attack.Set(creep, &AttackData{Power: balance.AttackPower, AttackType: RangedSingle, Range: balance.AttackRange, cooldown: util.NewCooldownTimer(balance.AttackCooldown)})
-	SpriteRender.Set(creep, &SpriteRenderData{Name: name})
+	Velocity.Set(creep, &VelocityData{X: creepType.VelocityX, Y: creepType.Speed.At(creepLevel)})
+	Creep.Set(creep, &CreepData{Type: creepType.Name, scoreValue: creepType.ScoreValue, pathAround: creepType.PathAround, level: creepLevel, behaviors: behaviors})
+	Health.Set(creep, NewHealthData(max(creepType.Health.At(creepLevel), 1)))
+	Defense.Set(creep, defense)
+	Attack.Set(creep, &AttackData{
+		Power:      creepType.AttackPower.At(creepLevel),
+		AttackType: attackType,
+		Range:      creepType.AttackRange,
+		cooldown:   util.NewCooldownTimer(creepType.AttackCooldown),
+	})
+	SpriteRender.Set(creep, &SpriteRenderData{Name: creepType.Sprite})
 	RangeRender.Set(creep, &RangeRenderData{})
 	InfoRender.Set(creep, &InfoRenderData{})
+	if creepType.Flying {
+		GetGameStats().IncrementStat("FlyingSpawned")
+	}
 	return creep, nil
 }
 
+// chooseCreepType picks a type name at random in proportion to the spawn weights at the creep level
+func chooseCreepType(rng *rand.Rand, types []config.CreepTypeBalance, creepLevel int) (string, error) {
+	total := 0
+	for _, creepType := range types {
+		total += max(creepType.SpawnWeight.At(creepLevel), 0)
+	}
+	if total <= 0 {
+		return "", fmt.Errorf("no creep types spawn at level %v", creepLevel)
+	}
+	pick := rng.IntN(total)
+	for _, creepType := range types {
+		pick -= max(creepType.SpawnWeight.At(creepLevel), 0)
+		if pick < 0 {
+			return creepType.Name, nil
+		}
+	}
+	return "", fmt.Errorf("no creep types spawn at level %v", creepLevel)
+}
+
 const maxTryMove = 10
 
 func (c *CreepData) Update(entry *donburi.Entry) error {
+	effects := StatusEffects.Get(entry)
+	if effects.Has(Stun) {
+		// stunned creeps neither move nor attack and their cooldown is frozen
+		return nil
+	}
 	pos := Position.Get(entry)
 	v := Velocity.Get(entry)
-	newPt := image.Pt(pos.X+v.X, pos.Y+v.Y)
+	vx, vy := effects.SlowVelocity(entry.World, v.X, v.Y)
+	newPt := image.Pt(pos.X+vx, pos.Y+vy)
+	if c.pathAround {
+		// steered around towers by the nav grid so they don't get stuck against them,
+		// when the towers wall off the base there is no path so fall back to attacking through
+		if pt, ok := PathStep(entry.World, image.Pt(pos.X, pos.Y), GetRect(entry).Size(), max(util.Abs(vx), util.Abs(vy))); ok {
+			newPt = pt
+		}
+	}
 	if c.TryMoveTo(entry, pos, newPt, maxTryMove) {
 		v.blocked = false
 	} else {
 		v.blocked = true
 	}
 	a := Attack.Get(entry)
-	a.AttackEnemyRange(entry, nil, Tower, Player)
+	if err := a.AttackEnemyRange(entry, nil, nil, Tower, Player); err != nil {
+		return err
+	}
 
+	for _, behavior := range c.behaviors {
+		if err := behavior.Update(entry); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -100,7 +173,12 @@ func (c *CreepData) TryMoveTo(entry *donburi.Entry, curPos *PositionData, newPt
 	rect := GetRect(entry)
 	newRect := image.Rect(newPt.X, newPt.Y, newPt.X+rect.Dx(), newPt.Y+rect.Dy())
 
-	collision := DetectCollisionsEntry(entry.World, entry.Entity(), newRect, util.CreateOrFilter(Bullet))
+	// flying creeps only stop at the base, ground creeps pass under them
+	exclude := util.CreateOrFilter(Bullet, Flying)
+	if entry.HasComponent(Flying) {
+		exclude = util.CreateOrFilter(Bullet, Tower, Creep)
+	}
+	collision := DetectCollisionsEntry(entry.World, entry.Entity(), newRect, exclude)
 	if collision == nil {
 		curPos.X = newPt.X
 		curPos.Y = newPt.Y
@@ -118,23 +196,48 @@ func (c *CreepData) TryMoveTo(entry *donburi.Entry, curPos *PositionData, newPt
 
 		return c.TryMoveTo(entry, curPos, image.Pt(newX, newPt.Y), maxTry-1)
 	} else {
-		// BUG creeps can still get stuck on each other just above the player and not be able to attack, and the player can't attack them
-		// if it can go a little way then let it move as far as possible
-		// collRect := GetRect(collision)
-
-		// if rect.Max.Y >= collRect.Min.Y {
-		// 	newY := collRect.Min.Y - 1
-		// 	return c.TryMoveTo(entry, curPos, image.Pt(newPt.X, newY), maxTry-1)
-		// }
-
 		// try to creep forward just a bit
 		return c.TryMoveTo(entry, curPos, image.Pt(curPos.X, curPos.Y+1), maxTry-1)
 	}
-
-	// TODO allow creeps to move sideways around the tower? (if so don't allow for player)
-	// return false
 }
 
 func (c *CreepData) GetScoreValue() int {
 	return c.scoreValue
 }
+
+// IsSplit reports whether the creep was spawned by another creep splitting
+func (c *CreepData) IsSplit() bool {
+	return c.generation > 0
+}
+
+// Split spawns the creep's split types side by side across the spot where it died, at the same creep level
+func (c *CreepData) Split(entry *donburi.Entry) ([]*donburi.Entry, error) {
+	creepType := config.GetBalance(entry.World).Creep.GetType(c.Type)
+	if creepType == nil || len(creepType.Split) == 0 {
+		return nil, nil
+	}
+	count := 0
+	for _, split := range creepType.Split {
+		count += split.Count
+	}
+	rect := GetRect(entry)
+	children := make([]*donburi.Entry, 0, count)
+	for _, split := range creepType.Split {
+		for range split.Count {
+			x := rect.Min.X + len(children)*rect.Dx()/count
+			child, err := NewCreepOfType(entry.World, x, rect.Min.Y+rect.Dy()/4, split.Type, c.level)
+			if err != nil {
+				return children, err
+			}
+			Creep.Get(child).generation = c.generation + 1
+			children = append(children, child)
+		}
+	}
+	GetGameStats().UpdateStat("SplitsSpawned", len(children))
+	return children, nil
+}
+
+// IsSuper reports whether this is a multiplayer super creep
+func (c *CreepData) IsSuper() bool {
+	return c.super
+}
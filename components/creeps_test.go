@@ -0,0 +1,290 @@
+package components
+
+import (
+	"image"
+	"math/rand/v2"
+	"slices"
+	"testing"
+
+	"tower-defense/config"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/yohamta/donburi"
+	"github.com/yohamta/donburi/filter"
+)
+
+func TestNewCreepOfType_ScalesWithLevel(t *testing.T) {
+	world := newAttackTestWorld(t)
+
+	creep, err := NewCreepOfType(world, 10, 20, "Brute", 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := Creep.Get(creep).Type; got != "Brute" {
+		t.Errorf("Type = %v, want Brute", got)
+	}
+	if got := SpriteRender.Get(creep).Name; got != "creep4" {
+		t.Errorf("sprite = %v, want creep4", got)
+	}
+	if got := Velocity.Get(creep).Y; got != 5 {
+		t.Errorf("speed = %v, want 5", got)
+	}
+	if got := Health.Get(creep).MaxHealth; got != 6 {
+		t.Errorf("health = %v, want 6", got)
+	}
+	attack := Attack.Get(creep)
+	if attack.Power != 3 || attack.Range != 40 || attack.AttackType != RangedSingle {
+		t.Errorf("attack = %+v, want power 3 range 40 ranged", attack)
+	}
+	if got := Creep.Get(creep).GetScoreValue(); got != 20 {
+		t.Errorf("score value = %v, want 20", got)
+	}
+
+	if _, err := NewCreepOfType(world, 0, 0, "Missing", 1); err == nil {
+		t.Error("NewCreepOfType() of unknown type returned no error")
+	}
+}
+
+func TestNewSuperCreep(t *testing.T) {
+	world := newAttackTestWorld(t)
+
+	creep, err := NewSuperCreep(world, 0, 0, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := Creep.Get(creep)
+	if !data.IsSuper() || data.Type != "Super" {
+		t.Errorf("creep = %+v, want a super creep of type Super", data)
+	}
+	if got := Velocity.Get(creep).X; got != 5 {
+		t.Errorf("velocity x = %v, want 5", got)
+	}
+}
+
+func Test_chooseCreepType(t *testing.T) {
+	types := []config.CreepTypeBalance{
+		{Name: "Never", SpawnWeight: config.LevelFormula{Base: 0}},
+		{Name: "Early", SpawnWeight: config.LevelFormula{Base: 10, LevelMultiplier: -1, LevelDivisor: 1}},
+		{Name: "Late", SpawnWeight: config.LevelFormula{Base: -5, LevelMultiplier: 1, LevelDivisor: 1}},
+	}
+	tests := []struct {
+		level int
+		want  string
+	}{
+		{1, "Early"},
+		{5, "Early"},
+		{10, "Late"},
+		{20, "Late"},
+	}
+	rng := rand.New(rand.NewPCG(1, 2))
+	for _, tt := range tests {
+		for range 20 {
+			got, err := chooseCreepType(rng, types, tt.level)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Fatalf("chooseCreepType() at level %v = %v, want %v", tt.level, got, tt.want)
+			}
+		}
+	}
+
+	if _, err := chooseCreepType(rng, types[:1], 1); err == nil {
+		t.Error("chooseCreepType() with no spawnable types returned no error")
+	}
+}
+
+func TestAttackData_DamageEnemyCountsKillsByCreepType(t *testing.T) {
+	world := newAttackTestWorld(t)
+	creep, err := NewCreepOfType(world, 0, 0, "Grunt", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	attacker := world.Entry(world.Create(Attack))
+	attack := &AttackData{Power: 100}
+
+	attack.DamageEnemy(attacker, creep, attack.Power, nil)
+	if got := GetGameStats().GetStat("KilledGrunt"); got != 1 {
+		t.Errorf("KilledGrunt = %v, want 1", got)
+	}
+}
+
+func newFlyingTestCreep(t *testing.T, world donburi.World, x, y int) *donburi.Entry {
+	t.Helper()
+	creep, err := NewCreepOfType(world, x, y, "Wasp", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	SpriteRender.Get(creep).image = ebiten.NewImage(10, 10)
+	return creep
+}
+
+func TestCreepData_FlyingPassesOverTowersAndCreeps(t *testing.T) {
+	world := newNavTestWorld(t)
+	flyer := newFlyingTestCreep(t, world, 80, 80)
+	if !flyer.HasComponent(Flying) {
+		t.Fatal("Wasp has no Flying component")
+	}
+	if got := GetGameStats().GetStat("FlyingSpawned"); got != 1 {
+		t.Errorf("FlyingSpawned = %v, want 1", got)
+	}
+
+	// ground creeps pass under flying ones
+	ground := newAttackTestCreep(world, 80, 60, 5)
+	if !Creep.Get(ground).TryMoveTo(ground, Position.Get(ground), image.Pt(80, 75), 1) {
+		t.Error("ground creep blocked by a flying creep")
+	}
+
+	newNavTestTower(world, 70, 92)
+	newAttackTestCreep(world, 80, 110, 5)
+	pos := Position.Get(flyer)
+	if !Creep.Get(flyer).TryMoveTo(flyer, pos, image.Pt(80, 100), maxTryMove) || *pos != (PositionData{X: 80, Y: 100}) {
+		t.Errorf("flying creep moved to %v, want straight over the tower and creep to (80,100)", *pos)
+	}
+}
+
+func TestAttackData_OnlyAntiAirTargetsFlyingCreeps(t *testing.T) {
+	world := newAttackTestWorld(t)
+	flyer := newFlyingTestCreep(t, world, 20, 0)
+
+	tower := world.Entry(world.Create(Tower, Position, SpriteRender, Attack))
+	SpriteRender.Set(tower, &SpriteRenderData{image: ebiten.NewImage(10, 10)})
+	ground := &AttackData{Power: 1, Range: 30}
+	Attack.Set(tower, ground)
+	if got := ground.FindEnemyRange(tower, Creep); got != nil {
+		t.Error("tower without anti-air found a flying creep")
+	}
+	if got, _ := ground.AttackArea(tower, GetRect(tower), 30, nil, Creep); got != 0 {
+		t.Errorf("AttackArea() without anti-air hit %v flying creeps, want 0", got)
+	}
+
+	antiAir := &AttackData{Power: 100, Range: 30, AntiAir: true}
+	if got := antiAir.FindEnemyRange(tower, Creep); got == nil || got.Entity() != flyer.Entity() {
+		t.Fatal("anti-air tower didn't find the flying creep")
+	}
+	antiAir.DamageEnemy(tower, flyer, antiAir.Power, nil)
+	if got := GetGameStats().GetStat("FlyingKills"); got != 1 {
+		t.Errorf("FlyingKills = %v, want 1", got)
+	}
+}
+
+// giveCreepImages sets a sprite image on creeps that don't have one since tests can't load the assets
+func giveCreepImages(world donburi.World, size int) {
+	donburi.NewQuery(filter.Contains(Creep, SpriteRender)).Each(world, func(entry *donburi.Entry) {
+		if sprite := SpriteRender.Get(entry); sprite.image == nil {
+			sprite.image = ebiten.NewImage(size, size)
+		}
+	})
+}
+
+func TestCreepData_SplitsOnDeath(t *testing.T) {
+	world := newAttackTestWorld(t)
+	blob, err := NewCreepOfType(world, 100, 200, "Blob", 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	giveCreepImages(world, 48)
+	attacker := world.Entry(world.Create(Attack))
+	attack := &AttackData{Power: 100, AreaRadius: 500}
+	Attack.Set(attacker, attack)
+
+	// the blast that kills the blob doesn't hit the children spawned where it died
+	if got, err := attack.AttackArea(attacker, GetRect(blob), attack.AreaRadius, OnKillCreep, Creep); err != nil || got != 1 {
+		t.Fatalf("AttackArea() hit %v creeps, %v, want only the blob", got, err)
+	}
+	children := make([]*donburi.Entry, 0)
+	donburi.NewQuery(filter.Contains(Creep)).Each(world, func(entry *donburi.Entry) {
+		children = append(children, entry)
+	})
+	if len(children) != 2 {
+		t.Fatalf("blob split into %v creeps, want 2", len(children))
+	}
+	for i, child := range children {
+		data := Creep.Get(child)
+		if data.Type != "Blobling" || data.level != 5 || !data.IsSplit() {
+			t.Errorf("child %v = %+v, want a split Blobling at level 5", i, data)
+		}
+		if got := Position.Get(child).Y; got != 212 {
+			t.Errorf("child %v y = %v, want 212 a quarter down the blob", i, got)
+		}
+	}
+	if got := creepXs(world); !slices.Equal(got, []int{100, 124}) {
+		t.Errorf("children x = %v, want side by side across the blob", got)
+	}
+	if got := GetGameStats().GetStat("SplitsSpawned"); got != 2 {
+		t.Errorf("SplitsSpawned = %v, want 2", got)
+	}
+
+	giveCreepImages(world, 24)
+	attack.AttackArea(attacker, GetRect(children[0]), attack.AreaRadius, OnKillCreep, Creep)
+	if got := GetGameStats().GetStat("SplitKills"); got != 2 {
+		t.Errorf("SplitKills = %v, want 2", got)
+	}
+	if got := GetGameStats().GetStat("SplitScore"); got != 10 {
+		t.Errorf("SplitScore = %v, want 10 for the two children and not the blob", got)
+	}
+	if got := Player.Get(Player.MustFirst(world)).Score; got != 25 {
+		t.Errorf("player score = %v, want 25", got)
+	}
+}
+
+func TestAttackData_DamageEnemyReturnsSplitErrors(t *testing.T) {
+	world := newAttackTestWorld(t)
+	balance := *config.DefaultBalance()
+	balance.Creep.Types = append(slices.Clone(balance.Creep.Types), config.CreepTypeBalance{
+		Name: "Broken", Sprite: "creepBlob", Health: config.LevelFormula{Base: 1}, AttackCooldown: 5,
+		Split: []config.CreepSplitBalance{{Type: "Missing", Count: 1}},
+	})
+	config.NewBalance(world, &balance)
+
+	broken, err := NewCreepOfType(world, 0, 0, "Broken", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	giveCreepImages(world, 24)
+	attack := &AttackData{Power: 10}
+	if killed, err := attack.DamageEnemy(broken, broken, attack.Power, nil); !killed || err == nil {
+		t.Errorf("DamageEnemy() = %v, %v, want a kill and the split error", killed, err)
+	}
+}
+
+func TestCreepData_SplitChainsInOneTick(t *testing.T) {
+	world := newAttackTestWorld(t)
+	balance := *config.DefaultBalance()
+	balance.Creep.Types = append(slices.Clone(balance.Creep.Types), config.CreepTypeBalance{
+		Name: "Mother", Sprite: "creepBlob", Health: config.LevelFormula{Base: 1}, AttackCooldown: 5,
+		Split: []config.CreepSplitBalance{{Type: "Blob", Count: 1}},
+	})
+	config.NewBalance(world, &balance)
+
+	mother, err := NewCreepOfType(world, 0, 0, "Mother", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	attack := &AttackData{Power: 100}
+	// kill each generation as soon as it appears like several bullets landing in the same tick
+	for generation := 0; generation < 2; generation++ {
+		giveCreepImages(world, 48)
+		victims := make([]*donburi.Entry, 0)
+		donburi.NewQuery(filter.Contains(Creep)).Each(world, func(entry *donburi.Entry) {
+			victims = append(victims, entry)
+		})
+		for _, victim := range victims {
+			attack.DamageEnemy(mother, victim, attack.Power, OnKillCreep)
+		}
+	}
+
+	count := 0
+	donburi.NewQuery(filter.Contains(Creep)).Each(world, func(entry *donburi.Entry) {
+		count++
+		if data := Creep.Get(entry); data.Type != "Blobling" || data.generation != 2 {
+			t.Errorf("creep = %+v, want a second generation Blobling", data)
+		}
+	})
+	if count != 2 {
+		t.Errorf("chain left %v creeps, want 2 Bloblings", count)
+	}
+	if got := GetGameStats().GetStat("SplitsSpawned"); got != 3 {
+		t.Errorf("SplitsSpawned = %v, want 3", got)
+	}
+}
@@ -0,0 +1,123 @@
+package components
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"slices"
+
+	"tower-defense/config"
+
+	"github.com/yohamta/donburi"
+)
+
+type DamageType int
+
+const (
+	Kinetic DamageType = iota
+	Explosive
+	Energy
+)
+
+var damageTypeNames = []string{"Kinetic", "Explosive", "Energy"}
+
+// short labels drawn on towers
+var damageTypeLabels = []string{"KIN", "EXP", "NRG"}
+
+var strongDamageColor = color.RGBA{60, 255, 60, 255}
+var weakDamageColor = color.RGBA{255, 80, 60, 255}
+
+// DefenseData is a creep's armor and resistances, synced so the tower overlay can show effectiveness in the viewer too
+type DefenseData struct {
+	// Armor is taken off every hit after resistances
+	Armor int
+	// Resistances are the percent less damage taken from each damage type, indexed by type, negative for a weakness
+	Resistances []int
+}
+
+var Defense = donburi.NewComponentType[DefenseData]()
+
+// ParseDamageType converts a balance damage type name, empty defaults to Kinetic
+func ParseDamageType(name string) (DamageType, error) {
+	if name == "" {
+		return Kinetic, nil
+	}
+	index := slices.Index(damageTypeNames, name)
+	if index < 0 {
+		return Kinetic, fmt.Errorf("unknown damage type %q", name)
+	}
+	return DamageType(index), nil
+}
+
+func (dt DamageType) String() string {
+	if dt < 0 || int(dt) >= len(damageTypeNames) {
+		return ""
+	}
+	return damageTypeNames[dt]
+}
+
+func (dt DamageType) Label() string {
+	if dt < 0 || int(dt) >= len(damageTypeLabels) {
+		return ""
+	}
+	return damageTypeLabels[dt]
+}
+
+// NewDefenseData converts a creep type's armor and resistances by damage type name
+func NewDefenseData(creepType *config.CreepTypeBalance) (*DefenseData, error) {
+	defense := &DefenseData{Armor: creepType.Armor, Resistances: make([]int, len(damageTypeNames))}
+	for name, resistance := range creepType.Resistances {
+		damageType, err := ParseDamageType(name)
+		if err != nil {
+			return nil, err
+		}
+		defense.Resistances[damageType] = resistance
+	}
+	return defense, nil
+}
+
+// Resistance is the percent less damage taken from the damage type
+func (d *DefenseData) Resistance(damageType DamageType) int {
+	if int(damageType) >= len(d.Resistances) {
+		return 0
+	}
+	return d.Resistances[damageType]
+}
+
+// CalculateDamage scales the damage by the defender's resistance to its type then takes off the defender's armor, shred lowers the armor
+// and can take it below zero for bonus damage. Every hit does at least 1 damage, a nil defense only counts the shred.
+func CalculateDamage(damage int, damageType DamageType, defense *DefenseData, shred int) int {
+	if damage <= 0 {
+		return 0
+	}
+	armor := -shred
+	if defense != nil {
+		damage = int(math.Round(float64(damage) * float64(100-defense.Resistance(damageType)) / 100))
+		armor += defense.Armor
+	}
+	return max(damage-armor, 1)
+}
+
+// Effectiveness is the percent of the attack's power that gets through to the enemy
+func (a *AttackData) Effectiveness(enemy *donburi.Entry) int {
+	if a.Power <= 0 || !enemy.HasComponent(Defense) {
+		return 100
+	}
+	return CalculateDamage(a.Power, a.DamageType, Defense.Get(enemy), 0) * 100 / a.Power
+}
+
+// damageAgainst is the damage a hit does to the enemy after its defense and any armor shred on it
+func (a *AttackData) damageAgainst(enemy *donburi.Entry, damage int) int {
+	if a.ignoreDefense {
+		return damage
+	}
+	var defense *DefenseData
+	if enemy.HasComponent(Defense) {
+		defense = Defense.Get(enemy)
+	}
+	shred := 0
+	if enemy.HasComponent(StatusEffects) {
+		shred = StatusEffects.Get(enemy).Strength(enemy.World, ArmorShred)
+	}
+	return CalculateDamage(damage, a.DamageType, defense, shred)
+}
@@ -0,0 +1,142 @@
+package components
+
+import (
+	"image"
+	"testing"
+)
+
+func TestParseDamageType(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    DamageType
+		wantErr bool
+	}{
+		{"", Kinetic, false},
+		{"Kinetic", Kinetic, false},
+		{"Explosive", Explosive, false},
+		{"Energy", Energy, false},
+		{"Sonic", Kinetic, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseDamageType(tt.name)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseDamageType() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseDamageType() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCalculateDamage(t *testing.T) {
+	defense := &DefenseData{Armor: 1, Resistances: []int{50, -50, 100}}
+	tests := []struct {
+		name       string
+		damage     int
+		damageType DamageType
+		defense    *DefenseData
+		shred      int
+		want       int
+	}{
+		{"no defense", 4, Kinetic, nil, 0, 4},
+		{"shred without defense", 4, Kinetic, nil, 1, 5},
+		{"resisted", 6, Kinetic, defense, 0, 2},
+		{"weakness", 6, Explosive, defense, 0, 8},
+		{"immune still hits", 6, Energy, defense, 0, 1},
+		{"shred cancels armor", 6, Kinetic, defense, 1, 3},
+		{"armor minimum", 1, Kinetic, defense, 0, 1},
+		{"no damage", 0, Explosive, defense, 2, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CalculateDamage(tt.damage, tt.damageType, tt.defense, tt.shred); got != tt.want {
+				t.Errorf("CalculateDamage() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAttackData_DamageEnemyUsesCreepDefense(t *testing.T) {
+	world := newAttackTestWorld(t)
+	brute, err := NewCreepOfType(world, 0, 0, "Brute", 30)
+	if err != nil {
+		t.Fatal(err)
+	}
+	attacker := world.Entry(world.Create(Attack))
+	health := Health.Get(brute)
+
+	// brutes have 1 armor, resist kinetic by 25% and are weak to explosive by 25%
+	kinetic := &AttackData{Power: 4, DamageType: Kinetic}
+	kinetic.DamageEnemy(attacker, brute, kinetic.Power, nil)
+	if got := health.MaxHealth - health.Health; got != 2 {
+		t.Errorf("kinetic damage = %v, want 2", got)
+	}
+	if got := kinetic.Effectiveness(brute); got != 50 {
+		t.Errorf("kinetic Effectiveness() = %v, want 50", got)
+	}
+
+	health.Health = health.MaxHealth
+	explosive := &AttackData{Power: 4, DamageType: Explosive}
+	explosive.DamageEnemy(attacker, brute, explosive.Power, nil)
+	if got := health.MaxHealth - health.Health; got != 4 {
+		t.Errorf("explosive damage = %v, want 4", got)
+	}
+
+	health.Health = health.MaxHealth
+	poison := &AttackData{ignoreDefense: true}
+	poison.DamageEnemy(attacker, brute, 4, nil)
+	if got := health.MaxHealth - health.Health; got != 4 {
+		t.Errorf("poison damage = %v, want 4 through the armor", got)
+	}
+}
+
+func TestAttackData_AttackEnemyRangeRecordsEffectiveness(t *testing.T) {
+	world := newWaveTestWorld(t)
+	tower := newTestTower(t, world, 0, 0, "Venom")
+	attack := Attack.Get(tower)
+	// energy passes brute resistances, so 1 of the 4 power is lost to its armor
+	attack.Power = 4
+	if attack.LastEffectiveness != 0 {
+		t.Fatalf("LastEffectiveness = %v before attacking, want 0", attack.LastEffectiveness)
+	}
+	brute, err := NewCreepOfType(world, 30, 0, "Brute", 30)
+	if err != nil {
+		t.Fatal(err)
+	}
+	giveCreepImages(world, 10)
+
+	if err := attack.AttackEnemyRange(tower, OnKillCreep, AfterTowerAttack, Creep); err != nil {
+		t.Fatal(err)
+	}
+	if attack.LastEffectiveness != 75 {
+		t.Errorf("LastEffectiveness = %v, want 75 against the brute", attack.LastEffectiveness)
+	}
+	if got := Health.Get(brute); got.MaxHealth-got.Health != 3 {
+		t.Errorf("brute took %v damage, want 3", got.MaxHealth-got.Health)
+	}
+}
+
+func TestNewTower_DamageTypeCarriedByBullets(t *testing.T) {
+	world := newAttackTestWorld(t)
+	tower, err := NewTower(world, 0, 0, "Splash")
+	if err != nil {
+		t.Fatal(err)
+	}
+	attack := Attack.Get(tower)
+	if attack.DamageType != Explosive {
+		t.Fatalf("Splash damage type = %v, want Explosive", attack.DamageType)
+	}
+	if attack.Hazard.DamageType != Explosive {
+		t.Errorf("Splash hazard damage type = %v, want Explosive", attack.Hazard.DamageType)
+	}
+
+	bullet, err := NewBullet(world, image.Pt(0, 0), image.Pt(10, 10), attack, 8, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := Attack.Get(bullet).DamageType; got != Explosive {
+		t.Errorf("bullet damage type = %v, want Explosive", got)
+	}
+}
@@ -0,0 +1,215 @@
+package components
+
+import (
+	"fmt"
+	"image/color"
+	"tower-defense/config"
+
+	"github.com/yohamta/donburi"
+)
+
+type EffectKind int
+
+const (
+	NoEffect EffectKind = iota
+	Slow
+	Poison
+	Stun
+	ArmorShred
+)
+
+var effectKindNames = map[string]EffectKind{
+	"Slow":       Slow,
+	"Poison":     Poison,
+	"Stun":       Stun,
+	"ArmorShred": ArmorShred,
+}
+
+// effect tints in draw priority order, the first active effect colors the sprite
+var effectTints = []struct {
+	kind    EffectKind
+	r, g, b float32
+	icon    color.RGBA
+}{
+	{Stun, 1, 1, 0.4, color.RGBA{255, 255, 0, 255}},
+	{Poison, 0.5, 1, 0.5, color.RGBA{0, 200, 0, 255}},
+	{Slow, 0.5, 0.8, 1, color.RGBA{80, 160, 255, 255}},
+	{ArmorShred, 1, 0.6, 0.5, color.RGBA{255, 100, 60, 255}},
+}
+
+// ParseEffectKind converts a balance effect name, empty means no effect
+func ParseEffectKind(name string) (EffectKind, error) {
+	if name == "" {
+		return NoEffect, nil
+	}
+	kind, ok := effectKindNames[name]
+	if !ok {
+		return NoEffect, fmt.Errorf("unknown effect kind %q", name)
+	}
+	return kind, nil
+}
+
+func (k EffectKind) String() string {
+	for name, kind := range effectKindNames {
+		if kind == k {
+			return name
+		}
+	}
+	return ""
+}
+
+// AttackEffect is the status effect an attack applies to whatever it hits
+type AttackEffect struct {
+	Kind     EffectKind
+	Strength int
+	Duration int
+}
+
+// NewAttackEffect converts the balance for an attack effect
+func NewAttackEffect(effect config.AttackEffectBalance) (AttackEffect, error) {
+	kind, err := ParseEffectKind(effect.Kind)
+	if err != nil {
+		return AttackEffect{}, err
+	}
+	return AttackEffect{Kind: kind, Strength: effect.Strength, Duration: effect.Duration}, nil
+}
+
+type StatusEffect struct {
+	Kind      EffectKind
+	Strength  int
+	Stacks    int
+	Remaining int
+	elapsed   int
+}
+
+type StatusEffectsData struct {
+	Effects []StatusEffect
+}
+
+var StatusEffects = donburi.NewComponentType[StatusEffectsData]()
+
+func (s *StatusEffectsData) find(kind EffectKind) *StatusEffect {
+	for i := range s.Effects {
+		if s.Effects[i].Kind == kind {
+			return &s.Effects[i]
+		}
+	}
+	return nil
+}
+
+// Apply adds the effect or combines it with an active effect of the same kind using the balance stacking rule
+func (s *StatusEffectsData) Apply(effects map[string]config.EffectBalance, effect AttackEffect) {
+	if effect.Kind == NoEffect || effect.Duration <= 0 {
+		return
+	}
+	rules := effects[effect.Kind.String()]
+	current := s.find(effect.Kind)
+	if current == nil {
+		s.Effects = append(s.Effects, StatusEffect{Kind: effect.Kind, Strength: effect.Strength, Stacks: 1, Remaining: effect.Duration})
+		current = &s.Effects[len(s.Effects)-1]
+	} else {
+		current.Strength = max(current.Strength, effect.Strength)
+		switch rules.Stacking {
+		case "stack":
+			current.Stacks = min(current.Stacks+1, max(rules.MaxStacks, 1))
+			current.Remaining = max(current.Remaining, effect.Duration)
+		case "extend":
+			current.Remaining += effect.Duration
+		default:
+			current.Remaining = max(current.Remaining, effect.Duration)
+		}
+	}
+	if rules.MaxDuration > 0 {
+		current.Remaining = min(current.Remaining, rules.MaxDuration)
+	}
+}
+
+// Strength returns the total strength of an effect kind across its stacks, capped by the balance, or 0 when not active
+func (s *StatusEffectsData) Strength(world donburi.World, kind EffectKind) int {
+	current := s.find(kind)
+	if current == nil {
+		return 0
+	}
+	return current.total(config.GetBalance(world).Effects)
+}
+
+func (e *StatusEffect) total(effects map[string]config.EffectBalance) int {
+	strength := e.Strength * e.Stacks
+	if maxStrength := effects[e.Kind.String()].MaxStrength; maxStrength > 0 {
+		strength = min(strength, maxStrength)
+	}
+	return strength
+}
+
+func (s *StatusEffectsData) Has(kind EffectKind) bool {
+	return s.find(kind) != nil
+}
+
+// SlowVelocity scales a velocity by the slow percentage, a moving entity always keeps at least 1 speed
+func (s *StatusEffectsData) SlowVelocity(world donburi.World, x, y int) (int, int) {
+	percent := s.Strength(world, Slow)
+	if percent <= 0 {
+		return x, y
+	}
+	scale := func(v int) int {
+		if v == 0 {
+			return 0
+		}
+		scaled := v * (100 - min(percent, 100)) / 100
+		if scaled == 0 {
+			if v > 0 {
+				return 1
+			}
+			return -1
+		}
+		return scaled
+	}
+	return scale(x), scale(y)
+}
+
+// Update counts down the effects, removing expired ones and dealing poison damage every tick interval
+func (s *StatusEffectsData) Update(entry *donburi.Entry) error {
+	balance := config.GetBalance(entry.World)
+	active := s.Effects[:0]
+	var poison int
+	for _, effect := range s.Effects {
+		effect.elapsed++
+		effect.Remaining--
+		if effect.Kind == Poison {
+			interval := max(balance.Effects[Poison.String()].TickInterval, 1)
+			if effect.elapsed%interval == 0 {
+				poison += effect.total(balance.Effects)
+			}
+		}
+		if effect.Remaining > 0 {
+			active = append(active, effect)
+		}
+	}
+	s.Effects = active
+
+	if poison > 0 {
+		// poison kills are credited to the player the same as any other kill
+		var afterKill func(*donburi.Entry, *donburi.Entry)
+		if entry.HasComponent(Creep) {
+			afterKill = OnKillCreep
+		}
+		attack := &AttackData{ignoreDefense: true}
+		killed, err := attack.DamageEnemy(entry, entry, poison, afterKill)
+		if err != nil {
+			return err
+		}
+		if killed {
+			GetGameStats().IncrementStat("PoisonKills")
+		}
+	}
+	return nil
+}
+
+// ApplyEffect applies the attack's effect to the enemy if it can carry status effects
+func (a *AttackData) ApplyEffect(enemy *donburi.Entry) {
+	if a.Effect.Kind == NoEffect || !enemy.HasComponent(StatusEffects) {
+		return
+	}
+	StatusEffects.Get(enemy).Apply(config.GetBalance(enemy.World).Effects, a.Effect)
+	GetGameStats().IncrementStat("EffectsApplied")
+}
@@ -0,0 +1,143 @@
+package components
+
+import (
+	"testing"
+	"tower-defense/config"
+)
+
+func TestStatusEffectsData_ApplyStackingRules(t *testing.T) {
+	rules := map[string]config.EffectBalance{
+		"Slow":       {Stacking: "refresh", MaxStacks: 1},
+		"Poison":     {Stacking: "stack", MaxStacks: 2},
+		"Stun":       {Stacking: "extend", MaxStacks: 1, MaxDuration: 25},
+		"ArmorShred": {Stacking: "stack", MaxStacks: 3},
+	}
+	tests := []struct {
+		name          string
+		first, second AttackEffect
+		wantStacks    int
+		wantRemaining int
+		wantStrength  int
+	}{
+		{"refresh keeps one stack and the longer duration", AttackEffect{Slow, 40, 30}, AttackEffect{Slow, 20, 20}, 1, 20, 40},
+		{"stack adds a stack and refreshes", AttackEffect{Poison, 1, 30}, AttackEffect{Poison, 1, 60}, 2, 60, 1},
+		{"extend adds duration up to the max", AttackEffect{Stun, 0, 15}, AttackEffect{Stun, 0, 25}, 1, 25, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			effects := &StatusEffectsData{}
+			effects.Apply(rules, tt.first)
+			// simulate some ticks passing before the second hit
+			effects.Effects[0].Remaining -= 10
+			effects.Apply(rules, tt.second)
+
+			if len(effects.Effects) != 1 {
+				t.Fatalf("got %v effects, want 1", len(effects.Effects))
+			}
+			got := effects.Effects[0]
+			if got.Stacks != tt.wantStacks || got.Remaining != tt.wantRemaining || got.Strength != tt.wantStrength {
+				t.Errorf("effect = %+v, want stacks %v remaining %v strength %v", got, tt.wantStacks, tt.wantRemaining, tt.wantStrength)
+			}
+		})
+	}
+
+	t.Run("stacks are capped", func(t *testing.T) {
+		effects := &StatusEffectsData{}
+		for range 3 {
+			effects.Apply(rules, AttackEffect{Poison, 1, 30})
+		}
+		if got := effects.Effects[0].Stacks; got != 2 {
+			t.Errorf("stacks = %v, want 2", got)
+		}
+	})
+}
+
+func TestStatusEffectsData_SlowVelocity(t *testing.T) {
+	world := newAttackTestWorld(t)
+	tests := []struct {
+		name         string
+		strength     int
+		vx, vy       int
+		wantX, wantY int
+	}{
+		{"no slow keeps velocity", 0, 0, 6, 0, 6},
+		{"40 percent slow", 40, 0, 5, 0, 3},
+		{"slow is capped by the balance", 100, 0, 10, 0, 2},
+		{"moving creeps keep at least 1 speed", 80, -1, 1, -1, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			effects := &StatusEffectsData{}
+			if tt.strength > 0 {
+				effects.Effects = append(effects.Effects, StatusEffect{Kind: Slow, Strength: tt.strength, Stacks: 1, Remaining: 10})
+			}
+			x, y := effects.SlowVelocity(world, tt.vx, tt.vy)
+			if x != tt.wantX || y != tt.wantY {
+				t.Errorf("SlowVelocity() = %v,%v, want %v,%v", x, y, tt.wantX, tt.wantY)
+			}
+		})
+	}
+}
+
+func TestStatusEffectsData_UpdatePoisonsAndExpires(t *testing.T) {
+	world := newAttackTestWorld(t)
+	creep := newAttackTestCreep(world, 0, 0, 3)
+	creep.AddComponent(StatusEffects)
+	effects := StatusEffects.Get(creep)
+	interval := config.GetBalance(world).Effects["Poison"].TickInterval
+	effects.Apply(config.GetBalance(world).Effects, AttackEffect{Poison, 1, interval + 1})
+	effects.Apply(config.GetBalance(world).Effects, AttackEffect{Slow, 40, 1})
+
+	if err := effects.Update(creep); err != nil {
+		t.Fatal(err)
+	}
+	if effects.Has(Slow) {
+		t.Error("slow still active after its duration")
+	}
+	for range interval - 1 {
+		if err := effects.Update(creep); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if health := Health.Get(creep).Health; health != 2 {
+		t.Errorf("health = %v after one poison tick, want 2", health)
+	}
+
+	effects.Apply(config.GetBalance(world).Effects, AttackEffect{Poison, 1, interval})
+	for range interval {
+		if err := effects.Update(creep); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if creep.Valid() {
+		t.Fatal("poisoned creep still valid, want killed")
+	}
+	if got := GetGameStats().GetStat("PoisonKills"); got != 1 {
+		t.Errorf("PoisonKills = %v, want 1", got)
+	}
+	if player := Player.Get(Player.MustFirst(world)); player.Score != 10 {
+		t.Errorf("player score = %v, want 10 from the poison kill", player.Score)
+	}
+}
+
+func TestAttackData_DamageEnemyAppliesEffectAndShred(t *testing.T) {
+	world := newAttackTestWorld(t)
+	attacker := newAttackTestCreep(world, 100, 100, 1)
+	creep := newAttackTestCreep(world, 0, 0, 10)
+	creep.AddComponent(StatusEffects)
+
+	attack := &AttackData{Power: 1, Effect: AttackEffect{ArmorShred, 1, 30}}
+	attack.DamageEnemy(attacker, creep, attack.Power, nil)
+	attack.DamageEnemy(attacker, creep, attack.Power, nil)
+
+	// first hit 1, second hit 1 plus 1 shred
+	if health := Health.Get(creep).Health; health != 7 {
+		t.Errorf("health = %v, want 7", health)
+	}
+	if got := StatusEffects.Get(creep).Strength(world, ArmorShred); got != 2 {
+		t.Errorf("shred strength = %v, want 2", got)
+	}
+	if got := GetGameStats().GetStat("EffectsApplied"); got != 2 {
+		t.Errorf("EffectsApplied = %v, want 2", got)
+	}
+}
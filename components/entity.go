@@ -59,3 +59,21 @@ func DetectCollisionsWorld(world donburi.World, rect image.Rectangle, excludeFil
 	})
 	return collision
 }
+
+// DetectCollisionsAll returns every entity matching the filter that overlaps the rect
+func DetectCollisionsAll(world donburi.World, rect image.Rectangle, includeFilter filter.LayoutFilter) []*donburi.Entry {
+	collisions := make([]*donburi.Entry, 0)
+	query := donburi.NewQuery(
+		filter.And(
+			filter.Contains(SpriteRender, Position),
+			includeFilter,
+		),
+	)
+
+	query.Each(world, func(testEntry *donburi.Entry) {
+		if rect.Overlaps(GetRect(testEntry)) {
+			collisions = append(collisions, testEntry)
+		}
+	})
+	return collisions
+}
@@ -0,0 +1,181 @@
+package components
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"tower-defense/config"
+	"tower-defense/util"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"github.com/leap-fish/necs/esync/srvsync"
+	"github.com/yohamta/donburi"
+	"github.com/yohamta/donburi/filter"
+)
+
+type HazardKind int
+
+const (
+	NoHazard HazardKind = iota
+	TarPit
+	FirePatch
+	MineField
+)
+
+var hazardKindNames = map[string]HazardKind{
+	"TarPit":    TarPit,
+	"FirePatch": FirePatch,
+	"MineField": MineField,
+}
+
+var hazardColors = map[HazardKind]color.RGBA{
+	TarPit:    {40, 25, 10, 140},
+	FirePatch: {255, 90, 0, 100},
+	MineField: {200, 200, 200, 70},
+}
+
+// HazardSpec is the ground hazard an attack's bullets leave behind
+type HazardSpec struct {
+	Kind         HazardKind
+	OnExpire     bool
+	OnHit        bool
+	Size         int
+	Duration     int
+	Power        int
+	TickInterval int
+	Charges      int
+	AreaRadius   int
+	Effect       AttackEffect
+	// DamageType is set from the tower that leaves the hazard
+	DamageType DamageType
+}
+
+// NewHazardSpec converts the balance for a tower type's hazard
+func NewHazardSpec(hazard config.HazardBalance) (HazardSpec, error) {
+	if hazard.Kind == "" {
+		return HazardSpec{}, nil
+	}
+	kind, ok := hazardKindNames[hazard.Kind]
+	if !ok {
+		return HazardSpec{}, fmt.Errorf("unknown hazard kind %q", hazard.Kind)
+	}
+	effect, err := NewAttackEffect(hazard.Effect)
+	if err != nil {
+		return HazardSpec{}, err
+	}
+	return HazardSpec{
+		Kind:         kind,
+		OnExpire:     hazard.SpawnOn == "expire" || hazard.SpawnOn == "both",
+		OnHit:        hazard.SpawnOn == "hit" || hazard.SpawnOn == "both",
+		Size:         hazard.Size,
+		Duration:     hazard.Duration,
+		Power:        hazard.Power,
+		TickInterval: hazard.TickInterval,
+		Charges:      hazard.Charges,
+		AreaRadius:   hazard.AreaRadius,
+		Effect:       effect,
+	}, nil
+}
+
+type HazardData struct {
+	Kind      HazardKind
+	Remaining int
+	Charges   int
+	interval  int
+	elapsed   int
+}
+
+type HazardRenderData struct {
+	Width, Height int
+	R, G, B, A    uint8
+}
+
+var Hazard = donburi.NewComponentType[HazardData]()
+var HazardRender = donburi.NewComponentType[HazardRenderData]()
+
+// NewHazard creates a hazard centered on the point that damages or affects creeps overlapping it until it runs out
+func NewHazard(world donburi.World, center image.Point, spec HazardSpec) (*donburi.Entry, error) {
+	entity := world.Create(Hazard, Position, Attack, HazardRender)
+	err := srvsync.NetworkSync(world, &entity, Hazard, Position, HazardRender)
+	if err != nil {
+		return nil, err
+	}
+	hazard := world.Entry(entity)
+
+	Position.Set(hazard, &PositionData{X: center.X - spec.Size/2, Y: center.Y - spec.Size/2})
+	Hazard.Set(hazard, &HazardData{Kind: spec.Kind, Remaining: spec.Duration, Charges: max(spec.Charges, 1), interval: max(spec.TickInterval, 1)})
+	Attack.Set(hazard, &AttackData{Power: spec.Power, AttackType: MeleeArea, AreaRadius: spec.AreaRadius, Effect: spec.Effect, DamageType: spec.DamageType})
+	clr := hazardColors[spec.Kind]
+	HazardRender.Set(hazard, &HazardRenderData{Width: spec.Size, Height: spec.Size, R: clr.R, G: clr.G, B: clr.B, A: clr.A})
+	GetGameStats().IncrementStat("HazardsCreated")
+	return hazard, nil
+}
+
+func (h *HazardData) Update(entry *donburi.Entry) error {
+	h.Remaining--
+	h.elapsed++
+	rect := GetRect(entry)
+	// hazards lie on the ground so flying creeps pass over them
+	creeps := DetectCollisionsAll(entry.World, rect, filter.And(filter.Contains(Creep), filter.Not(filter.Contains(Flying))))
+	a := Attack.Get(entry)
+
+	switch h.Kind {
+	case MineField:
+		// a creep stepping in sets off a mine, blasting everything nearby
+		if len(creeps) > 0 && h.elapsed >= h.interval {
+			if _, err := a.AttackArea(entry, rect, a.AreaRadius, OnHazardKill, Creep); err != nil {
+				return err
+			}
+			h.Charges--
+			h.elapsed = 0
+		}
+	default:
+		if h.elapsed%h.interval == 0 {
+			for _, creep := range creeps {
+				if !creep.Valid() {
+					continue
+				}
+				if _, err := a.DamageEnemy(entry, creep, a.Power, OnHazardKill); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	if h.Remaining <= 0 || h.Charges <= 0 {
+		entry.Remove()
+	}
+	return nil
+}
+
+// OnHazardKill credits a hazard kill to the player like a tower kill
+func OnHazardKill(hazardEntry *donburi.Entry, enemyEntry *donburi.Entry) {
+	GetGameStats().IncrementStat("HazardKills")
+	OnKillCreep(hazardEntry, enemyEntry)
+}
+
+func (hr *HazardRenderData) Draw(screen *ebiten.Image, entry *donburi.Entry) {
+	rect := hr.GetRect(entry)
+	clr := color.RGBA{hr.R, hr.G, hr.B, hr.A}
+	vector.DrawFilledRect(screen, float32(rect.Min.X), float32(rect.Min.Y), float32(rect.Dx()), float32(rect.Dy()), clr, true)
+
+	hazard := Hazard.Get(entry)
+	if hazard.Kind == MineField {
+		// mark the mine in the middle of the field
+		mid := util.MidpointRect(rect)
+		vector.DrawFilledCircle(screen, float32(mid.X), float32(mid.Y), 2, color.RGBA{255, 0, 0, 255}, true)
+	}
+	if config.GetConfig(entry.World).Debug {
+		vector.StrokeRect(screen, float32(rect.Min.X), float32(rect.Min.Y), float32(rect.Dx()), float32(rect.Dy()), 1, color.White, true)
+		if hazard.Kind == MineField && entry.HasComponent(Attack) {
+			mid := util.MidpointRect(rect)
+			vector.StrokeCircle(screen, float32(mid.X), float32(mid.Y), float32(Attack.Get(entry).AreaRadius+rect.Dx()/2), 1, clr, true)
+		}
+	}
+}
+
+func (hr *HazardRenderData) GetRect(entry *donburi.Entry) image.Rectangle {
+	pos := Position.Get(entry)
+	return image.Rect(pos.X, pos.Y, pos.X+hr.Width, pos.Y+hr.Height)
+}
@@ -0,0 +1,158 @@
+package components
+
+import (
+	"image"
+	"testing"
+	"tower-defense/config"
+)
+
+func TestNewHazardSpec(t *testing.T) {
+	tests := []struct {
+		name         string
+		spawnOn      string
+		wantOnExpire bool
+		wantOnHit    bool
+	}{
+		{"expire", "expire", true, false},
+		{"hit", "hit", false, true},
+		{"both", "both", true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec, err := NewHazardSpec(config.HazardBalance{Kind: "TarPit", SpawnOn: tt.spawnOn, Size: 10, Duration: 10})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if spec.Kind != TarPit || spec.OnExpire != tt.wantOnExpire || spec.OnHit != tt.wantOnHit {
+				t.Errorf("NewHazardSpec() = %+v, want TarPit expire %v hit %v", spec, tt.wantOnExpire, tt.wantOnHit)
+			}
+		})
+	}
+
+	if spec, err := NewHazardSpec(config.HazardBalance{}); err != nil || spec.Kind != NoHazard {
+		t.Errorf("NewHazardSpec() of empty balance = %+v, %v, want no hazard", spec, err)
+	}
+	if _, err := NewHazardSpec(config.HazardBalance{Kind: "Quicksand"}); err == nil {
+		t.Error("NewHazardSpec() of unknown kind returned no error")
+	}
+}
+
+func TestAfterBulletAttack_SpawnsHitHazard(t *testing.T) {
+	world := newAttackTestWorld(t)
+	attack := &AttackData{Power: 1, Hazard: HazardSpec{Kind: FirePatch, OnHit: true, Size: 10, Duration: 10}}
+	bullet, err := NewBullet(world, image.Pt(20, 20), image.Pt(20, 60), attack, 8, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := AfterBulletAttack(bullet); err != nil {
+		t.Fatal(err)
+	}
+	if bullet.Valid() {
+		t.Error("bullet still valid after hitting")
+	}
+	hazard, ok := Hazard.First(world)
+	if !ok {
+		t.Fatal("no hazard spawned where the bullet hit")
+	}
+	if rect := GetRect(hazard); rect != image.Rect(15, 15, 25, 25) {
+		t.Errorf("hazard rect = %v, want centered on the bullet", rect)
+	}
+}
+
+func TestHazardData_TarPitSlowsOverlappingCreeps(t *testing.T) {
+	world := newAttackTestWorld(t)
+	inside := newAttackTestCreep(world, 5, 5, 5)
+	inside.AddComponent(StatusEffects)
+	outside := newAttackTestCreep(world, 50, 50, 5)
+	outside.AddComponent(StatusEffects)
+
+	hazard, err := NewHazard(world, image.Pt(10, 10), HazardSpec{Kind: TarPit, Size: 20, Duration: 2, TickInterval: 1, Effect: AttackEffect{Slow, 30, 5}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Hazard.Get(hazard).Update(hazard); err != nil {
+		t.Fatal(err)
+	}
+	if !StatusEffects.Get(inside).Has(Slow) {
+		t.Error("creep inside the tar pit not slowed")
+	}
+	if StatusEffects.Get(outside).Has(Slow) {
+		t.Error("creep outside the tar pit slowed")
+	}
+	if health := Health.Get(inside).Health; health != 5 {
+		t.Errorf("tar pit did damage, health = %v, want 5", health)
+	}
+
+	if err := Hazard.Get(hazard).Update(hazard); err != nil {
+		t.Fatal(err)
+	}
+	if hazard.Valid() {
+		t.Error("tar pit still valid after its duration")
+	}
+	if got := GetGameStats().GetStat("HazardsCreated"); got != 1 {
+		t.Errorf("HazardsCreated = %v, want 1", got)
+	}
+}
+
+func TestHazardData_FirePatchBurnsOnIntervalAndCreditsKills(t *testing.T) {
+	world := newAttackTestWorld(t)
+	creep := newAttackTestCreep(world, 5, 5, 2)
+
+	hazard, err := NewHazard(world, image.Pt(10, 10), HazardSpec{Kind: FirePatch, Size: 20, Duration: 100, Power: 1, TickInterval: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for range 3 {
+		if err := Hazard.Get(hazard).Update(hazard); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if health := Health.Get(creep).Health; health != 1 {
+		t.Errorf("health = %v after one burn, want 1", health)
+	}
+	for range 3 {
+		if err := Hazard.Get(hazard).Update(hazard); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if creep.Valid() {
+		t.Fatal("burning creep still valid, want killed")
+	}
+	if got := GetGameStats().GetStat("HazardKills"); got != 1 {
+		t.Errorf("HazardKills = %v, want 1", got)
+	}
+	if player := Player.Get(Player.MustFirst(world)); player.Score != 10 {
+		t.Errorf("player score = %v, want 10 from the hazard kill", player.Score)
+	}
+}
+
+func TestHazardData_MineFieldDetonatesOnContact(t *testing.T) {
+	world := newAttackTestWorld(t)
+	hazard, err := NewHazard(world, image.Pt(10, 10), HazardSpec{Kind: MineField, Size: 10, Duration: 100, Power: 3, Charges: 1, AreaRadius: 20})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// nothing to set it off yet
+	if err := Hazard.Get(hazard).Update(hazard); err != nil {
+		t.Fatal(err)
+	}
+	if !hazard.Valid() {
+		t.Fatal("mine went off without a creep")
+	}
+
+	trigger := newAttackTestCreep(world, 8, 8, 3)
+	nearby := newAttackTestCreep(world, 25, 5, 10)
+	if err := Hazard.Get(hazard).Update(hazard); err != nil {
+		t.Fatal(err)
+	}
+	if trigger.Valid() {
+		t.Error("creep that stepped on the mine still valid, want killed")
+	}
+	if health := Health.Get(nearby).Health; health >= 10 {
+		t.Errorf("nearby creep health = %v, want blast damage", health)
+	}
+	if hazard.Valid() {
+		t.Error("mine field still valid after using its only charge")
+	}
+}
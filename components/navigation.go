@@ -0,0 +1,187 @@
+package components
+
+import (
+	"image"
+	"tower-defense/config"
+
+	"github.com/yohamta/donburi"
+	"github.com/yohamta/donburi/filter"
+)
+
+// NavGridData holds flow fields over the board that lead creeps around towers to the base.
+// There is one field per creep size since bigger creeps need wider gaps, they are only used on the server
+// and are rebuilt lazily after towers are placed or destroyed.
+type NavGridData struct {
+	dirty  bool
+	fields map[image.Point]*flowField
+}
+
+type flowField struct {
+	cellSize   int
+	cols, rows int
+	// dist is the number of steps from each cell to the base, -1 when the base can't be reached
+	dist []int
+}
+
+var NavGrid = donburi.NewComponentType[NavGridData]()
+
+const defaultNavCellSize = 8
+
+// neighbor steps in order of preference, ties favor moving straight down the lane
+var navSteps = []image.Point{
+	{0, 1}, {-1, 1}, {1, 1}, {-1, 0}, {1, 0}, {-1, -1}, {1, -1}, {0, -1},
+}
+
+func getNavGrid(world donburi.World) *NavGridData {
+	entry, ok := NavGrid.First(world)
+	if !ok {
+		entry = world.Entry(world.Create(NavGrid))
+		NavGrid.Set(entry, &NavGridData{fields: make(map[image.Point]*flowField)})
+	}
+	return NavGrid.Get(entry)
+}
+
+// MarkNavGridDirty forces the paths to be recomputed, call it whenever a tower is placed or removed
+func MarkNavGridDirty(world donburi.World) {
+	if entry, ok := NavGrid.First(world); ok {
+		NavGrid.Get(entry).dirty = true
+	}
+}
+
+func (n *NavGridData) field(world donburi.World, size image.Point) *flowField {
+	if n.dirty {
+		clear(n.fields)
+		n.dirty = false
+	}
+	field, ok := n.fields[size]
+	if !ok {
+		field = newFlowField(world, size)
+		n.fields[size] = field
+	}
+	return field
+}
+
+// newFlowField runs a breadth first search out from the base over every top left position a creep of the size can stand at
+func newFlowField(world donburi.World, size image.Point) *flowField {
+	board := Board.Get(Board.MustFirst(world))
+	cellSize := config.GetBalance(world).Navigation.CellSize
+	if cellSize <= 0 {
+		cellSize = defaultNavCellSize
+	}
+	f := &flowField{
+		cellSize: cellSize,
+		cols:     max(board.Width-size.X, 0)/cellSize + 1,
+		rows:     board.Height/cellSize + 1,
+	}
+	f.dist = make([]int, f.cols*f.rows)
+
+	towers := make([]image.Rectangle, 0)
+	donburi.NewQuery(filter.Contains(Tower)).Each(world, func(entry *donburi.Entry) {
+		// a creep anywhere within half a cell of a grid point must clear the tower
+		towers = append(towers, GetRect(entry).Inset(-cellSize/2))
+	})
+	goalY := board.Height
+	if pe, ok := Player.First(world); ok {
+		goalY = GetRect(pe).Min.Y
+	}
+
+	passable := func(c, r int) bool {
+		rect := image.Rect(c*cellSize, r*cellSize, c*cellSize+size.X, r*cellSize+size.Y)
+		for _, tower := range towers {
+			if rect.Overlaps(tower) {
+				return false
+			}
+		}
+		return true
+	}
+
+	queue := make([]image.Point, 0)
+	for r := range f.rows {
+		for c := range f.cols {
+			i := r*f.cols + c
+			f.dist[i] = -1
+			if r*cellSize+size.Y >= goalY-cellSize && passable(c, r) {
+				f.dist[i] = 0
+				queue = append(queue, image.Pt(c, r))
+			}
+		}
+	}
+
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+		d := f.dist[cur.Y*f.cols+cur.X]
+		for _, step := range navSteps {
+			next := cur.Add(step)
+			if !f.inBounds(next) || f.dist[next.Y*f.cols+next.X] != -1 || !passable(next.X, next.Y) {
+				continue
+			}
+			// don't cut diagonally across the corner of a tower
+			if step.X != 0 && step.Y != 0 && (!passable(cur.X+step.X, cur.Y) || !passable(cur.X, cur.Y+step.Y)) {
+				continue
+			}
+			f.dist[next.Y*f.cols+next.X] = d + 1
+			queue = append(queue, next)
+		}
+	}
+	return f
+}
+
+func (f *flowField) inBounds(cell image.Point) bool {
+	return cell.X >= 0 && cell.X < f.cols && cell.Y >= 0 && cell.Y < f.rows
+}
+
+func (f *flowField) distance(cell image.Point) int {
+	if !f.inBounds(cell) {
+		return -1
+	}
+	return f.dist[cell.Y*f.cols+cell.X]
+}
+
+// nearestCell rounds a position to the closest grid cell
+func (f *flowField) nearestCell(pos image.Point) image.Point {
+	c := min(max((pos.X+f.cellSize/2)/f.cellSize, 0), f.cols-1)
+	r := min(max((pos.Y+f.cellSize/2)/f.cellSize, 0), f.rows-1)
+	return image.Pt(c, r)
+}
+
+// next returns the neighboring cell that is closest to the base, false when there is no way through
+func (f *flowField) next(cell image.Point) (image.Point, bool) {
+	current := f.distance(cell)
+	if current == 0 {
+		// already at the base, keep pushing into it
+		return cell.Add(image.Pt(0, 1)), true
+	}
+	best, bestDist := cell, -1
+	for _, step := range navSteps {
+		d := f.distance(cell.Add(step))
+		if d >= 0 && (bestDist < 0 || d < bestDist) {
+			best, bestDist = cell.Add(step), d
+		}
+	}
+	if bestDist < 0 || (current >= 0 && bestDist >= current) {
+		return cell, false
+	}
+	return best, true
+}
+
+// PathStep returns where an entity at pos should move toward next at speed to follow the shortest path around towers to the base,
+// false when towers have walled off the base
+func PathStep(world donburi.World, pos image.Point, size image.Point, speed int) (image.Point, bool) {
+	field := getNavGrid(world).field(world, size)
+	cell := field.nearestCell(pos)
+	next, ok := field.next(cell)
+	if !ok {
+		return pos, false
+	}
+
+	// move at full speed in the direction of travel and line up with the grid along the other axis
+	target := next.Mul(field.cellSize)
+	step := func(dir, cur, want int) int {
+		if dir != 0 {
+			return cur + dir*speed
+		}
+		return cur + min(max(want-cur, -speed), speed)
+	}
+	return image.Pt(step(next.X-cell.X, pos.X, target.X), step(next.Y-cell.Y, pos.Y, target.Y)), true
+}
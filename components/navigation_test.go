@@ -0,0 +1,97 @@
+package components
+
+import (
+	"image"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/yohamta/donburi"
+	"github.com/yohamta/donburi/filter"
+)
+
+func newNavTestWorld(t *testing.T) donburi.World {
+	t.Helper()
+
+	world := newAttackTestWorld(t)
+	if _, err := NewBoard(world, 200, 200); err != nil {
+		t.Fatal(err)
+	}
+	pe := Player.MustFirst(world)
+	pe.AddComponent(Position)
+	pe.AddComponent(SpriteRender)
+	Position.Set(pe, &PositionData{X: 0, Y: 180})
+	SpriteRender.Set(pe, &SpriteRenderData{image: ebiten.NewImage(200, 20)})
+	return world
+}
+
+func newNavTestTower(world donburi.World, x, y int) *donburi.Entry {
+	entry := world.Entry(world.Create(Tower, Position, SpriteRender))
+	Position.Set(entry, &PositionData{X: x, Y: y})
+	SpriteRender.Set(entry, &SpriteRenderData{image: ebiten.NewImage(40, 20)})
+	MarkNavGridDirty(world)
+	return entry
+}
+
+func TestPathStep_OpenLaneMovesStraightDown(t *testing.T) {
+	world := newNavTestWorld(t)
+
+	got, ok := PathStep(world, image.Pt(80, 40), image.Pt(20, 20), 5)
+	if !ok {
+		t.Fatal("PathStep() found no path on an empty board")
+	}
+	if got != image.Pt(80, 45) {
+		t.Errorf("PathStep() = %v, want straight down to (80,45)", got)
+	}
+}
+
+func TestPathStep_RoutesAroundTowers(t *testing.T) {
+	world := newNavTestWorld(t)
+	// a wall across the lane below the creep with a gap on the right
+	newNavTestTower(world, 0, 100)
+	newNavTestTower(world, 40, 100)
+	newNavTestTower(world, 80, 100)
+	newNavTestTower(world, 120, 100)
+
+	pos := image.Pt(80, 60)
+	size := image.Pt(20, 20)
+	for range 100 {
+		next, ok := PathStep(world, pos, size, 4)
+		if !ok {
+			t.Fatalf("PathStep() from %v found no path through the gap", pos)
+		}
+		rect := image.Rectangle{next, next.Add(size)}
+		donburi.NewQuery(filter.Contains(Tower)).Each(world, func(tower *donburi.Entry) {
+			if rect.Overlaps(GetRect(tower)) {
+				t.Fatalf("PathStep() moved into tower at %v", GetRect(tower))
+			}
+		})
+		pos = next
+		if pos.Y+size.Y >= 180 {
+			break
+		}
+	}
+	if pos.Y+size.Y < 180-defaultNavCellSize {
+		t.Errorf("creep stopped at %v, want it to reach the base", pos)
+	}
+	if pos.X < 160 {
+		t.Errorf("creep at %v, want it to have gone through the gap on the right", pos)
+	}
+}
+
+func TestPathStep_WalledOffBaseHasNoPathUntilTowerRemoved(t *testing.T) {
+	world := newNavTestWorld(t)
+	towers := make([]*donburi.Entry, 0)
+	for x := 0; x < 200; x += 40 {
+		towers = append(towers, newNavTestTower(world, x, 100))
+	}
+
+	if _, ok := PathStep(world, image.Pt(80, 40), image.Pt(20, 20), 5); ok {
+		t.Fatal("PathStep() found a path through a solid wall")
+	}
+
+	towers[2].Remove()
+	MarkNavGridDirty(world)
+	if _, ok := PathStep(world, image.Pt(80, 40), image.Pt(20, 20), 5); !ok {
+		t.Error("PathStep() found no path after a tower was removed")
+	}
+}
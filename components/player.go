@@ -16,7 +16,6 @@ import (
 	"github.com/hajimehoshi/ebiten/v2/vector"
 	"github.com/leap-fish/necs/esync/srvsync"
 	"github.com/yohamta/donburi"
-	"github.com/yohamta/donburi/filter"
 )
 
 type PlayerData struct {
@@ -24,6 +23,11 @@ type PlayerData struct {
 	Score       int
 	Dead        bool
 	TowerLevels int
+	TowerType   string
+	// movingTower is the tower picked up to move on the next click, Null when not moving one
+	movingTower donburi.Entity
+	// choosingTower is the tower showing its upgrade choice popup, Null when there isn't one
+	choosingTower donburi.Entity
 }
 type PlayerRenderData struct {
 }
@@ -32,8 +36,8 @@ var Player = donburi.NewComponentType[PlayerData]()
 var PlayerRender = donburi.NewComponentType[PlayerRenderData]()
 
 func NewPlayer(world donburi.World, startingTowerLevel int) error {
-	entity := world.Create(Player, Position, Health, Attack, SpriteRender, PlayerRender, InfoRender)
-	err := srvsync.NetworkSync(world, &entity, Player, Position, Health, Attack, SpriteRender, PlayerRender, InfoRender)
+	entity := world.Create(Player, Position, Health, Attack, Abilities, SpriteRender, PlayerRender, InfoRender)
+	err := srvsync.NetworkSync(world, &entity, Player, Position, Health, Attack, Abilities, SpriteRender, PlayerRender, InfoRender)
 	if err != nil {
 		return err
 	}
@@ -44,9 +48,10 @@ func NewPlayer(world donburi.World, startingTowerLevel int) error {
 
 	Position.Set(entry, &PositionData{X: 0, Y: board.Height - yBorderBottom})
 	balance := config.GetBalance(world)
-	Player.Set(entry, &PlayerData{Money: balance.Player.StartingMoney, TowerLevels: startingTowerLevel})
+	Player.Set(entry, &PlayerData{Money: balance.Player.StartingMoney, TowerLevels: startingTowerLevel, TowerType: balance.Tower.DefaultType})
 	Health.Set(entry, NewHealthData(balance.Player.Health))
-	Attack.Set(entry, &AttackData{Power: balance.Player.AttackPower, AttackType: RangedSingle, Range: balance.Player.AttackRange, cooldown: util.NewCooldownTimer(balance.Player.AttackCooldown), noLead: true})
+	Attack.Set(entry, &AttackData{Power: balance.Player.AttackPower, AttackType: RangedSingle, Range: balance.Player.AttackRange, cooldown: util.NewCooldownTimer(balance.Player.AttackCooldown), AntiAir: true, noLead: true})
+	Abilities.Set(entry, NewAbilitiesData(balance))
 	SpriteRender.Set(entry, &SpriteRenderData{Name: "base"})
 	PlayerRender.Set(entry, &PlayerRenderData{})
 	InfoRender.Set(entry, &InfoRenderData{})
@@ -56,6 +61,8 @@ func NewPlayer(world donburi.World, startingTowerLevel int) error {
 
 type Direction int
 
+var towerTypeKeys = []ebiten.Key{ebiten.KeyDigit1, ebiten.KeyDigit2, ebiten.KeyDigit3, ebiten.KeyDigit4, ebiten.KeyDigit5, ebiten.KeyDigit6, ebiten.KeyDigit7, ebiten.KeyDigit8, ebiten.KeyDigit9, ebiten.KeyDigit0}
+
 const (
 	Up Direction = iota
 	Down
@@ -63,44 +70,148 @@ const (
 	Right
 )
 
-func (p *PlayerData) UserSpeedUpdate(entry *donburi.Entry) error {
+// ReadPlayerInput turns this frame's keyboard and mouse input into player actions.
+// A click and the tower keys under the cursor are checked in priority order so only one of them happens per frame.
+func ReadPlayerInput() []PlayerAction {
+	actions := make([]PlayerAction, 0)
+	for i, key := range towerTypeKeys {
+		if inpututil.IsKeyJustPressed(key) {
+			actions = append(actions, PlayerAction{Kind: SelectTowerAction, Value: i})
+		}
+	}
+
+	x, y := ebiten.CursorPosition()
+	for i, key := range abilityKeys {
+		if inpututil.IsKeyJustPressed(key) {
+			actions = append(actions, PlayerAction{Kind: AbilityAction, X: x, Y: y, Value: i})
+		}
+	}
+
+	kind := PlayerActionKind("")
+	if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
+		kind = ClickAction
+	} else if inpututil.IsKeyJustPressed(ebiten.KeyH) {
+		kind = HealAction
+	} else if inpututil.IsKeyJustPressed(ebiten.KeyU) {
+		kind = UpgradeAction
+	} else if inpututil.IsKeyJustPressed(ebiten.KeyB) {
+		kind = ResupplyAction
+	} else if inpututil.IsKeyJustPressed(ebiten.KeyG) {
+		kind = TargetAction
+	} else if inpututil.IsKeyJustPressed(ebiten.KeyX) {
+		kind = SellAction
+	} else if inpututil.IsKeyJustPressed(ebiten.KeyM) {
+		kind = MoveAction
+	}
+	if kind != "" {
+		actions = append(actions, PlayerAction{Kind: kind, X: x, Y: y})
+	}
+	return actions
+}
+
+// ApplyAction does what the player asked for, live from input or played back from a replay
+func (p *PlayerData) ApplyAction(entry *donburi.Entry, action PlayerAction) error {
 	if p.Dead {
 		return nil
 	}
 	config := config.GetConfig(entry.World)
+	x, y := action.X, action.Y
 
-	if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
-
-		x, y := ebiten.CursorPosition()
-		_, err := p.TryPlaceTower(entry.World, x, y, config.Sound, config.Debug)
-		if err != nil {
+	switch action.Kind {
+	case SelectTowerAction:
+		p.SelectTowerType(entry.World, action.Value)
+	case AbilityAction:
+		if _, err := p.TryUseAbility(entry, AbilityKind(action.Value), x, y, config.Sound, config.Debug); err != nil {
 			return err
 		}
-	} else if inpututil.IsKeyJustPressed(ebiten.KeyH) {
+	case ClickAction:
+		abilities := Abilities.Get(entry)
+		board := Board.Get(Board.MustFirst(entry.World))
+		if kind, ok := abilityButtonAt(board, x, y); ok {
+			if kind != Airstrike {
+				if _, err := p.TryUseAbility(entry, kind, x, y, config.Sound, config.Debug); err != nil {
+					return err
+				}
+			} else if abilities.aiming || p.CanUseAbility(entry, kind) {
+				// the strike needs a target, so the button waits for the next click on the board
+				abilities.aiming = !abilities.aiming
+			} else if config.Sound {
+				assets.PlaySound("invalid2")
+			}
+		} else if towerEntry := p.GetChoosingTower(entry.World); towerEntry != nil {
+			// take the option clicked on, clicking anywhere else closes the popup until the tower is upgraded again
+			if option := branchOptionAt(towerEntry, x, y); option >= 0 {
+				Tower.Get(towerEntry).ChooseBranch(towerEntry, option, config.Debug)
+			}
+			p.choosingTower = donburi.Null
+		} else if abilities.aiming {
+			abilities.aiming = false
+			if _, err := p.TryUseAbility(entry, Airstrike, x, y, config.Sound, config.Debug); err != nil {
+				return err
+			}
+		} else if towerEntry := p.GetMovingTower(entry.World); towerEntry != nil {
+			// drop the tower we picked up, if it can't go here keep holding it
+			if p.TryMoveTower(towerEntry, x, y, config.Sound, config.Debug) {
+				p.movingTower = donburi.Null
+			}
+		} else {
+			_, err := p.TryPlaceTower(entry.World, x, y, p.TowerType, config.Sound, config.Debug)
+			if err != nil {
+				return err
+			}
+		}
+	case HealAction:
 		// find tower below the click and heal it if we have enough money
-		x, y := ebiten.CursorPosition()
 		towerEntry := findTower(entry.World, x, y)
 		if towerEntry != nil {
 			_ = p.TryHealTower(towerEntry, config.Sound, config.Debug)
-
 		}
-	} else if inpututil.IsKeyJustPressed(ebiten.KeyU) {
-		// find tower below the click and upgrade it if we have enough money
-		x, y := ebiten.CursorPosition()
+	case UpgradeAction:
+		// find tower below the click and upgrade it if we have enough money, or reopen its upgrade choice
+		towerEntry := findTower(entry.World, x, y)
+		if towerEntry != nil {
+			if Tower.Get(towerEntry).ChoosingBranch {
+				p.choosingTower = towerEntry.Entity()
+			} else {
+				_ = p.TryUpgradeTower(towerEntry, config.Sound, config.Debug)
+			}
+		}
+	case ResupplyAction:
+		// refill the magazine of the tower below the cursor when ammo is separate from health
+		towerEntry := findTower(entry.World, x, y)
+		if towerEntry != nil {
+			_ = p.TryResupplyTower(towerEntry, config.Sound, config.Debug)
+		}
+	case TargetAction:
+		// cycle the targeting priority of the tower below the cursor
 		towerEntry := findTower(entry.World, x, y)
 		if towerEntry != nil {
-			_ = p.TryUpgradeTower(towerEntry, config.Sound, config.Debug)
+			p.CycleTowerTargeting(towerEntry, config.Debug)
 		}
+	case SellAction:
+		// sell the tower below the cursor
+		towerEntry := findTower(entry.World, x, y)
+		if towerEntry != nil {
+			p.SellTower(towerEntry, config.Debug)
+		}
+	case MoveAction:
+		// pick up the tower below the cursor to move it, or put it back down if we are already holding one
+		if p.GetMovingTower(entry.World) != nil {
+			p.movingTower = donburi.Null
+		} else if towerEntry := findTower(entry.World, x, y); towerEntry != nil {
+			p.movingTower = towerEntry.Entity()
+		}
+	default:
+		return fmt.Errorf("unknown player action %q", action.Kind)
 	}
-
 	return nil
 }
 
 func (p *PlayerData) TryHealTower(entry *donburi.Entry, sound, debug bool) bool {
 	healed := false
-	cost := getTowerHealCost(entry.World, config.GetBalance(entry.World).Tower.DefaultType)
+	tower := Tower.Get(entry)
+	cost := getTowerHealCost(entry.World, tower.Type)
 	if p.Money >= cost {
-		tower := Tower.Get(entry)
 		if tower.Heal(entry, debug) {
 			p.Money -= cost
 			GetGameStats().UpdateStat("MoneySpent", cost)
@@ -119,14 +230,18 @@ func (p *PlayerData) TryHealTower(entry *donburi.Entry, sound, debug bool) bool
 
 func (p *PlayerData) TryUpgradeTower(entry *donburi.Entry, sound, debug bool) bool {
 	upgraded := false
-	cost := getTowerUpgradeCost(entry.World, config.GetBalance(entry.World).Tower.DefaultType)
+	tower := Tower.Get(entry)
+	cost := getTowerUpgradeCost(entry.World, tower.Type)
 	if p.Money >= cost {
-		tower := Tower.Get(entry)
 		if tower.Upgrade(entry, debug) {
 			p.Money -= cost
+			tower.Spent += cost
 			GetGameStats().UpdateStat("MoneySpent", cost)
 			p.TowerLevels++
 			upgraded = true
+			if tower.ChoosingBranch {
+				p.choosingTower = entry.Entity()
+			}
 		}
 	} else {
 		if debug {
@@ -139,11 +254,88 @@ func (p *PlayerData) TryUpgradeTower(entry *donburi.Entry, sound, debug bool) bo
 	return upgraded
 }
 
-func (p *PlayerData) TryPlaceTower(world donburi.World, x, y int, sound, debug bool) (bool, error) {
+// SellTower removes the tower and refunds the balance percentage of what was spent placing and upgrading it, returns the refund
+func (p *PlayerData) SellTower(entry *donburi.Entry, debug bool) int {
+	tower := Tower.Get(entry)
+	refund := tower.Spent * config.GetBalance(entry.World).Tower.SellRefundPercent / 100
+	if debug {
+		fmt.Printf("sold %v tower for %v of %v spent\n", tower.Type, refund, tower.Spent)
+	}
+	p.AddMoney(refund)
+	if p.movingTower == entry.Entity() {
+		p.movingTower = donburi.Null
+	}
+	MarkNavGridDirty(entry.World)
+	MarkAurasDirty(entry.World)
+	entry.Remove()
+	GetGameStats().IncrementStat("TowersSold")
+	return refund
+}
+
+// GetMovingTower returns the tower picked up to be moved, or nil if there isn't one or it was destroyed while held
+func (p *PlayerData) GetMovingTower(world donburi.World) *donburi.Entry {
+	if p.movingTower == donburi.Null || !world.Valid(p.movingTower) {
+		return nil
+	}
+	return world.Entry(p.movingTower)
+}
+
+// TryMoveTower moves the tower so it is centered on x, y for the balance move cost, the new spot is validated the same as placing a tower
+func (p *PlayerData) TryMoveTower(entry *donburi.Entry, x, y int, sound, debug bool) bool {
+	cost := config.GetBalance(entry.World).Tower.MoveCost
+	if p.Money < cost {
+		if debug {
+			fmt.Printf("Not enough money to move tower cost %v, remaining %v\n", cost, p.Money)
+		}
+		if sound {
+			assets.PlaySound("invalid2")
+		}
+		return false
+	}
+	bounds := SpriteRender.Get(entry).GetImage(entry).Bounds()
+	rect, err := validateTowerPlacement(entry.World, x, y, bounds, entry.Entity(), sound)
+	if err != nil {
+		if debug {
+			fmt.Println(err.Error())
+		}
+		return false
+	}
+	pos := Position.Get(entry)
+	pos.X, pos.Y = rect.Min.X, rect.Min.Y
+	MarkNavGridDirty(entry.World)
+	MarkAurasDirty(entry.World)
+
+	p.Money -= cost
+	GetGameStats().UpdateStat("MoneySpent", cost)
+	GetGameStats().IncrementStat("TowersMoved")
+	return true
+}
+
+// CycleTowerTargeting switches the tower to the next targeting priority, changing targets is free
+func (p *PlayerData) CycleTowerTargeting(entry *donburi.Entry, debug bool) TargetPriority {
+	a := Attack.Get(entry)
+	a.Targeting = a.Targeting.Next()
+	if debug {
+		fmt.Printf("tower targeting changed to %v\n", a.Targeting)
+	}
+	return a.Targeting
+}
+
+// SelectTowerType makes the tower type at index in the balance order the one placed by the player
+func (p *PlayerData) SelectTowerType(world donburi.World, index int) bool {
+	names := config.GetBalance(world).Tower.TypeNames()
+	if index < 0 || index >= len(names) {
+		return false
+	}
+	p.TowerType = names[index]
+	return true
+}
+
+func (p *PlayerData) TryPlaceTower(world donburi.World, x, y int, towerType string, sound, debug bool) (bool, error) {
 	placed := false
-	cost := getTowerCost(world, config.GetBalance(world).Tower.DefaultType)
+	cost := getTowerCost(world, towerType)
 	if p.Money >= cost {
-		err := p.PlaceTower(world, x, y, sound)
+		towerEntry, err := p.PlaceTower(world, x, y, towerType, sound)
 
 		if err != nil {
 			switch err.(type) {
@@ -156,6 +348,7 @@ func (p *PlayerData) TryPlaceTower(world donburi.World, x, y int, sound, debug b
 			}
 		} else {
 			p.Money -= cost
+			Tower.Get(towerEntry).Spent += cost
 			GetGameStats().UpdateStat("MoneySpent", cost)
 			placed = true
 		}
@@ -172,7 +365,10 @@ func (p *PlayerData) TryPlaceTower(world donburi.World, x, y int, sound, debug b
 
 func (p *PlayerData) GameSpeedUpdate(entry *donburi.Entry) error {
 	a := Attack.Get(entry)
-	a.AttackEnemyRange(entry, nil, Creep)
+	if err := a.AttackEnemyRange(entry, OnKillCreep, nil, Creep); err != nil {
+		return err
+	}
+	Abilities.Get(entry).Update()
 	return nil
 }
 
@@ -184,9 +380,18 @@ func (e *PlacementError) Error() string {
 	return e.message
 }
 
-func (p *PlayerData) PlaceTower(world donburi.World, x, y int, sound bool) error {
-	img := assets.GetImage("tower")
-	bounds := img.Bounds()
+func (p *PlayerData) PlaceTower(world donburi.World, x, y int, towerType string, sound bool) (*donburi.Entry, error) {
+	img := assets.GetImage(config.GetBalance(world).Tower.GetType(towerType).Sprite)
+	rect, err := validateTowerPlacement(world, x, y, img.Bounds(), donburi.Null, sound)
+	if err != nil {
+		return nil, err
+	}
+	GetGameStats().IncrementStat("TowersBuilt")
+	return NewTower(world, rect.Min.X, rect.Min.Y, towerType)
+}
+
+// validateTowerPlacement centers the bounds on x, y and checks the tower fits on the board without colliding with anything other than ignore
+func validateTowerPlacement(world donburi.World, x, y int, bounds image.Rectangle, ignore donburi.Entity, sound bool) (image.Rectangle, error) {
 	rect := bounds.Add(image.Pt(x-bounds.Dx()/2, y-bounds.Dy()/2))
 	boardEntry := Board.MustFirst(world)
 	board := Board.Get(boardEntry)
@@ -195,19 +400,19 @@ func (p *PlayerData) PlaceTower(world donburi.World, x, y int, sound bool) error
 			assets.PlaySound("invalid1")
 		}
 		message := fmt.Sprintf("Invalid tower location %v, %v, image out of bounds", x, y)
-		return &PlacementError{message}
+		return rect, &PlacementError{message}
 	} else {
-		collision := DetectCollisionsWorld(world, rect, filter.Contains(Player))
+		// flying creeps are overhead so they don't get in the way
+		collision := DetectCollisionsEntry(world, ignore, rect, util.CreateOrFilter(Player, Flying))
 		if collision != nil {
 			if sound {
 				assets.PlaySound("invalid2")
 			}
 			message := fmt.Sprintf("Invalid tower location %v, %v, collision with entity", x, y)
-			return &PlacementError{message}
+			return rect, &PlacementError{message}
 		}
 	}
-	GetGameStats().IncrementStat("TowersBuilt")
-	return NewTower(world, rect.Min.X, rect.Min.Y)
+	return rect, nil
 }
 
 func (p *PlayerData) IsDead() bool {
@@ -242,7 +447,25 @@ func (pr *PlayerRenderData) Draw(screen *ebiten.Image, entry *donburi.Entry, deb
 	nextY := DrawTextLines(screen, assets.ScoreFace, str, float64(board.Width), TextBorder, text.AlignStart, text.AlignStart)
 
 	str = fmt.Sprintf("Max Tower Level %d", player.GetMaxTowerLevel(config.GetBalance(entry.World)))
-	_ = DrawTextLines(screen, assets.InfoFace, str, float64(board.Width), nextY, text.AlignStart, text.AlignStart)
+	towerY := DrawTextLines(screen, assets.InfoFace, str, float64(board.Width), nextY, text.AlignStart, text.AlignStart)
+
+	damageType, _ := ParseDamageType(config.GetBalance(entry.World).Tower.GetType(player.TowerType).DamageType)
+	str = fmt.Sprintf("Tower %s $%d %s", player.TowerType, getTowerCost(entry.World, player.TowerType), damageType)
+	if towerEntry := player.GetMovingTower(entry.World); towerEntry != nil {
+		str = fmt.Sprintf("Moving %s $%d", Tower.Get(towerEntry).Type, config.GetBalance(entry.World).Tower.MoveCost)
+		// outline where the tower will be dropped
+		rect := GetRect(towerEntry)
+		x, y := ebiten.CursorPosition()
+		vector.StrokeRect(screen, float32(x-rect.Dx()/2), float32(y-rect.Dy()/2), float32(rect.Dx()), float32(rect.Dy()), 1, color.White, true)
+	}
+	_ = DrawTextLines(screen, assets.InfoFace, str, float64(board.Width), towerY, text.AlignStart, text.AlignStart)
+
+	if entry.HasComponent(Abilities) {
+		Abilities.Get(entry).Draw(screen, entry)
+	}
+	if towerEntry := player.GetChoosingTower(entry.World); towerEntry != nil {
+		drawBranchPopup(screen, towerEntry)
+	}
 
 	str = fmt.Sprintf("SCORE %05d", player.Score)
 	_ = DrawTextLines(screen, assets.ScoreFace, str, float64(board.Width), TextBorder, text.AlignCenter, text.AlignStart)
@@ -0,0 +1,211 @@
+package components
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"slices"
+
+	"tower-defense/assets"
+	"tower-defense/config"
+	"tower-defense/util"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"github.com/leap-fish/necs/esync/srvsync"
+	"github.com/yohamta/donburi"
+	"github.com/yohamta/donburi/component"
+)
+
+// ProjectileKind is how a ranged attack reaches its target
+type ProjectileKind int
+
+const (
+	// PlainBullet shots fly straight at where the target is headed and stop at the first enemy they hit
+	PlainBullet ProjectileKind = iota
+	// HomingMissile shots turn towards their target every tick, flying on straight if it dies first
+	HomingMissile
+	// PiercingRound shots fly straight through up to Pierce enemies
+	PiercingRound
+	// InstantBeam attacks hit their target instantly
+	InstantBeam
+)
+
+var projectileKindNames = []string{"Bullet", "Homing", "Piercing", "Beam"}
+
+var homingBulletColor = color.RGBA{255, 80, 200, 255}
+var piercingBulletColor = color.RGBA{120, 255, 255, 255}
+var beamColor = color.RGBA{180, 255, 60, 255}
+
+// beamTicks is how long a beam stays drawn after it hits
+const beamTicks = 6
+
+// ParseProjectileKind converts a balance projectile name, empty defaults to a plain bullet
+func ParseProjectileKind(name string) (ProjectileKind, error) {
+	if name == "" {
+		return PlainBullet, nil
+	}
+	index := slices.Index(projectileKindNames, name)
+	if index < 0 {
+		return PlainBullet, fmt.Errorf("unknown projectile %q", name)
+	}
+	return ProjectileKind(index), nil
+}
+
+func (pk ProjectileKind) String() string {
+	if pk < 0 || int(pk) >= len(projectileKindNames) {
+		return ""
+	}
+	return projectileKindNames[pk]
+}
+
+// BeamData is the line drawn for a beam attack from its Position to the end, removed after a few ticks
+type BeamData struct {
+	EndX, EndY int
+	ticks      int
+}
+
+var Beam = donburi.NewComponentType[BeamData]()
+
+// FireBeam hits the enemy instantly, area beams blasting everything around it, and leaves the beam drawn for a few ticks
+func (a *AttackData) FireBeam(entry *donburi.Entry, enemy *donburi.Entry, afterKill func(*donburi.Entry, *donburi.Entry), enemyType ...component.IComponentType) error {
+	start := util.MidpointRect(GetRect(entry))
+	enemyRect := GetRect(enemy)
+	end := util.MidpointRect(enemyRect)
+	GetGameStats().IncrementStat("BeamsFired")
+	var err error
+	if a.AttackType.IsArea() {
+		_, err = a.AttackArea(entry, enemyRect, a.AreaRadius, afterKill, enemyType...)
+	} else {
+		_, err = a.DamageEnemy(entry, enemy, a.Power, afterKill)
+	}
+	if err != nil {
+		return err
+	}
+	if a.Hazard.OnHit {
+		if _, err := NewHazard(entry.World, end, a.Hazard); err != nil {
+			return err
+		}
+	}
+	if _, err := NewBeam(entry.World, start, end); err != nil {
+		return err
+	}
+	if config.GetConfig(entry.World).Sound {
+		assets.PlaySound("shoot3")
+	}
+	return nil
+}
+
+func NewBeam(world donburi.World, start, end image.Point) (*donburi.Entry, error) {
+	beamEntity := world.Create(Beam, Position)
+	err := srvsync.NetworkSync(world, &beamEntity, Beam, Position)
+	if err != nil {
+		return nil, err
+	}
+	beam := world.Entry(beamEntity)
+	Position.Set(beam, &PositionData{start.X, start.Y})
+	Beam.Set(beam, &BeamData{EndX: end.X, EndY: end.Y, ticks: beamTicks})
+	return beam, nil
+}
+
+func (b *BeamData) Update(entry *donburi.Entry) error {
+	b.ticks--
+	if b.ticks <= 0 {
+		entry.Remove()
+	}
+	return nil
+}
+
+func (b *BeamData) Draw(screen *ebiten.Image, entry *donburi.Entry) {
+	pos := Position.Get(entry)
+	vector.StrokeLine(screen, float32(pos.X), float32(pos.Y), float32(b.EndX), float32(b.EndY), 2, beamColor, true)
+}
+
+// homingStep turns a homing missile towards its target, returning the move for this tick.
+// A missile whose target is gone keeps flying the way it was headed.
+func (bd *BulletData) homingStep(entry *donburi.Entry, pos *PositionData) image.Point {
+	if entry.World.Valid(bd.target) {
+		aim := util.MidpointRect(GetRect(entry.World.Entry(bd.target)))
+		if dist := util.DistancePoints(image.Pt(pos.X, pos.Y), aim); dist > 0 {
+			ratio := dist / float64(bd.speed)
+			bd.heading = image.Pt(int(float64(aim.X-pos.X)/ratio), int(float64(aim.Y-pos.Y)/ratio))
+		}
+	}
+	bd.flown += float64(bd.speed)
+	return bd.heading
+}
+
+// pierceEnemies hits every enemy the round is passing through that it hasn't hit yet, removing it once it has hit its limit.
+// Area rounds blast once a tick however many enemies they reach, and every enemy a blast damages counts as hit.
+func (bd *BulletData) pierceEnemies(entry *donburi.Entry, afterKill func(*donburi.Entry, *donburi.Entry), enemyType ...component.IComponentType) error {
+	a := Attack.Get(entry)
+	rect := a.GetExpandedRect(entry)
+	// collect the enemies first since kills remove entries from the world
+	targets := make([]*donburi.Entry, 0)
+	donburi.NewQuery(util.CreateOrFilter(enemyType...)).Each(entry.World, func(enemyEntry *donburi.Entry) {
+		if rect.Overlaps(GetRect(enemyEntry)) && a.CanTarget(enemyEntry) && !slices.Contains(bd.hit, enemyEntry.Entity()) {
+			targets = append(targets, enemyEntry)
+		}
+	})
+	if len(targets) == 0 {
+		return nil
+	}
+	if a.AttackType.IsArea() {
+		return bd.pierceBlast(entry, a, targets, afterKill, enemyType...)
+	}
+
+	for _, enemy := range targets {
+		if !enemy.Valid() {
+			continue
+		}
+		bd.hit = append(bd.hit, enemy.Entity())
+		if _, err := a.DamageEnemy(entry, enemy, a.Power, afterKill); err != nil {
+			return err
+		}
+		if err := bd.afterPierce(entry, a); err != nil {
+			return err
+		}
+		if len(bd.hit) >= bd.pierce {
+			entry.Remove()
+			return nil
+		}
+	}
+	return nil
+}
+
+// pierceBlast blows up once around the round, sparing the enemies it has already hit
+func (bd *BulletData) pierceBlast(entry *donburi.Entry, a *AttackData, targets []*donburi.Entry, afterKill func(*donburi.Entry, *donburi.Entry), enemyType ...component.IComponentType) error {
+	damaged, err := a.attackAreaExcept(entry, GetRect(entry), a.AreaRadius, bd.hit, afterKill, enemyType...)
+	bd.hit = append(bd.hit, damaged...)
+	if err != nil {
+		return err
+	}
+	// the enemies the round reached count as hit even when the blast is too small to damage them
+	for _, enemy := range targets {
+		if !slices.Contains(bd.hit, enemy.Entity()) {
+			bd.hit = append(bd.hit, enemy.Entity())
+		}
+	}
+	if err := bd.afterPierce(entry, a); err != nil {
+		return err
+	}
+	if len(bd.hit) >= bd.pierce {
+		entry.Remove()
+	}
+	return nil
+}
+
+// afterPierce counts a piercing hit and drops the round's hazard where it hit
+func (bd *BulletData) afterPierce(entry *donburi.Entry, a *AttackData) error {
+	GetGameStats().IncrementStat("BulletHits")
+	if len(bd.hit) > 1 {
+		GetGameStats().IncrementStat("PiercingHits")
+	}
+	if a.Hazard.OnHit {
+		pos := Position.Get(entry)
+		if _, err := NewHazard(entry.World, image.Pt(pos.X, pos.Y), a.Hazard); err != nil {
+			return err
+		}
+	}
+	return nil
+}
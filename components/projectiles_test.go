@@ -0,0 +1,164 @@
+package components
+
+import (
+	"testing"
+
+	"github.com/yohamta/donburi"
+	"github.com/yohamta/donburi/filter"
+)
+
+// flyBullet updates the bullet until it hits its limit or runs out, failing if it is still flying after ticks
+func flyBullet(t *testing.T, bullet *donburi.Entry, ticks int) {
+	t.Helper()
+	for range ticks {
+		if !bullet.Valid() {
+			return
+		}
+		if err := Bullet.Get(bullet).Update(bullet); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if bullet.Valid() {
+		t.Fatalf("bullet still flying after %v ticks", ticks)
+	}
+}
+
+func TestParseProjectileKind(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    ProjectileKind
+		wantErr bool
+	}{
+		{"", PlainBullet, false},
+		{"Bullet", PlainBullet, false},
+		{"Homing", HomingMissile, false},
+		{"Piercing", PiercingRound, false},
+		{"Beam", InstantBeam, false},
+		{"Laser", PlainBullet, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseProjectileKind(tt.name)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseProjectileKind(%q) = %v, %v, want %v, error %v", tt.name, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestBulletData_HomingMissileFollowsTarget(t *testing.T) {
+	world := newWaveTestWorld(t)
+	tower := newTestTower(t, world, 0, 100, "Flak")
+	creep := newAttackTestCreep(world, 50, 100, 20)
+
+	if err := Attack.Get(tower).LaunchBullet(tower, creep); err != nil {
+		t.Fatal(err)
+	}
+	bullet := Bullet.MustFirst(world)
+	// the creep dodges well away from where the missile was fired
+	Position.Get(creep).Y = 160
+	flyBullet(t, bullet, 20)
+
+	if got := Health.Get(creep).Health; got != 19 {
+		t.Errorf("creep health = %v, want 19", got)
+	}
+	stats := GetGameStats()
+	if stats.GetStat("BulletHits") != 1 || stats.GetStat("BulletsExpired") != 0 {
+		t.Errorf("BulletHits = %v, BulletsExpired = %v, want 1, 0", stats.GetStat("BulletHits"), stats.GetStat("BulletsExpired"))
+	}
+}
+
+func TestBulletData_PiercingRoundHitsUpToPierce(t *testing.T) {
+	world := newWaveTestWorld(t)
+	// the default Sniper round does 3 damage and passes through 3 creeps
+	tower := newTestTower(t, world, 0, 0, "Sniper")
+	creeps := make([]*donburi.Entry, 0, 4)
+	for _, x := range []int{20, 32, 44, 56} {
+		creeps = append(creeps, newAttackTestCreep(world, x, 0, 20))
+	}
+
+	if err := Attack.Get(tower).LaunchBullet(tower, creeps[3]); err != nil {
+		t.Fatal(err)
+	}
+	flyBullet(t, Bullet.MustFirst(world), 20)
+	for i, want := range []int{17, 17, 17, 20} {
+		if got := Health.Get(creeps[i]).Health; got != want {
+			t.Errorf("creep %v health = %v, want %v", i, got, want)
+		}
+	}
+
+	// a round that runs out after hitting something didn't miss, so it doesn't count as expired or leave a mine
+	for _, creep := range creeps[:3] {
+		creep.Remove()
+	}
+	if err := Attack.Get(tower).LaunchBullet(tower, creeps[3]); err != nil {
+		t.Fatal(err)
+	}
+	flyBullet(t, Bullet.MustFirst(world), 20)
+	if got := Health.Get(creeps[3]).Health; got != 17 {
+		t.Errorf("last creep health = %v, want 17", got)
+	}
+	stats := GetGameStats()
+	if stats.GetStat("BulletHits") != 4 || stats.GetStat("PiercingHits") != 2 || stats.GetStat("BulletsExpired") != 0 {
+		t.Errorf("BulletHits = %v, PiercingHits = %v, BulletsExpired = %v, want 4, 2, 0", stats.GetStat("BulletHits"), stats.GetStat("PiercingHits"), stats.GetStat("BulletsExpired"))
+	}
+	if got := donburi.NewQuery(filter.Contains(Hazard)).Count(world); got != 0 {
+		t.Errorf("hazards = %v, want no mine", got)
+	}
+}
+
+func TestBulletData_PiercingAreaRoundBlastsOnceATick(t *testing.T) {
+	world := newWaveTestWorld(t)
+	tower := newTestTower(t, world, 0, 0, "Sniper")
+	// a Sniper that took the Splash branch
+	attack := Attack.Get(tower)
+	attack.AttackType = RangedArea
+	attack.AreaRadius = 10
+	attack.AreaFalloff = 0
+	creeps := make([]*donburi.Entry, 0, 3)
+	for _, x := range []int{20, 22, 56} {
+		creeps = append(creeps, newAttackTestCreep(world, x, 0, 20))
+	}
+
+	if err := attack.LaunchBullet(tower, creeps[2]); err != nil {
+		t.Fatal(err)
+	}
+	flyBullet(t, Bullet.MustFirst(world), 20)
+	// reaching two creeps at once is one blast, and the blast at the last creep spares the ones already hit
+	for i, creep := range creeps {
+		if got := Health.Get(creep).Health; got != 17 {
+			t.Errorf("creep %v health = %v, want 17 from a single blast", i, got)
+		}
+	}
+	if got := GetGameStats().GetStat("BulletHits"); got != 2 {
+		t.Errorf("BulletHits = %v, want 2 blasts", got)
+	}
+}
+
+func TestAttackData_BeamHitsInstantly(t *testing.T) {
+	world := newWaveTestWorld(t)
+	tower := newTestTower(t, world, 0, 0, "Venom")
+	creep := newAttackTestCreep(world, 30, 0, 20)
+
+	if err := Attack.Get(tower).AttackEnemyRange(tower, OnKillCreep, AfterTowerAttack, Creep); err != nil {
+		t.Fatal(err)
+	}
+	if got := Health.Get(creep).Health; got != 19 {
+		t.Errorf("creep health = %v, want 19", got)
+	}
+	stats := GetGameStats()
+	if stats.GetStat("BeamsFired") != 1 || stats.GetStat("TowerBulletsFired") != 0 {
+		t.Errorf("BeamsFired = %v, TowerBulletsFired = %v, want 1, 0", stats.GetStat("BeamsFired"), stats.GetStat("TowerBulletsFired"))
+	}
+	if donburi.NewQuery(filter.Contains(Bullet)).Count(world) != 0 {
+		t.Error("beam fired a bullet")
+	}
+
+	beam := Beam.MustFirst(world)
+	for range beamTicks {
+		if err := Beam.Get(beam).Update(beam); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if beam.Valid() {
+		t.Error("beam still drawn after its ticks")
+	}
+}
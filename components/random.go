@@ -0,0 +1,58 @@
+package components
+
+import (
+	"math/rand/v2"
+
+	"github.com/yohamta/donburi"
+)
+
+// RandomData is the battle's random number generator, there is one per world and it is only used on the server.
+// Every gameplay roll goes through it so the same seed and the same inputs play the same game.
+type RandomData struct {
+	Seed uint64
+	rng  *rand.Rand
+	// pcg is the generator's source, kept so its state can be saved with the battle
+	pcg *rand.PCG
+}
+
+var Random = donburi.NewComponentType[RandomData]()
+
+// NewSeed generates a seed for a battle that wasn't given one
+func NewSeed() uint64 {
+	// zero means no seed was given so never hand it out
+	return max(rand.Uint64(), 1)
+}
+
+// SeedRandom restarts the world's random number generator from the seed
+func SeedRandom(world donburi.World, seed uint64) *RandomData {
+	entry, ok := Random.First(world)
+	if !ok {
+		entry = world.Entry(world.Create(Random))
+	}
+	pcg := rand.NewPCG(seed, seed)
+	Random.Set(entry, &RandomData{Seed: seed, rng: rand.New(pcg), pcg: pcg})
+	return Random.Get(entry)
+}
+
+// GetRandom returns the world's random number generator, seeding it with a new seed if the battle hasn't
+func GetRandom(world donburi.World) *rand.Rand {
+	return getRandomData(world).rng
+}
+
+func getRandomData(world donburi.World) *RandomData {
+	entry, ok := Random.First(world)
+	if !ok {
+		return SeedRandom(world, NewSeed())
+	}
+	return Random.Get(entry)
+}
+
+// SaveRandom returns the state of the world's random number generator, to carry on from it with RestoreRandom
+func SaveRandom(world donburi.World) ([]byte, error) {
+	return getRandomData(world).pcg.MarshalBinary()
+}
+
+// RestoreRandom puts the world's random number generator back to a saved state, it rolls on as it would have from there
+func RestoreRandom(world donburi.World, seed uint64, state []byte) error {
+	return SeedRandom(world, seed).pcg.UnmarshalBinary(state)
+}
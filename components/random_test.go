@@ -0,0 +1,57 @@
+package components
+
+import (
+	"testing"
+
+	"github.com/yohamta/donburi"
+	"github.com/yohamta/donburi/filter"
+)
+
+func TestSeedRandomRepeatsRolls(t *testing.T) {
+	world := donburi.NewWorld()
+	SeedRandom(world, 42)
+	first := []int{GetRandom(world).IntN(1000), GetRandom(world).IntN(1000), GetRandom(world).IntN(1000)}
+
+	SeedRandom(world, 42)
+	for i, want := range first {
+		if got := GetRandom(world).IntN(1000); got != want {
+			t.Errorf("roll %v after reseeding = %v, want %v", i, got, want)
+		}
+	}
+	if count := donburi.NewQuery(filter.Contains(Random)).Count(world); count != 1 {
+		t.Errorf("%v random number generators in the world, want reseeding to reuse one", count)
+	}
+}
+
+func TestGetRandomSeedsUnseededWorld(t *testing.T) {
+	world := donburi.NewWorld()
+	if GetRandom(world) == nil {
+		t.Fatal("GetRandom() = nil, want a generator for an unseeded world")
+	}
+	entry, ok := Random.First(world)
+	if !ok {
+		t.Fatal("GetRandom() didn't store the generator in the world")
+	}
+	if Random.Get(entry).Seed == 0 {
+		t.Errorf("Seed = 0, want a generated seed")
+	}
+}
+
+func TestRestoreRandomCarriesOnRolling(t *testing.T) {
+	world := donburi.NewWorld()
+	SeedRandom(world, 42)
+	GetRandom(world).IntN(1000)
+	state, err := SaveRandom(world)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := GetRandom(world).IntN(1000)
+
+	restored := donburi.NewWorld()
+	if err := RestoreRandom(restored, 42, state); err != nil {
+		t.Fatal(err)
+	}
+	if got := GetRandom(restored).IntN(1000); got != want {
+		t.Errorf("roll after restoring = %v, want %v", got, want)
+	}
+}
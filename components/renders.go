@@ -33,6 +33,9 @@ func (t *InfoRenderData) Draw(screen *ebiten.Image, entry *donburi.Entry) {
 
 		// draw health info centered below the entity
 		str := fmt.Sprintf("HP %d", health.Health)
+		if health.Shield > 0 {
+			str = fmt.Sprintf("HP %d+%d", health.Health, health.Shield)
+		}
 		op := &text.DrawOptions{}
 		textWidth, textHeight = text.Measure(str, assets.InfoFace, op.LineSpacing)
 
@@ -78,6 +81,76 @@ func (t *InfoRenderData) Draw(screen *ebiten.Image, entry *donburi.Entry) {
 		}
 	}
 
+	if entry.HasComponent(Veterancy) {
+		Veterancy.Get(entry).drawChevrons(screen, entry)
+	}
+	if entry.HasComponent(Ammo) {
+		Ammo.Get(entry).drawAmmoBar(screen, entry)
+	}
+
+	if entry.HasComponent(Tower) && entry.HasComponent(Attack) {
+		// label the tower's targeting priority along the top, only when it was changed from closest unless debugging
+		attack := Attack.Get(entry)
+		if attack.Power > 0 && (attack.Targeting != Closest || config.GetConfig(entry.World).Debug) {
+			str := attack.Targeting.Label()
+			op := &text.DrawOptions{}
+			labelWidth, labelHeight := text.Measure(str, assets.InfoFace, op.LineSpacing)
+			op.GeoM.Translate(float64(rect.Min.X)+(float64(rect.Dx())-labelWidth)/2, float64(rect.Min.Y)-labelHeight)
+			text.Draw(screen, str, assets.InfoFace, op)
+		}
+		if attack.Power > 0 {
+			// label the damage type below the health, with how much got through to the creep it last attacked
+			str := attack.DamageType.Label()
+			var clr color.Color = color.White
+			if effectiveness := attack.LastEffectiveness; effectiveness > 0 {
+				str = fmt.Sprintf("%s %d%%", str, effectiveness)
+				if effectiveness > 100 {
+					clr = strongDamageColor
+				} else if effectiveness < 100 {
+					clr = weakDamageColor
+				}
+			}
+			op := &text.DrawOptions{}
+			op.ColorScale.ScaleWithColor(clr)
+			labelWidth, labelHeight := text.Measure(str, assets.InfoFace, op.LineSpacing)
+			op.GeoM.Translate(float64(rect.Min.X)+(float64(rect.Dx())-labelWidth)/2, float64(rect.Max.Y)+textHeight+4)
+			text.Draw(screen, str, assets.InfoFace, op)
+			textHeight += labelHeight
+		}
+	}
+
+	if entry.HasComponent(Tower) {
+		// label the upgrade paths taken below the damage type, and flag a tower waiting on a choice
+		tower := Tower.Get(entry)
+		str := tower.branchLabels(entry.World)
+		var clr color.Color = color.White
+		if tower.ChoosingBranch {
+			str = strings.TrimSpace(str + " UPG?")
+			clr = branchPendingColor
+		}
+		if str != "" {
+			op := &text.DrawOptions{}
+			op.ColorScale.ScaleWithColor(clr)
+			labelWidth, labelHeight := text.Measure(str, assets.InfoFace, op.LineSpacing)
+			op.GeoM.Translate(float64(rect.Min.X)+(float64(rect.Dx())-labelWidth)/2, float64(rect.Max.Y)+textHeight+4)
+			text.Draw(screen, str, assets.InfoFace, op)
+			textHeight += labelHeight
+		}
+	}
+
+	if entry.HasComponent(StatusEffects) {
+		// draw a small colored square along the top for each active status effect
+		effects := StatusEffects.Get(entry)
+		const size = 4
+		x := float32(rect.Max.X - size)
+		for _, tint := range effectTints {
+			if effects.Has(tint.kind) {
+				vector.DrawFilledRect(screen, x, float32(rect.Min.Y-size-1), size, size, tint.icon, true)
+				x -= size + 2
+			}
+		}
+	}
+
 	config := config.GetConfig(entry.World)
 	if config.Debug {
 		if entry.HasComponent(Attack) {
@@ -90,6 +163,9 @@ func (t *InfoRenderData) Draw(screen *ebiten.Image, entry *donburi.Entry) {
 			} else {
 				str = fmt.Sprintf("%d", attack.Power)
 			}
+			if entry.HasComponent(Veterancy) {
+				str = fmt.Sprintf("%s XP %d", str, Veterancy.Get(entry).XP)
+			}
 			op := &text.DrawOptions{}
 			textWidth, _ = text.Measure(str, assets.InfoFace, op.LineSpacing)
 			op.GeoM.Translate(float64(rect.Min.X)+(float64(rect.Dx())-textWidth)/2, float64(rect.Max.Y)+textHeight)
@@ -114,7 +190,12 @@ func DrawEntry(screen *ebiten.Image, entry *donburi.Entry, debug bool) {
 		render := SpriteRender.Get(entry)
 		render.Draw(screen, entry)
 		if debug {
-			ebitenutil.DebugPrintAt(screen, render.Name, GetRect(entry).Min.X, GetRect(entry).Min.Y-10)
+			label := render.Name
+			if entry.HasComponent(Creep) {
+				// creep sprites can be shared so label them by archetype
+				label = Creep.Get(entry).Type
+			}
+			ebitenutil.DebugPrintAt(screen, label, GetRect(entry).Min.X, GetRect(entry).Min.Y-10)
 		}
 	}
 	if entry.HasComponent(InfoRender) {
@@ -133,6 +214,14 @@ func DrawEntry(screen *ebiten.Image, entry *donburi.Entry, debug bool) {
 		bulletRender := BulletRender.Get(entry)
 		bulletRender.Draw(screen, entry)
 	}
+	if entry.HasComponent(HazardRender) {
+		hazardRender := HazardRender.Get(entry)
+		hazardRender.Draw(screen, entry)
+	}
+	if entry.HasComponent(Beam) {
+		beam := Beam.Get(entry)
+		beam.Draw(screen, entry)
+	}
 }
 
 func GetRect(entry *donburi.Entry) image.Rectangle {
@@ -142,6 +231,9 @@ func GetRect(entry *donburi.Entry) image.Rectangle {
 	} else if entry.HasComponent(BulletRender) {
 		render := BulletRender.Get(entry)
 		return render.GetRect(entry)
+	} else if entry.HasComponent(HazardRender) {
+		render := HazardRender.Get(entry)
+		return render.GetRect(entry)
 	}
 	panic("GetRect() unimplemented for entry without SpriteRender or BulletRender component")
 }
@@ -159,12 +251,24 @@ func DrawBoard(image *ebiten.Image, world donburi.World, config *config.ConfigDa
 		DrawGridLines(image)
 	}
 
-	query := donburi.NewQuery(filter.Contains(Position))
+	// hazards lie on the ground so draw them below everything else
+	hazards := donburi.NewQuery(filter.Contains(HazardRender, Position))
+	hazards.Each(world, func(entry *donburi.Entry) {
+		DrawEntry(image, entry, config.Debug)
+	})
+
+	query := donburi.NewQuery(filter.And(filter.Contains(Position), filter.Not(filter.Or(filter.Contains(HazardRender), filter.Contains(Flying)))))
 
 	query.Each(world, func(entry *donburi.Entry) {
 		DrawEntry(image, entry, config.Debug)
 	})
 
+	// flying creeps are drawn last so they pass over everything on the ground
+	flying := donburi.NewQuery(filter.Contains(Flying, Position))
+	flying.Each(world, func(entry *donburi.Entry) {
+		DrawEntry(image, entry, config.Debug)
+	})
+
 	if drawText != nil {
 		drawText(image)
 	}
@@ -0,0 +1,516 @@
+package components
+
+import (
+	"fmt"
+	"image"
+
+	"tower-defense/config"
+	"tower-defense/util"
+
+	"github.com/leap-fish/necs/esync/srvsync"
+	"github.com/yohamta/donburi"
+	"github.com/yohamta/donburi/filter"
+)
+
+// savedEntities are the entities a saved battle keeps, the board and battle state are created by the battle itself
+var savedEntities = donburi.NewQuery(filter.Or(
+	filter.Contains(Bullet),
+	filter.Contains(Beam),
+	filter.Contains(Hazard),
+	filter.Contains(Player),
+	filter.Contains(Tower),
+	filter.Contains(Creep),
+))
+
+// WorldSave is the battle's entities and the server-only world state a saved battle resumes from
+type WorldSave struct {
+	Entities []EntitySave
+	// Random is the state of the random number generator, so the resumed battle rolls the same as it would have
+	Random     []byte
+	AurasDirty bool
+}
+
+// EntitySave is one saved entity, each component it has is set and the rest are nil.
+// ID is the entity it was in the saved world, references between entities are pointed at the new entities on load.
+type EntitySave struct {
+	ID            donburi.Entity
+	Position      *PositionData      `json:",omitempty"`
+	Velocity      *VelocitySave      `json:",omitempty"`
+	Health        *HealthData        `json:",omitempty"`
+	Attack        *AttackSave        `json:",omitempty"`
+	Level         *LevelData         `json:",omitempty"`
+	Veterancy     *VeterancyData     `json:",omitempty"`
+	AuraBuff      *AuraBuffData      `json:",omitempty"`
+	Ammo          *AmmoSave          `json:",omitempty"`
+	Tower         *TowerData         `json:",omitempty"`
+	Creep         *CreepSave         `json:",omitempty"`
+	Flying        *FlyingData        `json:",omitempty"`
+	Boss          *BossSave          `json:",omitempty"`
+	Defense       *DefenseData       `json:",omitempty"`
+	StatusEffects *StatusEffectsSave `json:",omitempty"`
+	Bullet        *BulletSave        `json:",omitempty"`
+	BulletRender  *BulletRenderData  `json:",omitempty"`
+	Beam          *BeamSave          `json:",omitempty"`
+	Hazard        *HazardSave        `json:",omitempty"`
+	HazardRender  *HazardRenderData  `json:",omitempty"`
+	Player        *PlayerSave        `json:",omitempty"`
+	PlayerRender  *PlayerRenderData  `json:",omitempty"`
+	Abilities     *AbilitiesSave     `json:",omitempty"`
+	SpriteRender  *SpriteRenderData  `json:",omitempty"`
+	RangeRender   *RangeRenderData   `json:",omitempty"`
+	InfoRender    *InfoRenderData    `json:",omitempty"`
+}
+
+// the save types below add the unexported state of a component to its exported fields
+
+type VelocitySave struct {
+	VelocityData
+	Blocked bool
+}
+
+type AttackSave struct {
+	AttackData
+	Cooldown      *util.CooldownSave
+	NoLead        bool
+	IgnoreDefense bool
+}
+
+type AmmoSave struct {
+	AmmoData
+	Reload *util.CooldownSave
+}
+
+type CreepSave struct {
+	CreepData
+	ScoreValue int
+	PathAround bool
+	Super      bool
+	Level      int
+	Generation int
+	// Behaviors are in the order of the creep type's behaviors in the balance
+	Behaviors []BehaviorSave
+}
+
+type BehaviorSave struct {
+	Cooldown *util.CooldownSave
+	Minions  []donburi.Entity `json:",omitempty"`
+}
+
+type BossSave struct {
+	BossData
+	MinionTicks int
+	ShotTicks   int
+}
+
+type StatusEffectsSave struct {
+	Effects []StatusEffectSave
+}
+
+type StatusEffectSave struct {
+	StatusEffect
+	Elapsed int
+}
+
+type BulletSave struct {
+	Start, End image.Point
+	Speed      int
+	Creep      bool
+	Source     donburi.Entity
+	Kind       ProjectileKind
+	Target     donburi.Entity
+	Heading    image.Point
+	Flown      float64
+	Pierce     int
+	Hit        []donburi.Entity `json:",omitempty"`
+}
+
+type BeamSave struct {
+	BeamData
+	Ticks int
+}
+
+type HazardSave struct {
+	HazardData
+	Interval int
+	Elapsed  int
+}
+
+type PlayerSave struct {
+	PlayerData
+	MovingTower   donburi.Entity
+	ChoosingTower donburi.Entity
+}
+
+type AbilitiesSave struct {
+	AbilitiesData
+	Timers []*util.CooldownSave
+	Aiming bool
+}
+
+// WaveRunnerSave is how far a wave script has played
+type WaveRunnerSave struct {
+	Index, Loop, Wait, Tick int
+	Pending                 []WaveSpawnSave
+	Started                 int
+	Done                    bool
+}
+
+type WaveSpawnSave struct {
+	At               int
+	CreepType        string
+	X                int
+	LevelBonus       int
+	HealthMultiplier int
+}
+
+// SaveWorld captures every entity of the battle in the order the battle updates them
+func SaveWorld(world donburi.World) (*WorldSave, error) {
+	random, err := SaveRandom(world)
+	if err != nil {
+		return nil, err
+	}
+	save := &WorldSave{Random: random, AurasDirty: getAuraState(world).dirty}
+	savedEntities.Each(world, func(entry *donburi.Entry) {
+		save.Entities = append(save.Entities, saveEntity(entry))
+	})
+	return save, nil
+}
+
+// LoadWorld creates the saved entities in a world cleared of any earlier battle, synced the same way the game creates them
+func LoadWorld(world donburi.World, seed uint64, save *WorldSave) error {
+	if err := RestoreRandom(world, seed, save.Random); err != nil {
+		return err
+	}
+
+	entries := make([]*donburi.Entry, len(save.Entities))
+	ids := make(map[donburi.Entity]donburi.Entity, len(save.Entities))
+	for i := range save.Entities {
+		entry, err := save.Entities[i].load(world)
+		if err != nil {
+			return err
+		}
+		entries[i] = entry
+		ids[save.Entities[i].ID] = entry.Entity()
+	}
+
+	// entities that were already gone when the battle was saved are gone for good
+	remap := func(entity donburi.Entity) donburi.Entity {
+		if id, ok := ids[entity]; ok {
+			return id
+		}
+		return donburi.Null
+	}
+	for _, entry := range entries {
+		if entry.HasComponent(Bullet) {
+			bullet := Bullet.Get(entry)
+			bullet.source = remap(bullet.source)
+			bullet.target = remap(bullet.target)
+			for i, hit := range bullet.hit {
+				bullet.hit[i] = remap(hit)
+			}
+		}
+		if entry.HasComponent(Player) {
+			player := Player.Get(entry)
+			player.movingTower = remap(player.movingTower)
+			player.choosingTower = remap(player.choosingTower)
+		}
+		if entry.HasComponent(Creep) {
+			for _, behavior := range Creep.Get(entry).behaviors {
+				if summon, ok := behavior.(*summonBehavior); ok {
+					for i, minion := range summon.minions {
+						summon.minions[i] = remap(minion)
+					}
+				}
+			}
+		}
+	}
+
+	MarkNavGridDirty(world)
+	getAuraState(world).dirty = save.AurasDirty
+	return nil
+}
+
+// copyData returns a copy of the entry's component, nil when it doesn't have one
+func copyData[T any](entry *donburi.Entry, component *donburi.ComponentType[T]) *T {
+	if !entry.HasComponent(component) {
+		return nil
+	}
+	data := *component.Get(entry)
+	return &data
+}
+
+func saveEntity(entry *donburi.Entry) EntitySave {
+	save := EntitySave{
+		ID:           entry.Entity(),
+		Position:     copyData(entry, Position),
+		Health:       copyData(entry, Health),
+		Level:        copyData(entry, Level),
+		Veterancy:    copyData(entry, Veterancy),
+		AuraBuff:     copyData(entry, AuraBuff),
+		Tower:        copyData(entry, Tower),
+		Flying:       copyData(entry, Flying),
+		Defense:      copyData(entry, Defense),
+		BulletRender: copyData(entry, BulletRender),
+		HazardRender: copyData(entry, HazardRender),
+		PlayerRender: copyData(entry, PlayerRender),
+		SpriteRender: copyData(entry, SpriteRender),
+		RangeRender:  copyData(entry, RangeRender),
+		InfoRender:   copyData(entry, InfoRender),
+	}
+	if entry.HasComponent(Velocity) {
+		v := Velocity.Get(entry)
+		save.Velocity = &VelocitySave{VelocityData: *v, Blocked: v.blocked}
+	}
+	if entry.HasComponent(Attack) {
+		a := Attack.Get(entry)
+		save.Attack = &AttackSave{AttackData: *a, Cooldown: a.cooldown.Save(), NoLead: a.noLead, IgnoreDefense: a.ignoreDefense}
+	}
+	if entry.HasComponent(Ammo) {
+		a := Ammo.Get(entry)
+		save.Ammo = &AmmoSave{AmmoData: *a, Reload: a.reload.Save()}
+	}
+	if entry.HasComponent(Creep) {
+		c := Creep.Get(entry)
+		save.Creep = &CreepSave{CreepData: *c, ScoreValue: c.scoreValue, PathAround: c.pathAround, Super: c.super, Level: c.level, Generation: c.generation}
+		for _, behavior := range c.behaviors {
+			save.Creep.Behaviors = append(save.Creep.Behaviors, saveBehavior(behavior))
+		}
+	}
+	if entry.HasComponent(Boss) {
+		b := Boss.Get(entry)
+		save.Boss = &BossSave{BossData: *b, MinionTicks: b.minionTicks, ShotTicks: b.shotTicks}
+	}
+	if entry.HasComponent(StatusEffects) {
+		save.StatusEffects = &StatusEffectsSave{}
+		for _, effect := range StatusEffects.Get(entry).Effects {
+			save.StatusEffects.Effects = append(save.StatusEffects.Effects, StatusEffectSave{StatusEffect: effect, Elapsed: effect.elapsed})
+		}
+	}
+	if entry.HasComponent(Bullet) {
+		b := Bullet.Get(entry)
+		save.Bullet = &BulletSave{Start: b.start, End: b.end, Speed: b.speed, Creep: b.creep, Source: b.source, Kind: b.kind,
+			Target: b.target, Heading: b.heading, Flown: b.flown, Pierce: b.pierce, Hit: append([]donburi.Entity{}, b.hit...)}
+	}
+	if entry.HasComponent(Beam) {
+		b := Beam.Get(entry)
+		save.Beam = &BeamSave{BeamData: *b, Ticks: b.ticks}
+	}
+	if entry.HasComponent(Hazard) {
+		h := Hazard.Get(entry)
+		save.Hazard = &HazardSave{HazardData: *h, Interval: h.interval, Elapsed: h.elapsed}
+	}
+	if entry.HasComponent(Player) {
+		p := Player.Get(entry)
+		save.Player = &PlayerSave{PlayerData: *p, MovingTower: p.movingTower, ChoosingTower: p.choosingTower}
+	}
+	if entry.HasComponent(Abilities) {
+		a := Abilities.Get(entry)
+		save.Abilities = &AbilitiesSave{AbilitiesData: *a, Aiming: a.aiming}
+		for _, timer := range a.timers {
+			save.Abilities.Timers = append(save.Abilities.Timers, timer.Save())
+		}
+	}
+	return save
+}
+
+func saveBehavior(behavior CreepBehavior) BehaviorSave {
+	switch b := behavior.(type) {
+	case *healBehavior:
+		return BehaviorSave{Cooldown: b.cooldown.Save()}
+	case *shieldBehavior:
+		return BehaviorSave{Cooldown: b.cooldown.Save()}
+	case *summonBehavior:
+		return BehaviorSave{Cooldown: b.cooldown.Save(), Minions: append([]donburi.Entity{}, b.minions...)}
+	}
+	return BehaviorSave{}
+}
+
+// components lists the saved entity's components, in the same order every time so saved entities share archetypes
+func (s *EntitySave) components() []donburi.IComponentType {
+	components := make([]donburi.IComponentType, 0)
+	add := func(present bool, component donburi.IComponentType) {
+		if present {
+			components = append(components, component)
+		}
+	}
+	add(s.Position != nil, Position)
+	add(s.Velocity != nil, Velocity)
+	add(s.Health != nil, Health)
+	add(s.Attack != nil, Attack)
+	add(s.Level != nil, Level)
+	add(s.Veterancy != nil, Veterancy)
+	add(s.AuraBuff != nil, AuraBuff)
+	add(s.Ammo != nil, Ammo)
+	add(s.Tower != nil, Tower)
+	add(s.Creep != nil, Creep)
+	add(s.Flying != nil, Flying)
+	add(s.Boss != nil, Boss)
+	add(s.Defense != nil, Defense)
+	add(s.StatusEffects != nil, StatusEffects)
+	add(s.Bullet != nil, Bullet)
+	add(s.BulletRender != nil, BulletRender)
+	add(s.Beam != nil, Beam)
+	add(s.Hazard != nil, Hazard)
+	add(s.HazardRender != nil, HazardRender)
+	add(s.Player != nil, Player)
+	add(s.PlayerRender != nil, PlayerRender)
+	add(s.Abilities != nil, Abilities)
+	add(s.SpriteRender != nil, SpriteRender)
+	add(s.RangeRender != nil, RangeRender)
+	add(s.InfoRender != nil, InfoRender)
+	return components
+}
+
+// load creates the entity, its references to other entities still point into the saved world
+func (s *EntitySave) load(world donburi.World) (*donburi.Entry, error) {
+	components := s.components()
+	// velocity is only used on the server and so is the attack a hazard deals, the game never syncs them
+	synced := make([]donburi.IComponentType, 0, len(components))
+	for _, component := range components {
+		if component == Velocity || (component == Attack && s.Hazard != nil) {
+			continue
+		}
+		synced = append(synced, component)
+	}
+	entity := world.Create(components...)
+	err := srvsync.NetworkSync(world, &entity, synced...)
+	if err != nil {
+		return nil, err
+	}
+	entry := world.Entry(entity)
+
+	setData(entry, Position, s.Position)
+	setData(entry, Health, s.Health)
+	setData(entry, Level, s.Level)
+	setData(entry, Veterancy, s.Veterancy)
+	setData(entry, AuraBuff, s.AuraBuff)
+	setData(entry, Tower, s.Tower)
+	setData(entry, Flying, s.Flying)
+	setData(entry, Defense, s.Defense)
+	setData(entry, BulletRender, s.BulletRender)
+	setData(entry, HazardRender, s.HazardRender)
+	setData(entry, PlayerRender, s.PlayerRender)
+	setData(entry, SpriteRender, s.SpriteRender)
+	setData(entry, RangeRender, s.RangeRender)
+	setData(entry, InfoRender, s.InfoRender)
+	if s.Velocity != nil {
+		v := s.Velocity.VelocityData
+		v.blocked = s.Velocity.Blocked
+		Velocity.Set(entry, &v)
+	}
+	if s.Attack != nil {
+		a := s.Attack.AttackData
+		a.cooldown, a.noLead, a.ignoreDefense = s.Attack.Cooldown.Load(), s.Attack.NoLead, s.Attack.IgnoreDefense
+		Attack.Set(entry, &a)
+	}
+	if s.Ammo != nil {
+		a := s.Ammo.AmmoData
+		a.reload = s.Ammo.Reload.Load()
+		Ammo.Set(entry, &a)
+	}
+	if s.Creep != nil {
+		c, err := s.Creep.load(world)
+		if err != nil {
+			return nil, err
+		}
+		Creep.Set(entry, c)
+	}
+	if s.Boss != nil {
+		b := s.Boss.BossData
+		b.minionTicks, b.shotTicks = s.Boss.MinionTicks, s.Boss.ShotTicks
+		Boss.Set(entry, &b)
+	}
+	if s.StatusEffects != nil {
+		effects := &StatusEffectsData{}
+		for _, saved := range s.StatusEffects.Effects {
+			effect := saved.StatusEffect
+			effect.elapsed = saved.Elapsed
+			effects.Effects = append(effects.Effects, effect)
+		}
+		StatusEffects.Set(entry, effects)
+	}
+	if s.Bullet != nil {
+		b := s.Bullet
+		Bullet.Set(entry, &BulletData{start: b.Start, end: b.End, speed: b.Speed, creep: b.Creep, source: b.Source, kind: b.Kind,
+			target: b.Target, heading: b.Heading, flown: b.Flown, pierce: b.Pierce, hit: append([]donburi.Entity{}, b.Hit...)})
+	}
+	if s.Beam != nil {
+		b := s.Beam.BeamData
+		b.ticks = s.Beam.Ticks
+		Beam.Set(entry, &b)
+	}
+	if s.Hazard != nil {
+		h := s.Hazard.HazardData
+		h.interval, h.elapsed = s.Hazard.Interval, s.Hazard.Elapsed
+		Hazard.Set(entry, &h)
+	}
+	if s.Player != nil {
+		p := s.Player.PlayerData
+		p.movingTower, p.choosingTower = s.Player.MovingTower, s.Player.ChoosingTower
+		Player.Set(entry, &p)
+	}
+	if s.Abilities != nil {
+		a := s.Abilities.AbilitiesData
+		a.aiming = s.Abilities.Aiming
+		a.timers = make([]*util.CooldownTimer, 0, len(s.Abilities.Timers))
+		for _, timer := range s.Abilities.Timers {
+			a.timers = append(a.timers, timer.Load())
+		}
+		Abilities.Set(entry, &a)
+	}
+	return entry, nil
+}
+
+func setData[T any](entry *donburi.Entry, component *donburi.ComponentType[T], data *T) {
+	if data != nil {
+		component.Set(entry, data)
+	}
+}
+
+// load rebuilds the creep's behaviors from its type in the balance and puts back where their cooldowns were
+func (s *CreepSave) load(world donburi.World) (*CreepData, error) {
+	creepType := config.GetBalance(world).Creep.GetType(s.Type)
+	if creepType == nil {
+		return nil, fmt.Errorf("unknown creep type %q", s.Type)
+	}
+	behaviors, err := NewCreepBehaviors(creepType.Behaviors)
+	if err != nil {
+		return nil, err
+	}
+	if len(behaviors) != len(s.Behaviors) {
+		return nil, fmt.Errorf("creep type %v has %v behaviors, the save has %v", s.Type, len(behaviors), len(s.Behaviors))
+	}
+	for i, behavior := range behaviors {
+		saved := s.Behaviors[i]
+		switch b := behavior.(type) {
+		case *healBehavior:
+			b.cooldown = saved.Cooldown.Load()
+		case *shieldBehavior:
+			b.cooldown = saved.Cooldown.Load()
+		case *summonBehavior:
+			b.cooldown = saved.Cooldown.Load()
+			b.minions = append([]donburi.Entity{}, saved.Minions...)
+		}
+	}
+	c := s.CreepData
+	c.scoreValue, c.pathAround, c.super, c.level, c.generation, c.behaviors = s.ScoreValue, s.PathAround, s.Super, s.Level, s.Generation, behaviors
+	return &c, nil
+}
+
+// Save returns how far the script has played
+func (w *WaveRunner) Save() *WaveRunnerSave {
+	save := &WaveRunnerSave{Index: w.index, Loop: w.loop, Wait: w.wait, Tick: w.tick, Started: w.started, Done: w.done}
+	for _, spawn := range w.pending {
+		save.Pending = append(save.Pending, WaveSpawnSave{At: spawn.at, CreepType: spawn.creepType, X: spawn.x, LevelBonus: spawn.levelBonus, HealthMultiplier: spawn.healthMultiplier})
+	}
+	return save
+}
+
+// LoadWaveRunner carries on playing the script from where it was saved
+func LoadWaveRunner(script *config.WaveScriptData, save *WaveRunnerSave) *WaveRunner {
+	w := &WaveRunner{script: script, index: save.Index, loop: save.Loop, wait: save.Wait, tick: save.Tick, started: save.Started, done: save.Done}
+	for _, spawn := range save.Pending {
+		w.pending = append(w.pending, waveSpawn{at: spawn.At, creepType: spawn.CreepType, x: spawn.X, levelBonus: spawn.LevelBonus, healthMultiplier: spawn.HealthMultiplier})
+	}
+	return w
+}
@@ -0,0 +1,151 @@
+package components
+
+import (
+	"encoding/json"
+	"image"
+	"testing"
+
+	"tower-defense/config"
+
+	"github.com/yohamta/donburi"
+)
+
+// newSaveTestWorld is the wave test world without its stand-in player, the saves bring their own
+func newSaveTestWorld(t *testing.T) donburi.World {
+	t.Helper()
+	world := newWaveTestWorld(t)
+	world.Remove(Player.MustFirst(world).Entity())
+	return world
+}
+
+func TestLoadWorldRestoresEntitiesAndReferences(t *testing.T) {
+	world := newSaveTestWorld(t)
+	SeedRandom(world, 9)
+	if err := NewPlayer(world, 2); err != nil {
+		t.Fatal(err)
+	}
+	tower, err := NewTower(world, 100, 400, "Ranged")
+	if err != nil {
+		t.Fatal(err)
+	}
+	Attack.Get(tower).cooldown.StartCooldown()
+	Attack.Get(tower).cooldown.IncrementTicker()
+	caller, err := NewCreepOfType(world, 300, 100, "Caller", 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bullet, err := NewBullet(world, image.Point{110, 400}, image.Point{300, 100}, Attack.Get(tower), 5, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	Bullet.Get(bullet).source = tower.Entity()
+	player := Player.Get(Player.MustFirst(world))
+	player.movingTower = tower.Entity()
+	GetRandom(world).IntN(100)
+
+	save, err := SaveWorld(world)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bytes, err := json.Marshal(save)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var loadedSave WorldSave
+	if err := json.Unmarshal(bytes, &loadedSave); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded := newSaveTestWorld(t)
+	// an entity that isn't in the save shifts the loaded entities' ids
+	loaded.Create(Position)
+	if err := LoadWorld(loaded, 9, &loadedSave); err != nil {
+		t.Fatal(err)
+	}
+
+	loadedTower, ok := Tower.First(loaded)
+	if !ok {
+		t.Fatal("no tower after loading")
+	}
+	if got := Attack.Get(loadedTower).cooldown.GetDisplay(); got != Attack.Get(tower).cooldown.GetDisplay() {
+		t.Errorf("tower cooldown display = %v, want %v", got, Attack.Get(tower).cooldown.GetDisplay())
+	}
+	loadedPlayer := Player.Get(Player.MustFirst(loaded))
+	if loadedPlayer.TowerLevels != 2 || loadedPlayer.movingTower != loadedTower.Entity() {
+		t.Errorf("player tower levels %v moving %v, want 2 moving the loaded tower %v", loadedPlayer.TowerLevels, loadedPlayer.movingTower, loadedTower.Entity())
+	}
+	loadedBullet, ok := Bullet.First(loaded)
+	if !ok {
+		t.Fatal("no bullet after loading")
+	}
+	if got := Bullet.Get(loadedBullet).source; got != loadedTower.Entity() {
+		t.Errorf("bullet source = %v, want the loaded tower %v", got, loadedTower.Entity())
+	}
+	if Bullet.Get(loadedBullet).end != Bullet.Get(bullet).end {
+		t.Errorf("bullet end = %v, want %v", Bullet.Get(loadedBullet).end, Bullet.Get(bullet).end)
+	}
+	loadedCaller, ok := Creep.First(loaded)
+	if !ok {
+		t.Fatal("no creep after loading")
+	}
+	creep := Creep.Get(loadedCaller)
+	if creep.Type != "Caller" || creep.level != 3 || creep.scoreValue != Creep.Get(caller).scoreValue || len(creep.behaviors) != 1 {
+		t.Errorf("creep %v level %v score %v with %v behaviors, want the saved Caller", creep.Type, creep.level, creep.scoreValue, len(creep.behaviors))
+	}
+	if Velocity.Get(loadedCaller).Y != Velocity.Get(caller).Y {
+		t.Errorf("creep speed = %v, want %v", Velocity.Get(loadedCaller).Y, Velocity.Get(caller).Y)
+	}
+	if got, want := GetRandom(loaded).IntN(100), GetRandom(world).IntN(100); got != want {
+		t.Errorf("roll after loading = %v, want %v", got, want)
+	}
+}
+
+func TestLoadWorldDropsReferencesToMissingEntities(t *testing.T) {
+	world := newSaveTestWorld(t)
+	if err := NewPlayer(world, 0); err != nil {
+		t.Fatal(err)
+	}
+	save, err := SaveWorld(world)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the tower being moved was sold before the save
+	save.Entities[0].Player.MovingTower = donburi.Entity(12345)
+
+	loaded := newSaveTestWorld(t)
+	if err := LoadWorld(loaded, 1, save); err != nil {
+		t.Fatal(err)
+	}
+	if got := Player.Get(Player.MustFirst(loaded)).movingTower; got != donburi.Null {
+		t.Errorf("movingTower = %v, want Null", got)
+	}
+}
+
+func TestWaveRunnerSaveCarriesOnScript(t *testing.T) {
+	world := newWaveTestWorld(t)
+	script, err := config.LoadWaveScript("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	runner := NewWaveRunner(script)
+	for range 200 {
+		if _, err := runner.Update(world, 1); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	loaded := LoadWaveRunner(script, runner.Save())
+	for range 500 {
+		want, err := runner.Update(world, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := loaded.Update(world, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != want || loaded.Wave() != runner.Wave() {
+			t.Fatalf("loaded runner spawned %v in wave %v, want %v in wave %v", got, loaded.Wave(), want, runner.Wave())
+		}
+	}
+}
@@ -29,6 +29,15 @@ func (s *SpriteRenderData) Draw(screen *ebiten.Image, entry *donburi.Entry) {
 	pos := Position.Get(entry)
 	opts := &ebiten.DrawImageOptions{}
 	opts.GeoM.Translate(float64(pos.X), float64(pos.Y))
+	if entry.HasComponent(StatusEffects) {
+		effects := StatusEffects.Get(entry)
+		for _, tint := range effectTints {
+			if effects.Has(tint.kind) {
+				opts.ColorScale.Scale(tint.r, tint.g, tint.b, 1)
+				break
+			}
+		}
+	}
 	screen.DrawImage(img, opts)
 
 	config := config.GetConfig(entry.World)
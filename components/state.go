@@ -1,6 +1,8 @@
 package components
 
 import (
+	"fmt"
+
 	"tower-defense/assets"
 	"tower-defense/config"
 
@@ -12,6 +14,10 @@ import (
 type BattleSceneState struct {
 	GameOver bool
 	Paused   bool
+	// Wave is the scripted wave number, zero when waves are random
+	Wave int
+	// Seed is the battle's random seed, shown at game over so the game can be played again
+	Seed uint64
 }
 
 var BattleState = donburi.NewComponentType[BattleSceneState]()
@@ -22,7 +28,7 @@ func (bss *BattleSceneState) Draw(screen *ebiten.Image, width, height float64, c
 		str := "GAME OVER"
 		nextY = DrawTextLines(screen, assets.ScoreFace, str, width, height/2, text.AlignCenter, text.AlignCenter)
 
-		str = "Press R to reset game"
+		str = fmt.Sprintf("Seed %d\nPress R to reset game", bss.Seed)
 		_ = DrawTextLines(screen, assets.InfoFace, str, width, nextY, text.AlignCenter, text.AlignStart)
 
 	} else if bss.Paused {
@@ -30,6 +36,10 @@ func (bss *BattleSceneState) Draw(screen *ebiten.Image, width, height float64, c
 		_ = DrawTextLines(screen, assets.ScoreFace, str, width, height/2, text.AlignCenter, text.AlignCenter)
 	}
 
+	if bss.Wave > 0 {
+		_ = DrawTextLines(screen, assets.InfoFace, fmt.Sprintf("Wave %d", bss.Wave), width, TextBorder, text.AlignCenter, text.AlignStart)
+	}
+
 	if gameStats != nil && config.ShowStats {
 		str := gameStats.StatsLines(" ", !bss.GameOver, true, "High", "Player")
 		_ = DrawTextLines(screen, assets.InfoFace, str, width, 450, text.AlignStart, text.AlignStart)
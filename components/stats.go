@@ -20,19 +20,54 @@ type GameStats struct {
 		HighCreepLevel int
 		HighTowerLevel int
 
+		AbilitiesUsed     int
+		AirstrikeKills    int
+		AmmoReloaded      int
+		AreaKills         int
+		BeamsFired        int
+		BossesSpawned     int
+		BossKills         int
+		BossMinions       int
+		BossPhases        int
+		BranchesChosen    int
+		BulletHits        int
 		BulletsExpired    int
 		CreepBulletsFired int
+		CreepHealing      int
+		CreepsFrozen      int
 		CreepsKilled      int
 		CreepsSpawned     int
+		CreepsSummoned    int
 		CreepWaves        int
+		EffectsApplied    int
+		FlyingKills       int
+		FlyingSpawned     int
+		HazardKills       int
+		HazardsCreated    int
 		MoneySpent        int
+		PiercingHits      int
 		PlayerDeaths      int
+		PoisonKills       int
+		ShieldAbsorbed    int
+		SplitKills        int
+		SplitScore        int
+		SplitsSpawned     int
 		TowerBulletsFired int
 		TowersAmmoOut     int
+		TowersBuffed      int
 		TowersBuilt       int
 		TowersHealed      int
 		TowersKilled      int
+		TowersMoved       int
+		TowersOutOfAmmo   int
+		TowersRepaired    int
+		TowersResupplied  int
+		TowersSold        int
 		TowersUpgraded    int
+		VeteranRanks      int
+
+		Killed<CreepType> int, one per creep type in the balance
+		VeteranRank<N>    int, towers that reached each veteran rank
 	*/
 	StartTime time.Time
 	GameTime  time.Duration
@@ -43,25 +78,59 @@ const statsFile = "score/stats.txt"
 var (
 	gameStats  *GameStats
 	validStats = []string{
+		"AbilitiesUsed",
+		"AirstrikeKills",
+		"AmmoReloaded",
+		"AreaKills",
+		"BeamsFired",
+		"BossesSpawned",
+		"BossKills",
+		"BossMinions",
+		"BossPhases",
+		"BranchesChosen",
+		"BulletHits",
 		"BulletsExpired",
 		"CreepBulletsFired",
+		"CreepHealing",
+		"CreepsFrozen",
 		"CreepsKilled",
 		"CreepsSpawned",
+		"CreepsSummoned",
 		"CreepWaves",
+		"EffectsApplied",
+		"FlyingKills",
+		"FlyingSpawned",
 		"Games",
+		"HazardKills",
+		"HazardsCreated",
 		"HighCreepLevel",
 		"HighScore",
 		"HighTowerLevel",
 		"MoneySpent",
+		"PiercingHits",
 		"PlayerDeaths",
+		"PoisonKills",
+		"ShieldAbsorbed",
+		"SplitKills",
+		"SplitScore",
+		"SplitsSpawned",
 		"TowerBulletsFired",
 		"TowersAmmoOut",
+		"TowersBuffed",
 		"TowersBuilt",
 		"TowersHealed",
 		"TowersKilled",
+		"TowersMoved",
+		"TowersOutOfAmmo",
+		"TowersRepaired",
+		"TowersResupplied",
+		"TowersSold",
 		"TowersUpgraded",
+		"VeteranRanks",
 	}
 	displayNames = makeDisplayNames(validStats)
+	// dynamicStatPrefixes are stats kept for each creep type or veteran rank, named prefix then type or rank like KilledGrunt or VeteranRank2
+	dynamicStatPrefixes = []string{"Killed", "VeteranRank"}
 )
 
 func SetGameStats(gs *GameStats) {
@@ -93,13 +162,23 @@ func (gs *GameStats) Update(other *GameStats) {
 	gs.UpdateHighs(other.stats["HighScore"], other.stats["HighCreepLevel"], other.stats["HighTowerLevel"])
 	gs.stats["Games"]++
 
-
-	iterExcludePrefix(validStats, func(name string) {
-		gs.stats[name] += other.stats[name]
+	iterExcludePrefix(slices.Sorted(maps.Keys(other.stats)), func(name string) {
+		if isValidStat(name) {
+			gs.stats[name] += other.stats[name]
+		}
 	}, "Game", "High")
 	gs.GameTime += other.GameTime
 }
 
+// Counters copies this game's stats, leaving out the high scores and game totals
+func (gs *GameStats) Counters() map[string]int {
+	counters := make(map[string]int, len(gs.stats))
+	gs.iterExcludePrefix(func(name string) {
+		counters[name] = gs.stats[name]
+	}, "High", "Game")
+	return counters
+}
+
 func (gs *GameStats) UpdateStat(name string, count int) {
 	gs.stats[name] += count
 }
@@ -122,6 +201,17 @@ func (gs *GameStats) Reset() {
 	gs.GameTime = 0
 }
 
+// Resume restarts the stats from a saved battle's counters, with its clock carrying on from the time already played
+func (gs *GameStats) Resume(counters map[string]int, gameTime time.Duration) {
+	gs.Reset()
+	for name, count := range counters {
+		if isValidStat(name) {
+			gs.stats[name] = count
+		}
+	}
+	gs.StartTime = time.Now().Add(-gameTime)
+}
+
 func LoadStats() *GameStats {
 	gameStats := NewGameStats(nil)
 	bytes, err := os.ReadFile(statsFile)
@@ -138,7 +228,7 @@ func LoadStats() *GameStats {
 				if err != nil {
 					fmt.Printf("WARN %s formatting err %s %v\n", name, value, err)
 				}
-			} else if slices.Contains(validStats, name) {
+			} else if isValidStat(name) {
 				gameStats.stats[name] = parseScore(name, value)
 			} else {
 				fmt.Printf("Invalid stat loaded %s\n", line)
@@ -206,7 +296,7 @@ func (gs *GameStats) StatsLines(delim string, runningTime, forDisplay bool, excl
 	gs.iterExcludePrefix(func(name string) {
 		displayName := name
 		if forDisplay {
-			displayName = displayNames[name]
+			displayName = getDisplayName(name)
 		}
 		fmt.Fprintf(&b, "%s%s%d\n", displayName, delim, gs.stats[name])
 	}, excludePrefixes...)
@@ -240,6 +330,25 @@ func ensureDir(dirName string) error {
 	return err
 }
 
+func isValidStat(name string) bool {
+	if slices.Contains(validStats, name) {
+		return true
+	}
+	for _, prefix := range dynamicStatPrefixes {
+		if len(name) > len(prefix) && strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func getDisplayName(name string) string {
+	if displayName, ok := displayNames[name]; ok {
+		return displayName
+	}
+	return makeDisplayName(name)
+}
+
 func makeDisplayNames(validStats []string) map[string]string {
 	displayNames := make(map[string]string, len(validStats))
 	for _, name := range validStats {
@@ -62,6 +62,9 @@ func TestGameStatsUpdateAggregatesRunStats(t *testing.T) {
 	run.UpdateHighs(150, 2, 5)
 	run.UpdateStat("TowersBuilt", 4)
 	run.UpdateStat("CreepsKilled", 9)
+	run.UpdateStat("KilledGrunt", 7)
+	run.UpdateStat("VeteranRank2", 2)
+	run.UpdateStat("Bogus", 3)
 	run.GameTime = 5 * time.Second
 
 	total.Update(run)
@@ -84,6 +87,15 @@ func TestGameStatsUpdateAggregatesRunStats(t *testing.T) {
 	if got := total.GetStat("CreepsKilled"); got != 9 {
 		t.Errorf("CreepsKilled = %v, want 9", got)
 	}
+	if got := total.GetStat("KilledGrunt"); got != 7 {
+		t.Errorf("KilledGrunt = %v, want 7", got)
+	}
+	if got := total.GetStat("VeteranRank2"); got != 2 {
+		t.Errorf("VeteranRank2 = %v, want 2", got)
+	}
+	if got := total.GetStat("Bogus"); got != 0 {
+		t.Errorf("Bogus = %v, want unknown stats skipped", got)
+	}
 	if total.GameTime != 15*time.Second {
 		t.Errorf("GameTime = %v, want 15s", total.GameTime)
 	}
@@ -203,3 +215,47 @@ func TestGameStatsIterExcludePrefixUsesSortedMapKeys(t *testing.T) {
 		t.Fatalf("GameStats.iterExcludePrefix visited %v, want %v", got, want)
 	}
 }
+
+func TestGameStatsCountersExcludeHighsAndTotals(t *testing.T) {
+	gs := NewGameStats(nil)
+	gs.UpdateHighs(100, 4, 7)
+	gs.UpdateStat("Games", 2)
+	gs.UpdateStat("TowersBuilt", 3)
+
+	got := gs.Counters()
+	if got["TowersBuilt"] != 3 {
+		t.Errorf("TowersBuilt = %v, want 3", got["TowersBuilt"])
+	}
+	for _, name := range []string{"HighScore", "HighCreepLevel", "HighTowerLevel", "Games"} {
+		if _, ok := got[name]; ok {
+			t.Errorf("Counters() includes %v, want only this game's counters", name)
+		}
+	}
+	got["TowersBuilt"] = 0
+	if gs.GetStat("TowersBuilt") != 3 {
+		t.Errorf("changing the counters changed the stats")
+	}
+}
+
+func TestGameStatsResumeRestoresCountersAndTime(t *testing.T) {
+	gs := NewGameStats(nil)
+	gs.UpdateHighs(100, 4, 7)
+	gs.UpdateStat("TowersSold", 5)
+
+	gs.Resume(map[string]int{"TowersBuilt": 3, "NotAStat": 1}, time.Minute)
+	if gs.GetStat("TowersBuilt") != 3 {
+		t.Errorf("TowersBuilt = %v, want 3", gs.GetStat("TowersBuilt"))
+	}
+	if gs.GetStat("TowersSold") != 0 {
+		t.Errorf("TowersSold = %v, want the counters from before resuming cleared", gs.GetStat("TowersSold"))
+	}
+	if gs.GetStat("NotAStat") != 0 {
+		t.Errorf("NotAStat = %v, want unknown stats dropped", gs.GetStat("NotAStat"))
+	}
+	if gs.GetStat("HighScore") != 100 {
+		t.Errorf("HighScore = %v, want 100", gs.GetStat("HighScore"))
+	}
+	if gs.RunningTime() < time.Minute {
+		t.Errorf("RunningTime() = %v, want the saved minute carried on", gs.RunningTime())
+	}
+}
@@ -0,0 +1,81 @@
+package components
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/yohamta/donburi"
+)
+
+type TargetPriority int
+
+const (
+	Closest TargetPriority = iota
+	Furthest
+	LowestHealth
+	HighestHealth
+	StrongestAttack
+	SuperFirst
+)
+
+var targetPriorityNames = []string{"Closest", "Furthest", "LowestHealth", "HighestHealth", "StrongestAttack", "SuperFirst"}
+
+// short labels drawn on towers
+var targetPriorityLabels = []string{"NEAR", "FAR", "WEAK", "TANK", "STR", "SUP"}
+
+// ParseTargetPriority converts a balance targeting name, empty defaults to Closest
+func ParseTargetPriority(name string) (TargetPriority, error) {
+	if name == "" {
+		return Closest, nil
+	}
+	index := slices.Index(targetPriorityNames, name)
+	if index < 0 {
+		return Closest, fmt.Errorf("unknown targeting %q", name)
+	}
+	return TargetPriority(index), nil
+}
+
+func (tp TargetPriority) String() string {
+	if tp < 0 || int(tp) >= len(targetPriorityNames) {
+		return ""
+	}
+	return targetPriorityNames[tp]
+}
+
+func (tp TargetPriority) Label() string {
+	if tp < 0 || int(tp) >= len(targetPriorityLabels) {
+		return ""
+	}
+	return targetPriorityLabels[tp]
+}
+
+// Next cycles to the following priority, wrapping around
+func (tp TargetPriority) Next() TargetPriority {
+	return TargetPriority((int(tp) + 1) % len(targetPriorityNames))
+}
+
+// score rates an enemy for the priority, higher is better, ties go to the closest enemy
+func (tp TargetPriority) score(enemy *donburi.Entry) int {
+	switch tp {
+	case Furthest:
+		// furthest down the lane is closest to the base
+		return GetRect(enemy).Max.Y
+	case LowestHealth:
+		if enemy.HasComponent(Health) {
+			return -Health.Get(enemy).Health
+		}
+	case HighestHealth:
+		if enemy.HasComponent(Health) {
+			return Health.Get(enemy).Health
+		}
+	case StrongestAttack:
+		if enemy.HasComponent(Attack) {
+			return Attack.Get(enemy).Power
+		}
+	case SuperFirst:
+		if enemy.HasComponent(Creep) && Creep.Get(enemy).IsSuper() {
+			return 1
+		}
+	}
+	return 0
+}
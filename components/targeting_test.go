@@ -0,0 +1,96 @@
+package components
+
+import (
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/yohamta/donburi"
+)
+
+func TestParseTargetPriority(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    TargetPriority
+		wantErr bool
+	}{
+		{"", Closest, false},
+		{"Closest", Closest, false},
+		{"Furthest", Furthest, false},
+		{"SuperFirst", SuperFirst, false},
+		{"Random", Closest, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseTargetPriority(tt.name)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseTargetPriority() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseTargetPriority() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTargetPriority_NextWraps(t *testing.T) {
+	if got := Closest.Next(); got != Furthest {
+		t.Errorf("Closest.Next() = %v, want Furthest", got)
+	}
+	if got := SuperFirst.Next(); got != Closest {
+		t.Errorf("SuperFirst.Next() = %v, want Closest", got)
+	}
+}
+
+func TestAttackData_FindEnemyRangeUsesTargeting(t *testing.T) {
+	world := newAttackTestWorld(t)
+	tower := world.Entry(world.Create(Tower, Position, SpriteRender))
+	Position.Set(tower, &PositionData{X: 100, Y: 100})
+	SpriteRender.Set(tower, &SpriteRenderData{image: ebiten.NewImage(10, 10)})
+
+	// near is just above the tower, far is further away but lower down the lane
+	near := newAttackTestCreep(world, 100, 80, 5)
+	far := newAttackTestCreep(world, 60, 130, 2)
+	tank := newAttackTestCreep(world, 140, 60, 9)
+	superCreep := newAttackTestCreep(world, 60, 60, 3)
+	Creep.Get(superCreep).super = true
+	for entry, power := range map[*donburi.Entry]int{near: 1, far: 1, tank: 4, superCreep: 2} {
+		entry.AddComponent(Attack)
+		Attack.Set(entry, &AttackData{Power: power})
+	}
+	// out of range of everything
+	newAttackTestCreep(world, 400, 400, 1)
+
+	tests := []struct {
+		targeting TargetPriority
+		want      *donburi.Entry
+	}{
+		{Closest, near},
+		{Furthest, far},
+		{LowestHealth, far},
+		{HighestHealth, tank},
+		{StrongestAttack, tank},
+		{SuperFirst, superCreep},
+	}
+	for _, tt := range tests {
+		t.Run(tt.targeting.String(), func(t *testing.T) {
+			attack := &AttackData{Range: 50, Targeting: tt.targeting}
+			if got := attack.FindEnemyRange(tower, Creep); got != tt.want {
+				t.Errorf("FindEnemyRange() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPlayerData_CycleTowerTargeting(t *testing.T) {
+	world := newAttackTestWorld(t)
+	tower := world.Entry(world.Create(Tower, Attack))
+	Attack.Set(tower, &AttackData{Targeting: StrongestAttack})
+
+	player := Player.Get(Player.MustFirst(world))
+	if got := player.CycleTowerTargeting(tower, false); got != SuperFirst {
+		t.Errorf("CycleTowerTargeting() = %v, want SuperFirst", got)
+	}
+	if got := Attack.Get(tower).Targeting; got != SuperFirst {
+		t.Errorf("tower targeting = %v, want SuperFirst", got)
+	}
+}
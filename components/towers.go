@@ -13,6 +13,13 @@ import (
 )
 
 type TowerData struct {
+	Type string
+	// Spent is the money paid to place and upgrade this tower, part of it is refunded when sold
+	Spent int
+	// Branches are the names of the upgrade options taken at each branch level so far
+	Branches []string
+	// ChoosingBranch is set when the tower was upgraded to a branch level and no option was taken yet, it can't upgrade again until one is
+	ChoosingBranch bool
 }
 
 var Tower = donburi.NewComponentType[TowerData]()
@@ -30,30 +37,101 @@ func getTowerUpgradeCost(world donburi.World, name string) int {
 	return getTowerCost(world, name)
 }
 
-func NewTower(world donburi.World, x, y int) error {
-	towerEntity := world.Create(Tower, Position, Health, Attack, Level, SpriteRender, RangeRender, InfoRender)
-	err := srvsync.NetworkSync(world, &towerEntity, Tower, Position, Health, Attack, Level, SpriteRender, RangeRender, InfoRender)
+// GetTowerCosts returns the place, heal and upgrade costs of a tower type
+func GetTowerCosts(world donburi.World, name string) (place, heal, upgrade int) {
+	return getTowerCost(world, name), getTowerHealCost(world, name), getTowerUpgradeCost(world, name)
+}
+
+// GetTypeBalance returns the balance for this tower's type
+func (t *TowerData) GetTypeBalance(world donburi.World) *config.TowerTypeBalance {
+	return config.GetBalance(world).Tower.GetType(t.Type)
+}
+
+func NewTower(world donburi.World, x, y int, towerType string) (*donburi.Entry, error) {
+	balance := config.GetBalance(world).Tower
+	typeBalance := balance.GetType(towerType)
+	attackType, err := ParseAttackType(typeBalance.AttackType)
+	if err != nil {
+		return nil, fmt.Errorf("tower type %v: %w", typeBalance.Name, err)
+	}
+	effect, err := NewAttackEffect(typeBalance.Effect)
+	if err != nil {
+		return nil, fmt.Errorf("tower type %v: %w", typeBalance.Name, err)
+	}
+	hazard, err := NewHazardSpec(typeBalance.Hazard)
+	if err != nil {
+		return nil, fmt.Errorf("tower type %v: %w", typeBalance.Name, err)
+	}
+	targeting, err := ParseTargetPriority(typeBalance.Targeting)
+	if err != nil {
+		return nil, fmt.Errorf("tower type %v: %w", typeBalance.Name, err)
+	}
+	damageType, err := ParseDamageType(typeBalance.DamageType)
+	if err != nil {
+		return nil, fmt.Errorf("tower type %v: %w", typeBalance.Name, err)
+	}
+	projectile, err := ParseProjectileKind(typeBalance.Projectile)
+	if err != nil {
+		return nil, fmt.Errorf("tower type %v: %w", typeBalance.Name, err)
+	}
+	hazard.DamageType = damageType
+
+	components := []donburi.IComponentType{Tower, Position, Health, Attack, Level, Veterancy, AuraBuff, SpriteRender, RangeRender, InfoRender}
+	if balance.Ammo.SeparateAmmo {
+		components = append(components, Ammo)
+	}
+	towerEntity := world.Create(components...)
+	err = srvsync.NetworkSync(world, &towerEntity, components...)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	tower := world.Entry(towerEntity)
 
-	balance := config.GetBalance(world).Tower
+	Tower.Set(tower, &TowerData{Type: typeBalance.Name})
 	Position.Set(tower, &PositionData{x, y})
-	Health.Set(tower, NewHealthData(balance.Health))
-	Attack.Set(tower, &AttackData{Power: balance.AttackPower, AttackType: RangedSingle, Range: balance.AttackRange, cooldown: util.NewCooldownTimer(balance.AttackCooldown)})
+	Health.Set(tower, NewHealthData(typeBalance.Health))
+	Attack.Set(tower, &AttackData{
+		Power:       typeBalance.AttackPower,
+		AttackType:  attackType,
+		Range:       typeBalance.AttackRange,
+		AreaRadius:  typeBalance.AreaRadius,
+		AreaFalloff: typeBalance.AreaFalloff,
+		Effect:      effect,
+		Hazard:      hazard,
+		Targeting:   targeting,
+		DamageType:  damageType,
+		AntiAir:     typeBalance.AntiAir,
+		Projectile:  projectile,
+		Pierce:      typeBalance.Pierce,
+		cooldown:    util.NewCooldownTimer(typeBalance.AttackCooldown),
+	})
 	Level.Set(tower, &LevelData{Level: balance.InitialLevel})
-	SpriteRender.Set(tower, &SpriteRenderData{Name: "tower"})
+	SpriteRender.Set(tower, &SpriteRenderData{Name: typeBalance.Sprite})
 	RangeRender.Set(tower, &RangeRenderData{})
 	InfoRender.Set(tower, &InfoRenderData{})
-	return nil
+	if balance.Ammo.SeparateAmmo {
+		Ammo.Set(tower, NewAmmoData(config.GetBalance(world), typeBalance))
+	}
+	MarkNavGridDirty(world)
+	MarkAurasDirty(world)
+	return tower, nil
 }
 
 func (t *TowerData) Update(entry *donburi.Entry) error {
 	a := Attack.Get(entry)
-	a.AttackEnemyRange(entry, AfterTowerAttack, Creep)
-
-	return nil
+	if a.Power <= 0 {
+		// blocking only towers never fire so they don't use up their ammo
+		return nil
+	}
+	if entry.HasComponent(Ammo) {
+		ammo := Ammo.Get(entry)
+		ammo.Reload(entry.World)
+		if ammo.Ammo <= 0 {
+			// hold fire until reloaded or resupplied
+			return nil
+		}
+	}
+	return a.AttackEnemyRange(entry, OnKillCreep, AfterTowerAttack, Creep)
 }
 
 func (t *TowerData) Heal(entry *donburi.Entry, debug bool) bool {
@@ -78,6 +156,12 @@ func (p *PlayerData) GetMaxTowerLevel(balance *config.BalanceData) int {
 }
 
 func (t *TowerData) Upgrade(entry *donburi.Entry, debug bool) bool {
+	if t.ChoosingBranch {
+		if debug {
+			fmt.Printf("Tower is waiting on an upgrade choice\n")
+		}
+		return false
+	}
 	level := Level.Get(entry)
 	if level.Level >= GetMaxTowerLevel(entry.World) {
 		if debug {
@@ -85,15 +169,24 @@ func (t *TowerData) Upgrade(entry *donburi.Entry, debug bool) bool {
 		}
 		return false
 	}
+	clearAuraBuff(entry)
 	level.Level++
 	health := Health.Get(entry)
-	balance := config.GetBalance(entry.World).Tower
+	balance := t.GetTypeBalance(entry.World)
 	health.MaxHealth += balance.UpgradeMaxHealthAdd
 	health.Health = health.MaxHealth
+	if entry.HasComponent(Ammo) {
+		ammo := Ammo.Get(entry)
+		ammo.MaxAmmo += balance.UpgradeAmmoAdd
+		ammo.Ammo = ammo.MaxAmmo
+	}
 	attack := Attack.Get(entry)
-	attack.Power += level.Level / balance.UpgradePowerLevelDivisor
+	if balance.UpgradePowerLevelDivisor > 0 {
+		attack.Power += level.Level / balance.UpgradePowerLevelDivisor
+	}
 	attack.Range += balance.UpgradeRangeAdd
 	attack.cooldown.Cooldown = max(balance.UpgradeMinCooldown, attack.cooldown.Cooldown-balance.UpgradeCooldownReduction)
+	t.ChoosingBranch = t.branchAt(entry.World, level.Level) != nil
 	GetGameStats().IncrementStat("TowersUpgraded")
 
 	return true
@@ -111,13 +204,21 @@ func findTower(world donburi.World, x, y int) *donburi.Entry {
 	return foundEntry
 }
 
-func AfterTowerAttack(towerEntry *donburi.Entry) {
+// AfterTowerAttack pays for the shot from the tower's magazine, or from its health when ammo isn't separate
+func AfterTowerAttack(towerEntry *donburi.Entry) error {
+	if towerEntry.HasComponent(Ammo) {
+		Ammo.Get(towerEntry).consume()
+		return nil
+	}
 	towerHealth := Health.Get(towerEntry)
 	towerHealth.Health--
 	if towerHealth.Health <= 0 {
+		MarkNavGridDirty(towerEntry.World)
+		MarkAurasDirty(towerEntry.World)
 		towerEntry.Remove()
 		GetGameStats().IncrementStat("TowersAmmoOut")
 	}
+	return nil
 }
 
 func OnKillCreep(towerEntry *donburi.Entry, enemyEntry *donburi.Entry) {
@@ -128,4 +229,10 @@ func OnKillCreep(towerEntry *donburi.Entry, enemyEntry *donburi.Entry) {
 	player := Player.Get(pe)
 	player.AddMoney(score)
 	player.AddScore(score)
+	if enemy.IsSplit() {
+		GetGameStats().UpdateStat("SplitScore", score)
+	}
+	if tower := creditedTower(towerEntry); tower != nil {
+		Veterancy.Get(tower).AddXP(tower, config.GetBalance(tower.World).Tower.Veterancy.KillXP)
+	}
 }
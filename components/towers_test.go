@@ -2,6 +2,7 @@ package components
 
 import (
 	"testing"
+	"tower-defense/config"
 	"tower-defense/util"
 
 	"github.com/yohamta/donburi"
@@ -120,3 +121,159 @@ func TestAfterTowerAttackConsumesAmmoAndRemovesEmptyTower(t *testing.T) {
 		t.Errorf("TowersAmmoOut = %v, want 1", got)
 	}
 }
+
+func TestTowerData_UpgradeUsesTowerTypeCurve(t *testing.T) {
+	entry := newTowerTestEntry(t, 20, 2)
+	tower := Tower.Get(entry)
+	tower.Type = "Sniper"
+
+	if !tower.Upgrade(entry, false) {
+		t.Fatal("Upgrade() = false, want true below max level")
+	}
+
+	health := Health.Get(entry)
+	if health.MaxHealth != 23 {
+		t.Errorf("max health after Sniper Upgrade() = %v, want 23", health.MaxHealth)
+	}
+	attack := Attack.Get(entry)
+	if attack.Power != 2 {
+		t.Errorf("attack power after Sniper Upgrade() = %v, want 2", attack.Power)
+	}
+	if attack.Range != 58 {
+		t.Errorf("attack range after Sniper Upgrade() = %v, want 58", attack.Range)
+	}
+	if attack.cooldown.Cooldown != 25 {
+		t.Errorf("attack cooldown after Sniper Upgrade() = %v, want 25", attack.cooldown.Cooldown)
+	}
+}
+
+func TestTowerData_UpgradeWithoutPowerDivisorKeepsPower(t *testing.T) {
+	entry := newTowerTestEntry(t, 20, 2)
+	tower := Tower.Get(entry)
+	tower.Type = "Wall"
+	Attack.Get(entry).Power = 0
+
+	if !tower.Upgrade(entry, false) {
+		t.Fatal("Upgrade() = false, want true below max level")
+	}
+	if got := Attack.Get(entry).Power; got != 0 {
+		t.Errorf("attack power after Wall Upgrade() = %v, want 0", got)
+	}
+	if got := Health.Get(entry).MaxHealth; got != 40 {
+		t.Errorf("max health after Wall Upgrade() = %v, want 40", got)
+	}
+}
+
+func TestPlayerData_TryHealTowerChargesTowerTypeCost(t *testing.T) {
+	tests := []struct {
+		name      string
+		towerType string
+		wantMoney int
+	}{
+		{"default type", "Ranged", 475},
+		{"sniper type", "Sniper", 463},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry := newTowerTestEntry(t, 0, 1)
+			Tower.Get(entry).Type = tt.towerType
+			player := Player.Get(Player.MustFirst(entry.World))
+
+			if !player.TryHealTower(entry, false, false) {
+				t.Fatal("TryHealTower() = false, want true for damaged tower")
+			}
+			if player.Money != tt.wantMoney {
+				t.Errorf("money after TryHealTower() = %v, want %v", player.Money, tt.wantMoney)
+			}
+		})
+	}
+}
+
+func TestPlayerData_SelectTowerType(t *testing.T) {
+	entry := newTowerTestEntry(t, 0, 1)
+	player := Player.Get(Player.MustFirst(entry.World))
+
+	if !player.SelectTowerType(entry.World, 2) {
+		t.Fatal("SelectTowerType(2) = false, want true")
+	}
+	if player.TowerType != "Sniper" {
+		t.Errorf("TowerType = %v, want Sniper", player.TowerType)
+	}
+	if player.SelectTowerType(entry.World, 10) {
+		t.Fatal("SelectTowerType(10) = true, want false for a missing type")
+	}
+	if player.TowerType != "Sniper" {
+		t.Errorf("TowerType after invalid select = %v, want Sniper", player.TowerType)
+	}
+}
+
+func TestPlayerData_SellTowerRefundsPlacementAndUpgrades(t *testing.T) {
+	entry := newTowerTestEntry(t, 20, 2)
+	world := entry.World
+	player := Player.Get(Player.MustFirst(world))
+	Tower.Set(entry, &TowerData{Type: "Ranged", Spent: 50})
+
+	if !player.TryUpgradeTower(entry, false, false) {
+		t.Fatal("TryUpgradeTower() = false, want true")
+	}
+	if got := Tower.Get(entry).Spent; got != 100 {
+		t.Fatalf("Spent after upgrade = %v, want 100", got)
+	}
+
+	if got := player.SellTower(entry, false); got != 60 {
+		t.Errorf("SellTower() = %v, want 60 refund", got)
+	}
+	if entry.Valid() {
+		t.Error("sold tower still valid")
+	}
+	if player.Money != 510 {
+		t.Errorf("money = %v, want 510", player.Money)
+	}
+	if got := GetGameStats().GetStat("TowersSold"); got != 1 {
+		t.Errorf("TowersSold = %v, want 1", got)
+	}
+}
+
+func TestPlayerData_TryMoveTower(t *testing.T) {
+	world := newNavTestWorld(t)
+	player := Player.Get(Player.MustFirst(world))
+	moveCost := config.GetBalance(world).Tower.MoveCost
+	tower := newNavTestTower(world, 20, 20)
+	newNavTestTower(world, 100, 100)
+
+	tests := []struct {
+		name    string
+		money   int
+		x, y    int
+		want    bool
+		wantPos PositionData
+	}{
+		{"too poor", moveCost - 1, 100, 60, false, PositionData{20, 20}},
+		{"onto another tower", moveCost, 110, 100, false, PositionData{20, 20}},
+		{"off the board", moveCost, 5, 60, false, PositionData{20, 20}},
+		{"open spot", moveCost, 100, 60, true, PositionData{80, 50}},
+		{"overlapping its old spot", moveCost, 105, 65, true, PositionData{85, 55}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			player.Money = tt.money
+			if got := player.TryMoveTower(tower, tt.x, tt.y, false, false); got != tt.want {
+				t.Fatalf("TryMoveTower() = %v, want %v", got, tt.want)
+			}
+			if pos := *Position.Get(tower); pos != tt.wantPos {
+				t.Errorf("tower position = %v, want %v", pos, tt.wantPos)
+			}
+			wantMoney := tt.money
+			if tt.want {
+				wantMoney -= moveCost
+			}
+			if player.Money != wantMoney {
+				t.Errorf("money = %v, want %v", player.Money, wantMoney)
+			}
+		})
+	}
+	if got := GetGameStats().GetStat("TowersMoved"); got != 2 {
+		t.Errorf("TowersMoved = %v, want 2", got)
+	}
+}
@@ -0,0 +1,77 @@
+package components
+
+import (
+	"fmt"
+	"image/color"
+
+	"tower-defense/config"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"github.com/yohamta/donburi"
+)
+
+// VeterancyData is the experience a tower has earned from damaging and killing creeps, and the veteran rank it reached with it.
+// Rank bonuses are separate from the levels the player pays for.
+type VeterancyData struct {
+	Rank int
+	XP   int
+}
+
+var Veterancy = donburi.NewComponentType[VeterancyData]()
+
+var chevronColor = color.RGBA{255, 200, 40, 255}
+
+// creditedTower finds the tower an attack came from, either the tower itself or the tower that fired the bullet
+func creditedTower(entry *donburi.Entry) *donburi.Entry {
+	if entry.HasComponent(Veterancy) {
+		return entry
+	}
+	if !entry.HasComponent(Bullet) {
+		return nil
+	}
+	source := Bullet.Get(entry).source
+	if !entry.World.Valid(source) {
+		// the tower was destroyed or sold while the bullet was in the air
+		return nil
+	}
+	tower := entry.World.Entry(source)
+	if !tower.HasComponent(Veterancy) {
+		return nil
+	}
+	return tower
+}
+
+// AddXP gives the tower experience, ranking it up through every threshold it passed and adding each rank's bonuses
+func (v *VeterancyData) AddXP(entry *donburi.Entry, xp int) {
+	if xp <= 0 {
+		return
+	}
+	v.XP += xp
+	ranks := config.GetBalance(entry.World).Tower.Veterancy.Ranks
+	for v.Rank < len(ranks) && v.XP >= ranks[v.Rank].XP {
+		rank := ranks[v.Rank]
+		v.Rank++
+		clearAuraBuff(entry)
+		attack := Attack.Get(entry)
+		attack.Power += rank.PowerBonus
+		attack.Range += rank.RangeBonus
+		attack.cooldown.Cooldown = max(1, attack.cooldown.Cooldown-rank.CooldownReduction)
+		GetGameStats().IncrementStat("VeteranRanks")
+		GetGameStats().IncrementStat(fmt.Sprintf("VeteranRank%d", v.Rank))
+	}
+}
+
+// drawChevrons stacks a chevron for each rank down from the top middle of the tower
+func (v *VeterancyData) drawChevrons(screen *ebiten.Image, entry *donburi.Entry) {
+	const width = 10
+	const height = 4
+	const gap = 3
+	rect := GetRect(entry)
+	x := float32(rect.Min.X+rect.Dx()/2) - width/2
+	for i := range v.Rank {
+		y := float32(rect.Min.Y) + 2 + float32(i)*(height+gap)
+		vector.StrokeLine(screen, x, y, x+width/2, y+height, 2, chevronColor, true)
+		vector.StrokeLine(screen, x+width/2, y+height, x+width, y, 2, chevronColor, true)
+	}
+}
@@ -0,0 +1,69 @@
+package components
+
+import (
+	"testing"
+
+	"github.com/yohamta/donburi"
+	"github.com/yohamta/donburi/filter"
+)
+
+func TestVeterancyData_RanksUpFromDamageAndKills(t *testing.T) {
+	world := newAttackTestWorld(t)
+	tower := newTestTower(t, world, 0, 0, "Ranged")
+	attack := Attack.Get(tower)
+
+	// the default ranks need 40, 120 and 300 xp, damage earns 1 xp a point and kills 5
+	attack.DamageEnemy(tower, newAttackTestCreep(world, 20, 0, 100), 30, OnKillCreep)
+	veterancy := Veterancy.Get(tower)
+	if veterancy.XP != 30 || veterancy.Rank != 0 {
+		t.Fatalf("veterancy = %+v, want 30 xp at rank 0", *veterancy)
+	}
+
+	// overkill doesn't count, only the 8 health the creep had left plus the kill
+	attack.DamageEnemy(tower, newAttackTestCreep(world, 20, 0, 8), 100, OnKillCreep)
+	if veterancy.XP != 43 || veterancy.Rank != 1 {
+		t.Fatalf("veterancy = %+v, want 43 xp at rank 1", *veterancy)
+	}
+	if attack.Range != 53 || attack.cooldown.Cooldown != 29 || attack.Power != 1 {
+		t.Errorf("attack = range %v cooldown %v power %v, want the rank 1 bonus of +3 range and -1 cooldown", attack.Range, attack.cooldown.Cooldown, attack.Power)
+	}
+	if got := Level.Get(tower).Level; got != 1 {
+		t.Errorf("level = %v, want ranks to leave the level alone", got)
+	}
+
+	// enough experience for several ranks at once gets all of their bonuses
+	veterancy.AddXP(tower, 1000)
+	if veterancy.Rank != 3 || attack.Power != 3 || attack.Range != 61 || attack.cooldown.Cooldown != 26 {
+		t.Errorf("after 1000 xp rank %v power %v range %v cooldown %v, want rank 3 power 3 range 61 cooldown 26", veterancy.Rank, attack.Power, attack.Range, attack.cooldown.Cooldown)
+	}
+	stats := GetGameStats()
+	if stats.GetStat("VeteranRanks") != 3 || stats.GetStat("VeteranRank1") != 1 || stats.GetStat("VeteranRank3") != 1 {
+		t.Errorf("VeteranRanks = %v, VeteranRank1 = %v, VeteranRank3 = %v, want 3, 1, 1", stats.GetStat("VeteranRanks"), stats.GetStat("VeteranRank1"), stats.GetStat("VeteranRank3"))
+	}
+}
+
+func TestVeterancyData_BulletsCreditTheirTower(t *testing.T) {
+	world := newAttackTestWorld(t)
+	tower := newTestTower(t, world, 0, 0, "Ranged")
+	creep := newAttackTestCreep(world, 20, 0, 100)
+
+	if err := Attack.Get(tower).LaunchBullet(tower, creep); err != nil {
+		t.Fatal(err)
+	}
+	bullet, ok := donburi.NewQuery(filter.Contains(Bullet)).First(world)
+	if !ok {
+		t.Fatal("LaunchBullet() fired no bullet")
+	}
+	bulletAttack := Attack.Get(bullet)
+	bulletAttack.DamageEnemy(bullet, creep, 10, OnKillCreep)
+	if got := Veterancy.Get(tower).XP; got != 10 {
+		t.Errorf("tower xp = %v, want 10 from its bullet", got)
+	}
+
+	// a bullet still in the air after its tower is gone credits nobody
+	tower.Remove()
+	bulletAttack.DamageEnemy(bullet, creep, 10, OnKillCreep)
+	if got := Health.Get(creep).Health; got != 80 {
+		t.Errorf("creep health = %v, want 80", got)
+	}
+}
@@ -0,0 +1,164 @@
+package components
+
+import (
+	"tower-defense/config"
+
+	"github.com/yohamta/donburi"
+)
+
+// WaveRunner plays a wave script, spawning the creeps of each wave on schedule instead of the random wave timer.
+// It only runs on the server.
+type WaveRunner struct {
+	script *config.WaveScriptData
+	// index of the wave that is waiting or spawning, and the number of times the script has repeated
+	index int
+	loop  int
+	// ticks left to wait before the wave starts
+	wait int
+	// ticks since the wave started
+	tick    int
+	pending []waveSpawn
+	started int
+	done    bool
+}
+
+type waveSpawn struct {
+	at         int
+	creepType  string
+	x          int
+	levelBonus int
+	// healthMultiplier is only set for bosses
+	healthMultiplier int
+}
+
+const defaultFormationSpacing = 60
+const defaultFormationInterval = 15
+
+func NewWaveRunner(script *config.WaveScriptData) *WaveRunner {
+	return &WaveRunner{script: script, wait: script.Waves[0].Delay}
+}
+
+// Update advances the script by a tick and spawns every creep that is due, returning how many were spawned
+func (w *WaveRunner) Update(world donburi.World, creepLevel int) (int, error) {
+	if w.done {
+		return 0, nil
+	}
+	if len(w.pending) == 0 {
+		if w.wait > 0 {
+			w.wait--
+			return 0, nil
+		}
+		w.startWave(world)
+	}
+
+	count := 0
+	remaining := make([]waveSpawn, 0, len(w.pending))
+	for _, spawn := range w.pending {
+		if spawn.at > w.tick {
+			remaining = append(remaining, spawn)
+			continue
+		}
+		if err := spawn.spawn(world, creepLevel); err != nil {
+			return count, err
+		}
+		count++
+	}
+	w.pending = remaining
+	w.tick++
+	if len(w.pending) == 0 {
+		w.nextWave()
+	}
+	return count, nil
+}
+
+// Done reports that the script has run out and the random wave timer should take over
+func (w *WaveRunner) Done() bool {
+	return w.done
+}
+
+// Wave is the number of waves started so far, counting repeats
+func (w *WaveRunner) Wave() int {
+	return w.started
+}
+
+func (w *WaveRunner) startWave(world donburi.World) {
+	GetGameStats().IncrementStat("CreepWaves")
+	w.started++
+	w.tick = 0
+
+	wave := w.script.Waves[w.index]
+	levelBonus := wave.LevelBonus + w.loop*w.script.Endless.LevelBonusPerLoop
+	board := Board.Get(Board.MustFirst(world))
+	border := config.GetBalance(world).Wave.SpawnBorder
+	spawnX := func(position, offset int) int {
+		x := board.Width*position/100 + offset
+		return min(max(x, border), board.Width-border)
+	}
+
+	for _, group := range wave.Groups {
+		spacing := group.Spacing
+		if spacing <= 0 {
+			spacing = defaultFormationSpacing
+		}
+		interval := group.Interval
+		if interval <= 0 && group.Formation != "line" && group.Formation != "random" {
+			interval = defaultFormationInterval
+		}
+		for i := range group.Count {
+			spawn := waveSpawn{at: group.Delay, creepType: group.Type, levelBonus: levelBonus}
+			switch group.Formation {
+			case "line":
+				spawn.x = spawnX(group.Position, (2*i-(group.Count-1))*spacing/2)
+			case "column":
+				spawn.x = spawnX(group.Position, 0)
+				spawn.at += i * interval
+			case "wedge":
+				// the point of the wedge leads, then each row is a pair spreading out behind it
+				row := (i + 1) / 2
+				side := 1
+				if i%2 == 1 {
+					side = -1
+				}
+				spawn.x = spawnX(group.Position, side*row*spacing)
+				spawn.at += row * interval
+			case "random":
+				spawn.x = spawnX(GetRandom(world).IntN(101), 0)
+				spawn.at += i * interval
+			}
+			w.pending = append(w.pending, spawn)
+		}
+	}
+	for _, boss := range wave.Bosses {
+		w.pending = append(w.pending, waveSpawn{
+			at:               boss.Delay,
+			creepType:        boss.Type,
+			x:                spawnX(boss.Position, 0),
+			levelBonus:       levelBonus,
+			healthMultiplier: boss.HealthMultiplier,
+		})
+	}
+}
+
+func (w *WaveRunner) nextWave() {
+	w.index++
+	if w.index >= len(w.script.Waves) {
+		if w.script.Endless.Mode == "random" {
+			w.done = true
+			return
+		}
+		w.index = w.script.Endless.RepeatFrom
+		w.loop++
+	}
+	w.wait = w.script.Waves[w.index].Delay
+}
+
+func (s waveSpawn) spawn(world donburi.World, creepLevel int) error {
+	y := config.GetBalance(world).Wave.SpawnBorder
+	var err error
+	if s.healthMultiplier > 0 {
+		_, err = NewBoss(world, s.x, y, s.creepType, creepLevel+s.levelBonus, s.healthMultiplier)
+	} else {
+		_, err = NewCreepOfType(world, s.x, y, s.creepType, creepLevel+s.levelBonus)
+	}
+	return err
+}
@@ -0,0 +1,116 @@
+package components
+
+import (
+	"slices"
+	"testing"
+
+	"tower-defense/config"
+
+	"github.com/yohamta/donburi"
+	"github.com/yohamta/donburi/filter"
+)
+
+// newWaveTestWorld is the attack test world with a board, for anything that moves or spawns on it
+func newWaveTestWorld(t *testing.T) donburi.World {
+	t.Helper()
+
+	world := newAttackTestWorld(t)
+	if _, err := NewBoard(world, 600, 800); err != nil {
+		t.Fatal(err)
+	}
+	return world
+}
+
+func creepXs(world donburi.World) []int {
+	xs := make([]int, 0)
+	donburi.NewQuery(filter.Contains(Creep)).Each(world, func(entry *donburi.Entry) {
+		xs = append(xs, Position.Get(entry).X)
+	})
+	slices.Sort(xs)
+	return xs
+}
+
+func TestWaveRunner_SpawnsFormationsOnSchedule(t *testing.T) {
+	world := newWaveTestWorld(t)
+	script := &config.WaveScriptData{
+		Waves: []config.ScriptedWave{
+			{Delay: 2, Groups: []config.WaveGroup{{Type: "Grunt", Count: 3, Formation: "line", Position: 50, Spacing: 100}}},
+			{Delay: 1, Groups: []config.WaveGroup{{Type: "Scout", Count: 3, Formation: "wedge", Position: 50, Spacing: 100, Interval: 2}}},
+		},
+		Endless: config.EndlessWaves{Mode: "random"},
+	}
+	runner := NewWaveRunner(script)
+
+	update := func() int {
+		t.Helper()
+		count, err := runner.Update(world, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return count
+	}
+	// the first wave waits out its delay then spawns the whole line at once
+	if update()+update() != 0 {
+		t.Fatal("creeps spawned during the first wave's delay")
+	}
+	if got := update(); got != 3 {
+		t.Fatalf("line spawned %v creeps, want 3", got)
+	}
+	if got := creepXs(world); !slices.Equal(got, []int{200, 300, 400}) {
+		t.Errorf("line creeps at x %v, want 200 300 400", got)
+	}
+	if runner.Wave() != 1 {
+		t.Errorf("Wave() = %v, want 1", runner.Wave())
+	}
+
+	// the wedge point leads and the pair behind it spawns a row interval later
+	update()
+	if got := update(); got != 1 {
+		t.Fatalf("wedge point spawned %v creeps, want 1", got)
+	}
+	update()
+	if got := update(); got != 2 {
+		t.Fatalf("wedge row spawned %v creeps, want 2", got)
+	}
+	if !runner.Done() {
+		t.Error("runner not done after the last wave with random endless mode")
+	}
+	if got := GetGameStats().GetStat("CreepWaves"); got != 2 {
+		t.Errorf("CreepWaves = %v, want 2", got)
+	}
+}
+
+func TestWaveRunner_RepeatsWithLevelBonusAndSpawnsBosses(t *testing.T) {
+	world := newWaveTestWorld(t)
+	script := &config.WaveScriptData{
+		Waves: []config.ScriptedWave{
+			{Groups: []config.WaveGroup{{Type: "Grunt", Count: 1, Formation: "column", Position: 0}}},
+			{Bosses: []config.WaveBoss{{Type: "Brute", Position: 100, HealthMultiplier: 4}}},
+		},
+		Endless: config.EndlessWaves{Mode: "repeat", RepeatFrom: 1, LevelBonusPerLoop: 3},
+	}
+	runner := NewWaveRunner(script)
+	for range 3 {
+		if _, err := runner.Update(world, 1); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if runner.Done() || runner.Wave() != 3 {
+		t.Fatalf("Done() = %v Wave() = %v, want still running on wave 3", runner.Done(), runner.Wave())
+	}
+
+	bosses := make([]int, 0)
+	donburi.NewQuery(filter.Contains(Creep)).Each(world, func(entry *donburi.Entry) {
+		if entry.HasComponent(Boss) {
+			bosses = append(bosses, Health.Get(entry).MaxHealth)
+		}
+	})
+	// brute health is 5 + level/3, the repeat adds 3 levels
+	slices.Sort(bosses)
+	if !slices.Equal(bosses, []int{20, 24}) {
+		t.Errorf("boss health %v, want 20 then 24 after the level bonus", bosses)
+	}
+	if got := GetGameStats().GetStat("BossesSpawned"); got != 2 {
+		t.Errorf("BossesSpawned = %v, want 2", got)
+	}
+}
@@ -3,79 +3,326 @@ package config
 import (
 	"embed"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
+	"slices"
+	"strings"
 
 	"github.com/yohamta/donburi"
 )
 
-//go:embed default_balance.json
+//go:embed default_balance.json default_waves.json
 var balanceFS embed.FS
 
 type BalanceData struct {
-	Player      PlayerBalance      `json:"player"`
-	Tower       TowerBalance       `json:"tower"`
-	Creep       CreepBalance       `json:"creep"`
-	SuperCreep  SuperCreepBalance  `json:"superCreep"`
-	Wave        WaveBalance        `json:"wave"`
-	Multiplayer MultiplayerBalance `json:"multiplayer"`
+	Player      PlayerBalance            `json:"player"`
+	Tower       TowerBalance             `json:"tower"`
+	Creep       CreepBalance             `json:"creep"`
+	Boss        BossBalance              `json:"boss"`
+	Wave        WaveBalance              `json:"wave"`
+	Multiplayer MultiplayerBalance       `json:"multiplayer"`
+	Effects     map[string]EffectBalance `json:"effects"`
+	Navigation  NavigationBalance        `json:"navigation"`
 }
 
 type PlayerBalance struct {
-	StartingMoney          int `json:"startingMoney"`
-	Health                 int `json:"health"`
-	AttackPower            int `json:"attackPower"`
-	AttackRange            int `json:"attackRange"`
-	AttackCooldown         int `json:"attackCooldown"`
-	CreepLevelTowerLevels  int `json:"creepLevelTowerLevels"`
-	MaxTowerInitialLevel   int `json:"maxTowerInitialLevel"`
-	MaxTowerLevelsPerBonus int `json:"maxTowerLevelsPerBonus"`
+	StartingMoney          int              `json:"startingMoney"`
+	Health                 int              `json:"health"`
+	AttackPower            int              `json:"attackPower"`
+	AttackRange            int              `json:"attackRange"`
+	AttackCooldown         int              `json:"attackCooldown"`
+	CreepLevelTowerLevels  int              `json:"creepLevelTowerLevels"`
+	MaxTowerInitialLevel   int              `json:"maxTowerInitialLevel"`
+	MaxTowerLevelsPerBonus int              `json:"maxTowerLevelsPerBonus"`
+	Abilities              AbilitiesBalance `json:"abilities"`
+}
+
+// AbilitiesBalance is the active abilities of the base, each costs money to use and has a cooldown in game ticks before it can be used again
+type AbilitiesBalance struct {
+	Airstrike AirstrikeBalance `json:"airstrike"`
+	Freeze    FreezeBalance    `json:"freeze"`
+	Repair    RepairBalance    `json:"repair"`
+}
+
+// AirstrikeBalance is a blast on the cursor that damages every creep within the radius, falling off towards the edge
+type AirstrikeBalance struct {
+	Cost       int     `json:"cost"`
+	Cooldown   int     `json:"cooldown"`
+	Power      int     `json:"power"`
+	Radius     int     `json:"radius"`
+	Falloff    float64 `json:"falloff"`
+	DamageType string  `json:"damageType"`
+}
+
+// FreezeBalance stuns every creep on the board for the duration
+type FreezeBalance struct {
+	Cost     int `json:"cost"`
+	Cooldown int `json:"cooldown"`
+	Duration int `json:"duration"`
+}
+
+// RepairBalance heals every tower by the percent of its max health
+type RepairBalance struct {
+	Cost        int `json:"cost"`
+	Cooldown    int `json:"cooldown"`
+	HealPercent int `json:"healPercent"`
 }
 
 type TowerBalance struct {
-	DefaultType              string         `json:"defaultType"`
-	Costs                    map[string]int `json:"costs"`
-	HealCostDivisor          int            `json:"healCostDivisor"`
-	Health                   int            `json:"health"`
-	AttackPower              int            `json:"attackPower"`
-	AttackRange              int            `json:"attackRange"`
-	AttackCooldown           int            `json:"attackCooldown"`
-	InitialLevel             int            `json:"initialLevel"`
-	UpgradeMaxHealthAdd      int            `json:"upgradeMaxHealthAdd"`
-	UpgradePowerLevelDivisor int            `json:"upgradePowerLevelDivisor"`
-	UpgradeRangeAdd          int            `json:"upgradeRangeAdd"`
-	UpgradeCooldownReduction int            `json:"upgradeCooldownReduction"`
-	UpgradeMinCooldown       int            `json:"upgradeMinCooldown"`
+	DefaultType     string         `json:"defaultType"`
+	Costs           map[string]int `json:"costs"`
+	HealCostDivisor int            `json:"healCostDivisor"`
+	InitialLevel    int            `json:"initialLevel"`
+	// SellRefundPercent of the money spent placing and upgrading a tower is refunded when it is sold
+	SellRefundPercent int                `json:"sellRefundPercent"`
+	MoveCost          int                `json:"moveCost"`
+	Types             []TowerTypeBalance `json:"types"`
+	Veterancy         VeterancyBalance   `json:"veterancy"`
+	Ammo              AmmoBalance        `json:"ammo"`
+	// Branches are the upgrade choices offered to tower types that don't define their own
+	Branches []UpgradeBranchBalance `json:"branches"`
+	Auras    AuraRulesBalance       `json:"auras"`
+}
+
+// AuraRulesBalance is how the auras of several support towers combine on a tower in range of them all
+type AuraRulesBalance struct {
+	// Stacking is "highest" to take the best bonus of each kind, or "stack" to add them up, empty means highest
+	Stacking string `json:"stacking"`
+	// MaxStacks caps how many auras add up when stacking, 0 means no cap
+	MaxStacks int `json:"maxStacks"`
 }
 
+// AuraBalance is the buff a support tower gives every other firing tower within its attack range.
+// Towers with an aura never fire, their range upgrades widen the aura instead.
+type AuraBalance struct {
+	PowerAdd          int `json:"powerAdd"`
+	RangeAdd          int `json:"rangeAdd"`
+	CooldownReduction int `json:"cooldownReduction"`
+}
+
+// Active reports whether the aura gives any bonus
+func (a AuraBalance) Active() bool {
+	return a.PowerAdd > 0 || a.RangeAdd > 0 || a.CooldownReduction > 0
+}
+
+// UpgradeBranchBalance is a choice between upgrade paths the player makes when a tower is upgraded to Level
+type UpgradeBranchBalance struct {
+	Level   int                    `json:"level"`
+	Options []UpgradeOptionBalance `json:"options"`
+}
+
+// UpgradeOptionBalance is one upgrade path, its bonuses are added on top of the normal upgrade for the level.
+// An area radius turns single target towers into area towers.
+type UpgradeOptionBalance struct {
+	Name string `json:"name"`
+	// Label is the short name drawn on towers that took this path
+	Label             string  `json:"label"`
+	PowerAdd          int     `json:"powerAdd"`
+	RangeAdd          int     `json:"rangeAdd"`
+	CooldownReduction int     `json:"cooldownReduction"`
+	MaxHealthAdd      int     `json:"maxHealthAdd"`
+	AreaRadius        int     `json:"areaRadius"`
+	AreaFalloff       float64 `json:"areaFalloff"`
+}
+
+// AmmoBalance is how towers pay for their shots. By default every shot costs a point of health and a tower that runs out is removed,
+// with SeparateAmmo towers fire from a magazine that reloads and only creep attacks lower their health.
+type AmmoBalance struct {
+	SeparateAmmo bool `json:"separateAmmo"`
+	// ReloadInterval game ticks to passively reload ReloadAmount rounds into a magazine that isn't full, 0 never reloads
+	ReloadInterval int `json:"reloadInterval"`
+	ReloadAmount   int `json:"reloadAmount"`
+	// ResupplyCostDivisor divides the tower cost for the price of refilling its magazine
+	ResupplyCostDivisor int `json:"resupplyCostDivisor"`
+}
+
+// VeterancyBalance is how towers earn experience from fighting and the ranks they reach with it
+type VeterancyBalance struct {
+	// DamageXP is earned for every point of damage a tower does to creeps, KillXP for every creep it kills
+	DamageXP int `json:"damageXP"`
+	KillXP   int `json:"killXP"`
+	// Ranks are in order of the experience needed to reach them
+	Ranks []VeteranRankBalance `json:"ranks"`
+}
+
+// VeteranRankBalance is the experience a tower needs for a rank and the bonuses it gets on reaching it, on top of its upgrades
+type VeteranRankBalance struct {
+	XP                int `json:"xp"`
+	PowerBonus        int `json:"powerBonus"`
+	RangeBonus        int `json:"rangeBonus"`
+	CooldownReduction int `json:"cooldownReduction"`
+}
+
+// TowerTypeBalance describes a single tower archetype, its cost is looked up by Name in TowerBalance.Costs
+type TowerTypeBalance struct {
+	Name           string              `json:"name"`
+	Sprite         string              `json:"sprite"`
+	Health         int                 `json:"health"`
+	AttackPower    int                 `json:"attackPower"`
+	AttackRange    int                 `json:"attackRange"`
+	AttackCooldown int                 `json:"attackCooldown"`
+	AttackType     string              `json:"attackType"`
+	AreaRadius     int                 `json:"areaRadius"`
+	AreaFalloff    float64             `json:"areaFalloff"`
+	Effect         AttackEffectBalance `json:"effect"`
+	Hazard         HazardBalance       `json:"hazard"`
+	Targeting      string              `json:"targeting"`
+	// DamageType is Kinetic, Explosive or Energy, empty means Kinetic, hazards left by the tower do the same type
+	DamageType string `json:"damageType"`
+	// AntiAir towers can also shoot flying creeps, which every other tower ignores
+	AntiAir bool `json:"antiAir"`
+	// Projectile is Bullet, Homing, Piercing or Beam for ranged towers, empty means Bullet. Piercing rounds pass through up to Pierce creeps.
+	Projectile string `json:"projectile"`
+	Pierce     int    `json:"pierce"`
+	// Branches replace the tower wide upgrade choices for this type when set, an empty list means the type has none
+	Branches []UpgradeBranchBalance `json:"branches"`
+	// Aura makes this a support tower that buffs the towers around it
+	Aura AuraBalance `json:"aura"`
+	// Ammo is the magazine size when ammo is separate from health
+	Ammo                     int `json:"ammo"`
+	UpgradeAmmoAdd           int `json:"upgradeAmmoAdd"`
+	UpgradeMaxHealthAdd      int `json:"upgradeMaxHealthAdd"`
+	UpgradePowerLevelDivisor int `json:"upgradePowerLevelDivisor"`
+	UpgradeRangeAdd          int `json:"upgradeRangeAdd"`
+	UpgradeCooldownReduction int `json:"upgradeCooldownReduction"`
+	UpgradeMinCooldown       int `json:"upgradeMinCooldown"`
+}
+
+// AttackEffectBalance is a status effect applied by an attack on hit, an empty Kind means no effect
+type AttackEffectBalance struct {
+	Kind     string `json:"kind"`
+	Strength int    `json:"strength"`
+	Duration int    `json:"duration"`
+}
+
+// HazardBalance is a ground hazard left by a tower's bullets, an empty Kind means no hazard
+type HazardBalance struct {
+	Kind string `json:"kind"`
+	// SpawnOn is "expire" to spawn where a bullet misses and runs out of range, "hit" to spawn where it hits, or "both"
+	SpawnOn      string              `json:"spawnOn"`
+	Size         int                 `json:"size"`
+	Duration     int                 `json:"duration"`
+	Power        int                 `json:"power"`
+	TickInterval int                 `json:"tickInterval"`
+	Charges      int                 `json:"charges"`
+	AreaRadius   int                 `json:"areaRadius"`
+	Effect       AttackEffectBalance `json:"effect"`
+}
+
+// EffectBalance holds the rules for a status effect kind when it is applied more than once
+type EffectBalance struct {
+	// Stacking is "refresh" to reset the duration, "stack" to add a stack and reset the duration, or "extend" to add to the duration
+	Stacking     string `json:"stacking"`
+	MaxStacks    int    `json:"maxStacks"`
+	MaxStrength  int    `json:"maxStrength"`
+	MaxDuration  int    `json:"maxDuration"`
+	TickInterval int    `json:"tickInterval"`
+}
+
+// CreepBalance holds the creep archetypes, waves spawn them at random by their SpawnWeight
 type CreepBalance struct {
-	BigCreepChance                  float32 `json:"bigCreepChance"`
-	BigCreepChoice                  int     `json:"bigCreepChoice"`
-	BigCreepAugment                 int     `json:"bigCreepAugment"`
-	SmallCreepFirstChoice           int     `json:"smallCreepFirstChoice"`
-	SmallCreepVariants              int     `json:"smallCreepVariants"`
-	BaseVelocityY                   int     `json:"baseVelocityY"`
-	HealthBase                      int     `json:"healthBase"`
-	HealthAugmentMultiplier         int     `json:"healthAugmentMultiplier"`
-	HealthLevelDivisor              int     `json:"healthLevelDivisor"`
-	AttackPowerBase                 int     `json:"attackPowerBase"`
-	AttackPowerLevelOffset          int     `json:"attackPowerLevelOffset"`
-	AttackPowerLevelDivisor         int     `json:"attackPowerLevelDivisor"`
-	AttackRangeBase                 int     `json:"attackRangeBase"`
-	AttackRangeAugmentMultiplier    int     `json:"attackRangeAugmentMultiplier"`
-	AttackCooldownBase              int     `json:"attackCooldownBase"`
-	AttackCooldownAugmentMultiplier int     `json:"attackCooldownAugmentMultiplier"`
-	ScoreValueBase                  int     `json:"scoreValueBase"`
-}
-
-type SuperCreepBalance struct {
-	VelocityX      int `json:"velocityX"`
-	VelocityY      int `json:"velocityY"`
-	ScoreValue     int `json:"scoreValue"`
-	Health         int `json:"health"`
-	AttackPower    int `json:"attackPower"`
-	AttackRange    int `json:"attackRange"`
-	AttackCooldown int `json:"attackCooldown"`
+	Types []CreepTypeBalance `json:"types"`
+}
+
+// CreepTypeBalance describes a single creep archetype, new creeps can be added with only a balance entry
+type CreepTypeBalance struct {
+	Name      string `json:"name"`
+	Sprite    string `json:"sprite"`
+	VelocityX int    `json:"velocityX"`
+	// Speed is how far down the board the creep moves each tick
+	Speed          LevelFormula `json:"speed"`
+	Health         LevelFormula `json:"health"`
+	AttackPower    LevelFormula `json:"attackPower"`
+	AttackRange    int          `json:"attackRange"`
+	AttackCooldown int          `json:"attackCooldown"`
+	// AttackType is MeleeSingle or RangedSingle like the tower types, empty means RangedSingle
+	AttackType string `json:"attackType"`
+	ScoreValue int    `json:"scoreValue"`
+	// SpawnWeight is the relative chance of a wave spawning this type at a creep level, zero or less never spawns
+	SpawnWeight LevelFormula `json:"spawnWeight"`
+	// PathAround creeps follow the nav grid around towers, otherwise they walk straight down and attack through them
+	PathAround bool `json:"pathAround"`
+	// Flying creeps fly straight over towers and other creeps, only anti-air towers and the base can hit them
+	Flying    bool                   `json:"flying"`
+	Behaviors []CreepBehaviorBalance `json:"behaviors"`
+	// Armor is taken off every hit after resistances, a hit always does at least 1 damage
+	Armor int `json:"armor"`
+	// Resistances are the percent less damage taken from each damage type by name, negative for a weakness
+	Resistances map[string]int `json:"resistances"`
+	// Split creeps spawn at the creep's death position at the same creep level
+	Split []CreepSplitBalance `json:"split"`
+}
+
+// CreepSplitBalance is a number of creeps of one type that a creep splits into when it dies
+type CreepSplitBalance struct {
+	Type  string `json:"type"`
+	Count int    `json:"count"`
+}
+
+// CreepBehaviorBalance is a support ability a creep uses on other creeps every Interval ticks
+type CreepBehaviorBalance struct {
+	// Kind is "Heal" to restore health, "Shield" to give shield points that absorb damage, or "Summon" to spawn minions
+	Kind string `json:"kind"`
+	// Range is how far from the creep's edges allies are helped
+	Range    int `json:"range"`
+	Interval int `json:"interval"`
+	// Power is the health restored or shield given to each ally in range
+	Power     int `json:"power"`
+	MaxShield int `json:"maxShield"`
+	// MinionCount creeps of MinionType spawn beside the creep, up to MaxMinions alive at once, an empty type picks random types
+	MinionType  string `json:"minionType"`
+	MinionCount int    `json:"minionCount"`
+	MaxMinions  int    `json:"maxMinions"`
+}
+
+// BossBalance sets how often random waves bring a boss and the phases every boss goes through as it takes damage
+type BossBalance struct {
+	// WaveInterval adds a boss to every nth random wave, zero for no boss waves
+	WaveInterval     int                `json:"waveInterval"`
+	Type             string             `json:"type"`
+	HealthMultiplier int                `json:"healthMultiplier"`
+	Phases           []BossPhaseBalance `json:"phases"`
+}
+
+// BossPhaseBalance starts once a boss's health drops to HealthPercent of its max, phases go from highest to lowest percent
+type BossPhaseBalance struct {
+	HealthPercent int `json:"healthPercent"`
+	SpeedBonus    int `json:"speedBonus"`
+	// MinionCount creeps of MinionType spawn beside the boss when the phase starts and then every MinionInterval ticks,
+	// an empty type picks random types like a wave
+	MinionType     string            `json:"minionType"`
+	MinionCount    int               `json:"minionCount"`
+	MinionInterval int               `json:"minionInterval"`
+	SpreadShot     SpreadShotBalance `json:"spreadShot"`
+}
+
+// SpreadShotBalance fires a fan of Bullets spread across Angle degrees at the closest tower or base every Interval ticks
+type SpreadShotBalance struct {
+	Bullets  int `json:"bullets"`
+	Angle    int `json:"angle"`
+	Interval int `json:"interval"`
+}
+
+// LevelFormula scales a value with the creep level as Base + (level - LevelOffset) * LevelMultiplier / LevelDivisor,
+// a zero LevelDivisor means the value doesn't scale
+type LevelFormula struct {
+	Base            int `json:"base"`
+	LevelOffset     int `json:"levelOffset"`
+	LevelMultiplier int `json:"levelMultiplier"`
+	LevelDivisor    int `json:"levelDivisor"`
+}
+
+func (f LevelFormula) At(level int) int {
+	if f.LevelDivisor == 0 {
+		return f.Base
+	}
+	return f.Base + (level-f.LevelOffset)*f.LevelMultiplier/f.LevelDivisor
+}
+
+// NavigationBalance sizes the grid that creeps path around towers on
+type NavigationBalance struct {
+	CellSize int `json:"cellSize"`
 }
 
 type WaveBalance struct {
@@ -100,9 +347,81 @@ type CreepSpawnChance struct {
 type MultiplayerBalance struct {
 	SuperCreepCost     int `json:"superCreepCost"`
 	SuperCreepCooldown int `json:"superCreepCooldown"`
+	// SuperCreepType is the creep type sent to the other players
+	SuperCreepType string `json:"superCreepType"`
 }
 
 var Balance = donburi.NewComponentType[BalanceData]()
+
+// attackTypes are the attack type names understood by the components package, empty means RangedSingle
+var attackTypes = []string{"", "MeleeSingle", "RangedSingle", "MeleeArea", "RangedArea"}
+
+// creepAttackTypes are the attack types creeps can use, area attacks are tower only
+var creepAttackTypes = []string{"", "MeleeSingle", "RangedSingle"}
+
+// damageTypes are the damage type names understood by the components package, empty means Kinetic
+var damageTypes = []string{"", "Kinetic", "Explosive", "Energy"}
+
+var creepBehaviorKinds = []string{"Heal", "Shield", "Summon"}
+
+// effectKinds and effectStacking are the status effect names understood by the components package
+var effectKinds = []string{"Slow", "Poison", "Stun", "ArmorShred"}
+var effectStacking = []string{"refresh", "stack", "extend"}
+
+// auraStacking are the ways auras combine, empty means highest
+var auraStacking = []string{"", "highest", "stack"}
+
+// projectileKinds are the projectile names understood by the components package, empty means Bullet
+var projectileKinds = []string{"", "Bullet", "Homing", "Piercing", "Beam"}
+
+var targetPriorities = []string{"", "Closest", "Furthest", "LowestHealth", "HighestHealth", "StrongestAttack", "SuperFirst"}
+
+var hazardKinds = []string{"TarPit", "FirePatch", "MineField"}
+var hazardSpawnOn = []string{"expire", "hit", "both"}
+
+// GetType returns the named tower type, falling back to the default type for unknown names
+func (t *TowerBalance) GetType(name string) *TowerTypeBalance {
+	for i := range t.Types {
+		if t.Types[i].Name == name {
+			return &t.Types[i]
+		}
+	}
+	if name != t.DefaultType {
+		return t.GetType(t.DefaultType)
+	}
+	if len(t.Types) > 0 {
+		return &t.Types[0]
+	}
+	return &TowerTypeBalance{Name: name}
+}
+
+// GetBranches returns the upgrade choices for the named tower type, its own or the tower wide ones
+func (t *TowerBalance) GetBranches(name string) []UpgradeBranchBalance {
+	if branches := t.GetType(name).Branches; branches != nil {
+		return branches
+	}
+	return t.Branches
+}
+
+// TypeNames returns the tower type names in the order they are defined
+func (t *TowerBalance) TypeNames() []string {
+	names := make([]string, len(t.Types))
+	for i, towerType := range t.Types {
+		names[i] = towerType.Name
+	}
+	return names
+}
+
+// GetType returns the named creep type, nil when it isn't defined
+func (c *CreepBalance) GetType(name string) *CreepTypeBalance {
+	for i := range c.Types {
+		if c.Types[i].Name == name {
+			return &c.Types[i]
+		}
+	}
+	return nil
+}
+
 var defaultBalance = mustLoadDefaultBalance()
 
 func LoadBalance(path string) (*BalanceData, error) {
@@ -161,5 +480,346 @@ func parseBalanceFile(name string, bytes []byte) (*BalanceData, error) {
 	if err := json.Unmarshal(bytes, &balance); err != nil {
 		return nil, fmt.Errorf("parse %s: %w", name, err)
 	}
+	if err := balance.validate(); err != nil {
+		return nil, fmt.Errorf("validate %s: %w", name, err)
+	}
 	return &balance, nil
 }
+
+func (b *BalanceData) validate() error {
+	if len(b.Tower.Types) == 0 {
+		return errors.New("no tower types defined")
+	}
+	found := false
+	for _, towerType := range b.Tower.Types {
+		if _, ok := b.Tower.Costs[towerType.Name]; !ok {
+			return fmt.Errorf("tower type %q has no cost", towerType.Name)
+		}
+		if len(towerType.Sprite) == 0 {
+			return fmt.Errorf("tower type %q has no sprite", towerType.Name)
+		}
+		if !slices.Contains(attackTypes, towerType.AttackType) {
+			return fmt.Errorf("tower type %q has unknown attack type %q", towerType.Name, towerType.AttackType)
+		}
+		if towerType.AreaFalloff < 0 || towerType.AreaFalloff > 1 {
+			return fmt.Errorf("tower type %q area falloff %v must be between 0 and 1", towerType.Name, towerType.AreaFalloff)
+		}
+		if err := b.validateAttackEffect(towerType.Effect); err != nil {
+			return fmt.Errorf("tower type %q: %w", towerType.Name, err)
+		}
+		if !slices.Contains(damageTypes, towerType.DamageType) {
+			return fmt.Errorf("tower type %q has unknown damage type %q", towerType.Name, towerType.DamageType)
+		}
+		if !slices.Contains(targetPriorities, towerType.Targeting) {
+			return fmt.Errorf("tower type %q has unknown targeting %q", towerType.Name, towerType.Targeting)
+		}
+		if err := b.validateHazard(towerType.Hazard); err != nil {
+			return fmt.Errorf("tower type %q: %w", towerType.Name, err)
+		}
+		if err := towerType.validateProjectile(); err != nil {
+			return fmt.Errorf("tower type %q: %w", towerType.Name, err)
+		}
+		if err := b.Tower.validateBranches(towerType.Branches); err != nil {
+			return fmt.Errorf("tower type %q: %w", towerType.Name, err)
+		}
+		found = found || towerType.Name == b.Tower.DefaultType
+	}
+	if !found {
+		return fmt.Errorf("default tower type %q is not defined", b.Tower.DefaultType)
+	}
+	if b.Tower.SellRefundPercent < 0 || b.Tower.SellRefundPercent > 100 {
+		return fmt.Errorf("tower sell refund percent %v must be between 0 and 100", b.Tower.SellRefundPercent)
+	}
+	if err := b.Tower.validateBranches(b.Tower.Branches); err != nil {
+		return err
+	}
+	if err := b.Tower.validateAmmo(); err != nil {
+		return err
+	}
+	if err := b.Tower.validateAuras(); err != nil {
+		return err
+	}
+	if err := b.Player.Abilities.validate(); err != nil {
+		return err
+	}
+	lastXP := 0
+	for i, rank := range b.Tower.Veterancy.Ranks {
+		if rank.XP <= lastXP {
+			return fmt.Errorf("veteran rank %v xp %v must be more than the rank before", i+1, rank.XP)
+		}
+		lastXP = rank.XP
+	}
+	for kind, effect := range b.Effects {
+		if !slices.Contains(effectKinds, kind) {
+			return fmt.Errorf("unknown effect kind %q", kind)
+		}
+		if !slices.Contains(effectStacking, effect.Stacking) {
+			return fmt.Errorf("effect %q has unknown stacking rule %q", kind, effect.Stacking)
+		}
+	}
+	return b.validateCreeps()
+}
+
+func (t *TowerBalance) validateBranches(branches []UpgradeBranchBalance) error {
+	lastLevel := t.InitialLevel
+	for _, branch := range branches {
+		if branch.Level <= lastLevel {
+			return fmt.Errorf("upgrade branch level %v must be more than the initial level and the branch before", branch.Level)
+		}
+		lastLevel = branch.Level
+		if len(branch.Options) == 0 {
+			return fmt.Errorf("upgrade branch at level %v has no options", branch.Level)
+		}
+		names := make([]string, 0, len(branch.Options))
+		for _, option := range branch.Options {
+			if len(option.Name) == 0 || slices.Contains(names, option.Name) {
+				return fmt.Errorf("upgrade branch at level %v option name %q must be set and unique", branch.Level, option.Name)
+			}
+			names = append(names, option.Name)
+			if option.PowerAdd < 0 || option.RangeAdd < 0 || option.CooldownReduction < 0 || option.MaxHealthAdd < 0 || option.AreaRadius < 0 {
+				return fmt.Errorf("upgrade option %q bonuses must not be negative", option.Name)
+			}
+			if option.AreaFalloff < 0 || option.AreaFalloff > 1 {
+				return fmt.Errorf("upgrade option %q area falloff %v must be between 0 and 1", option.Name, option.AreaFalloff)
+			}
+		}
+	}
+	return nil
+}
+
+func (t *TowerTypeBalance) validateProjectile() error {
+	if !slices.Contains(projectileKinds, t.Projectile) {
+		return fmt.Errorf("unknown projectile %q", t.Projectile)
+	}
+	if t.Projectile != "" && t.Projectile != "Bullet" && (t.AttackType == "MeleeSingle" || t.AttackType == "MeleeArea") {
+		return fmt.Errorf("melee attack type %q can't use projectile %q", t.AttackType, t.Projectile)
+	}
+	if t.Pierce < 0 || (t.Projectile == "Piercing" && t.Pierce == 0) {
+		return fmt.Errorf("pierce %v must be positive for piercing rounds", t.Pierce)
+	}
+	return nil
+}
+
+func (t *TowerBalance) validateAmmo() error {
+	if t.Ammo.ReloadInterval < 0 || t.Ammo.ReloadAmount < 0 {
+		return fmt.Errorf("ammo reload interval %v and amount %v must not be negative", t.Ammo.ReloadInterval, t.Ammo.ReloadAmount)
+	}
+	if !t.Ammo.SeparateAmmo {
+		return nil
+	}
+	if t.Ammo.ResupplyCostDivisor <= 0 {
+		return fmt.Errorf("ammo resupply cost divisor %v must be positive", t.Ammo.ResupplyCostDivisor)
+	}
+	for _, towerType := range t.Types {
+		if towerType.AttackPower > 0 && towerType.Ammo <= 0 {
+			return fmt.Errorf("tower type %q ammo %v must be positive when ammo is separate", towerType.Name, towerType.Ammo)
+		}
+	}
+	return nil
+}
+
+func (t *TowerBalance) validateAuras() error {
+	if !slices.Contains(auraStacking, t.Auras.Stacking) {
+		return fmt.Errorf("unknown aura stacking rule %q", t.Auras.Stacking)
+	}
+	if t.Auras.MaxStacks < 0 {
+		return fmt.Errorf("aura max stacks %v must not be negative", t.Auras.MaxStacks)
+	}
+	for _, towerType := range t.Types {
+		aura := towerType.Aura
+		if aura.PowerAdd < 0 || aura.RangeAdd < 0 || aura.CooldownReduction < 0 {
+			return fmt.Errorf("tower type %q aura bonuses must not be negative", towerType.Name)
+		}
+		if aura.Active() && towerType.AttackPower > 0 {
+			return fmt.Errorf("tower type %q with an aura must have no attack power", towerType.Name)
+		}
+	}
+	return nil
+}
+
+func (a *AbilitiesBalance) validate() error {
+	costs := []struct {
+		name           string
+		cost, cooldown int
+	}{
+		{"airstrike", a.Airstrike.Cost, a.Airstrike.Cooldown},
+		{"freeze", a.Freeze.Cost, a.Freeze.Cooldown},
+		{"repair", a.Repair.Cost, a.Repair.Cooldown},
+	}
+	for _, c := range costs {
+		if c.cost < 0 || c.cooldown < 0 {
+			return fmt.Errorf("%v ability cost %v and cooldown %v must not be negative", c.name, c.cost, c.cooldown)
+		}
+	}
+	if a.Airstrike.Power < 0 || a.Airstrike.Radius < 0 {
+		return fmt.Errorf("airstrike power %v and radius %v must not be negative", a.Airstrike.Power, a.Airstrike.Radius)
+	}
+	if a.Airstrike.Falloff < 0 || a.Airstrike.Falloff > 1 {
+		return fmt.Errorf("airstrike falloff %v must be between 0 and 1", a.Airstrike.Falloff)
+	}
+	if !slices.Contains(damageTypes, a.Airstrike.DamageType) {
+		return fmt.Errorf("airstrike has unknown damage type %q", a.Airstrike.DamageType)
+	}
+	if a.Freeze.Duration < 0 {
+		return fmt.Errorf("freeze duration %v must not be negative", a.Freeze.Duration)
+	}
+	if a.Repair.HealPercent < 0 || a.Repair.HealPercent > 100 {
+		return fmt.Errorf("repair heal percent %v must be between 0 and 100", a.Repair.HealPercent)
+	}
+	return nil
+}
+
+func (b *BalanceData) validateCreeps() error {
+	if len(b.Creep.Types) == 0 {
+		return errors.New("no creep types defined")
+	}
+	names := make([]string, 0, len(b.Creep.Types))
+	for _, creepType := range b.Creep.Types {
+		if len(creepType.Name) == 0 {
+			return errors.New("creep type has no name")
+		}
+		if slices.Contains(names, creepType.Name) {
+			return fmt.Errorf("creep type %q is defined more than once", creepType.Name)
+		}
+		names = append(names, creepType.Name)
+		if len(creepType.Sprite) == 0 {
+			return fmt.Errorf("creep type %q has no sprite", creepType.Name)
+		}
+		if !slices.Contains(creepAttackTypes, creepType.AttackType) {
+			return fmt.Errorf("creep type %q has unknown attack type %q", creepType.Name, creepType.AttackType)
+		}
+		if creepType.AttackCooldown <= 0 {
+			return fmt.Errorf("creep type %q attack cooldown %v must be positive", creepType.Name, creepType.AttackCooldown)
+		}
+		if creepType.Armor < 0 {
+			return fmt.Errorf("creep type %q armor %v must not be negative", creepType.Name, creepType.Armor)
+		}
+		for damageType, resistance := range creepType.Resistances {
+			if damageType == "" || !slices.Contains(damageTypes, damageType) {
+				return fmt.Errorf("creep type %q has a resistance to unknown damage type %q", creepType.Name, damageType)
+			}
+			if resistance > 100 {
+				return fmt.Errorf("creep type %q resistance to %v %v must be at most 100", creepType.Name, damageType, resistance)
+			}
+		}
+		if creepType.Flying && creepType.PathAround {
+			return fmt.Errorf("creep type %q flies over towers so it can't path around them", creepType.Name)
+		}
+	}
+	// check behaviors once every type is known so minions can be any type
+	for _, creepType := range b.Creep.Types {
+		for _, behavior := range creepType.Behaviors {
+			if err := b.validateCreepBehavior(behavior); err != nil {
+				return fmt.Errorf("creep type %q: %w", creepType.Name, err)
+			}
+		}
+	}
+	if err := b.validateCreepSplits(); err != nil {
+		return err
+	}
+	if b.Creep.GetType(b.Multiplayer.SuperCreepType) == nil {
+		return fmt.Errorf("super creep type %q is not defined", b.Multiplayer.SuperCreepType)
+	}
+	return b.validateBoss()
+}
+
+// validateCreepSplits checks the split types exist and that no type splits back into itself, which would never end
+func (b *BalanceData) validateCreepSplits() error {
+	for _, creepType := range b.Creep.Types {
+		for _, split := range creepType.Split {
+			if b.Creep.GetType(split.Type) == nil {
+				return fmt.Errorf("creep type %q split type %q is not defined", creepType.Name, split.Type)
+			}
+			if split.Count <= 0 {
+				return fmt.Errorf("creep type %q split %q count %v must be positive", creepType.Name, split.Type, split.Count)
+			}
+		}
+	}
+	var visit func(name string, chain []string) error
+	visit = func(name string, chain []string) error {
+		found := slices.Contains(chain, name)
+		chain = append(chain, name)
+		if found {
+			return fmt.Errorf("creep type %q splits into itself through %v", name, strings.Join(chain, " -> "))
+		}
+		for _, split := range b.Creep.GetType(name).Split {
+			if err := visit(split.Type, chain); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	for _, creepType := range b.Creep.Types {
+		if err := visit(creepType.Name, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (b *BalanceData) validateCreepBehavior(behavior CreepBehaviorBalance) error {
+	if !slices.Contains(creepBehaviorKinds, behavior.Kind) {
+		return fmt.Errorf("unknown behavior kind %q", behavior.Kind)
+	}
+	if behavior.Interval <= 0 {
+		return fmt.Errorf("behavior %q interval %v must be positive", behavior.Kind, behavior.Interval)
+	}
+	if behavior.MinionType != "" && b.Creep.GetType(behavior.MinionType) == nil {
+		return fmt.Errorf("behavior %q minion type %q is not defined", behavior.Kind, behavior.MinionType)
+	}
+	return nil
+}
+
+func (b *BalanceData) validateBoss() error {
+	if b.Boss.WaveInterval > 0 {
+		if b.Creep.GetType(b.Boss.Type) == nil {
+			return fmt.Errorf("boss creep type %q is not defined", b.Boss.Type)
+		}
+		if b.Boss.HealthMultiplier <= 0 {
+			return fmt.Errorf("boss health multiplier %v must be positive", b.Boss.HealthMultiplier)
+		}
+	}
+	lastPercent := 100
+	for i, phase := range b.Boss.Phases {
+		if phase.HealthPercent <= 0 || phase.HealthPercent >= lastPercent {
+			return fmt.Errorf("boss phase %v health percent %v must be below %v and above 0", i+1, phase.HealthPercent, lastPercent)
+		}
+		lastPercent = phase.HealthPercent
+		if phase.MinionType != "" && b.Creep.GetType(phase.MinionType) == nil {
+			return fmt.Errorf("boss phase %v minion type %q is not defined", i+1, phase.MinionType)
+		}
+		if phase.SpreadShot.Bullets > 0 && phase.SpreadShot.Interval <= 0 {
+			return fmt.Errorf("boss phase %v spread shot interval %v must be positive", i+1, phase.SpreadShot.Interval)
+		}
+	}
+	return nil
+}
+
+func (b *BalanceData) validateAttackEffect(effect AttackEffectBalance) error {
+	if effect.Kind == "" {
+		return nil
+	}
+	if _, ok := b.Effects[effect.Kind]; !ok {
+		return fmt.Errorf("effect %q has no effects balance", effect.Kind)
+	}
+	if effect.Duration <= 0 {
+		return fmt.Errorf("effect %q duration %v must be positive", effect.Kind, effect.Duration)
+	}
+	return nil
+}
+
+func (b *BalanceData) validateHazard(hazard HazardBalance) error {
+	if hazard.Kind == "" {
+		return nil
+	}
+	if !slices.Contains(hazardKinds, hazard.Kind) {
+		return fmt.Errorf("unknown hazard kind %q", hazard.Kind)
+	}
+	if !slices.Contains(hazardSpawnOn, hazard.SpawnOn) {
+		return fmt.Errorf("hazard %q has unknown spawnOn %q", hazard.Kind, hazard.SpawnOn)
+	}
+	if hazard.Size <= 0 || hazard.Duration <= 0 {
+		return fmt.Errorf("hazard %q size %v and duration %v must be positive", hazard.Kind, hazard.Size, hazard.Duration)
+	}
+	return b.validateAttackEffect(hazard.Effect)
+}
@@ -0,0 +1,168 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTowerBalance_GetType(t *testing.T) {
+	tower := DefaultBalance().Tower
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"Ranged", "Ranged"},
+		{"Wall", "Wall"},
+		{"", "Ranged"},
+		{"Missing", "Ranged"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tower.GetType(tt.name).Name; got != tt.want {
+				t.Errorf("GetType(%q) = %v, want %v", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDefaultBalanceTowerTypesHaveCosts(t *testing.T) {
+	tower := DefaultBalance().Tower
+	for _, name := range tower.TypeNames() {
+		if tower.Costs[name] <= 0 {
+			t.Errorf("tower type %v cost = %v, want > 0", name, tower.Costs[name])
+		}
+	}
+}
+
+func Test_parseBalanceFileValidatesTowerTypes(t *testing.T) {
+	tests := []struct {
+		name    string
+		json    string
+		wantErr string
+	}{
+		{"no types", `{"tower": {"defaultType": "Ranged"}}`, "no tower types"},
+		{"missing cost", `{"tower": {"defaultType": "Ranged", "types": [{"name": "Ranged", "sprite": "tower"}]}}`, "has no cost"},
+		{"missing sprite", `{"tower": {"defaultType": "Ranged", "costs": {"Ranged": 1}, "types": [{"name": "Ranged"}]}}`, "has no sprite"},
+		{"missing default", `{"tower": {"defaultType": "Melee", "costs": {"Ranged": 1}, "types": [{"name": "Ranged", "sprite": "tower"}]}}`, "is not defined"},
+		{"effect without rules", `{"tower": {"defaultType": "Ranged", "costs": {"Ranged": 1}, "types": [{"name": "Ranged", "sprite": "tower", "effect": {"kind": "Slow", "duration": 5}}]}}`, "has no effects balance"},
+		{"effect without duration", `{"tower": {"defaultType": "Ranged", "costs": {"Ranged": 1}, "types": [{"name": "Ranged", "sprite": "tower", "effect": {"kind": "Slow"}}]}, "effects": {"Slow": {"stacking": "refresh"}}}`, "must be positive"},
+		{"unknown stacking", `{"tower": {"defaultType": "Ranged", "costs": {"Ranged": 1}, "types": [{"name": "Ranged", "sprite": "tower"}]}, "effects": {"Slow": {"stacking": "pile"}}}`, "unknown stacking rule"},
+		{"unknown damage type", `{"tower": {"defaultType": "Ranged", "costs": {"Ranged": 1}, "types": [{"name": "Ranged", "sprite": "tower", "damageType": "Sonic"}]}}`, "unknown damage type"},
+		{"veteran ranks out of order", `{"tower": {"defaultType": "Ranged", "costs": {"Ranged": 1}, "types": [{"name": "Ranged", "sprite": "tower"}], "veterancy": {"ranks": [{"xp": 50}, {"xp": 50}]}}}`, "more than the rank before"},
+		{"separate ammo without magazine", `{"tower": {"defaultType": "Ranged", "costs": {"Ranged": 1}, "types": [{"name": "Ranged", "sprite": "tower", "attackPower": 1}], "ammo": {"separateAmmo": true, "resupplyCostDivisor": 2}}}`, "ammo 0 must be positive"},
+		{"separate ammo without resupply cost", `{"tower": {"defaultType": "Ranged", "costs": {"Ranged": 1}, "types": [{"name": "Ranged", "sprite": "tower", "ammo": 5}], "ammo": {"separateAmmo": true}}}`, "resupply cost divisor"},
+		{"branch levels out of order", `{"tower": {"defaultType": "Ranged", "initialLevel": 1, "costs": {"Ranged": 1}, "types": [{"name": "Ranged", "sprite": "tower"}], "branches": [{"level": 3, "options": [{"name": "Range"}]}, {"level": 3, "options": [{"name": "Speed"}]}]}}`, "more than the initial level"},
+		{"duplicate branch option", `{"tower": {"defaultType": "Ranged", "costs": {"Ranged": 1}, "types": [{"name": "Ranged", "sprite": "tower", "branches": [{"level": 2, "options": [{"name": "Range"}, {"name": "Range"}]}]}]}}`, "must be set and unique"},
+		{"unknown projectile", `{"tower": {"defaultType": "Ranged", "costs": {"Ranged": 1}, "types": [{"name": "Ranged", "sprite": "tower", "projectile": "Laser"}]}}`, "unknown projectile"},
+		{"melee beam", `{"tower": {"defaultType": "Ranged", "costs": {"Ranged": 1}, "types": [{"name": "Ranged", "sprite": "tower", "attackType": "MeleeArea", "projectile": "Beam"}]}}`, "can't use projectile"},
+		{"piercing without pierce", `{"tower": {"defaultType": "Ranged", "costs": {"Ranged": 1}, "types": [{"name": "Ranged", "sprite": "tower", "projectile": "Piercing"}]}}`, "must be positive for piercing"},
+		{"unknown aura stacking", `{"tower": {"defaultType": "Ranged", "costs": {"Ranged": 1}, "types": [{"name": "Ranged", "sprite": "tower"}], "auras": {"stacking": "multiply"}}}`, "unknown aura stacking"},
+		{"aura tower that fires", `{"tower": {"defaultType": "Ranged", "costs": {"Ranged": 1}, "types": [{"name": "Ranged", "sprite": "tower", "attackPower": 1, "aura": {"powerAdd": 1}}]}}`, "must have no attack power"},
+		{"negative ability cooldown", `{"tower": {"defaultType": "Ranged", "costs": {"Ranged": 1}, "types": [{"name": "Ranged", "sprite": "tower"}]}, "player": {"abilities": {"freeze": {"cooldown": -1}}}}`, "must not be negative"},
+		{"unknown airstrike damage type", `{"tower": {"defaultType": "Ranged", "costs": {"Ranged": 1}, "types": [{"name": "Ranged", "sprite": "tower"}]}, "player": {"abilities": {"airstrike": {"damageType": "Sonic"}}}}`, "unknown damage type"},
+		{"repair over 100", `{"tower": {"defaultType": "Ranged", "costs": {"Ranged": 1}, "types": [{"name": "Ranged", "sprite": "tower"}]}, "player": {"abilities": {"repair": {"healPercent": 150}}}}`, "between 0 and 100"},
+		{"unknown hazard", `{"tower": {"defaultType": "Ranged", "costs": {"Ranged": 1}, "types": [{"name": "Ranged", "sprite": "tower", "hazard": {"kind": "Quicksand"}}]}}`, "unknown hazard kind"},
+		{"hazard without size", `{"tower": {"defaultType": "Ranged", "costs": {"Ranged": 1}, "types": [{"name": "Ranged", "sprite": "tower", "hazard": {"kind": "TarPit", "spawnOn": "hit", "duration": 5}}]}}`, "must be positive"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseBalanceFile("test", []byte(tt.json))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("parseBalanceFile() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLevelFormula_At(t *testing.T) {
+	tests := []struct {
+		name    string
+		formula LevelFormula
+		level   int
+		want    int
+	}{
+		{"constant", LevelFormula{Base: 20}, 7, 20},
+		{"scaled", LevelFormula{Base: 3, LevelMultiplier: 1, LevelDivisor: 3}, 7, 5},
+		{"offset", LevelFormula{Base: 1, LevelOffset: 1, LevelMultiplier: 2, LevelDivisor: 4}, 5, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.formula.At(tt.level); got != tt.want {
+				t.Errorf("At(%v) = %v, want %v", tt.level, got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_parseBalanceFileValidatesCreepTypes(t *testing.T) {
+	const tower = `"tower": {"defaultType": "Ranged", "costs": {"Ranged": 1}, "types": [{"name": "Ranged", "sprite": "tower"}]}`
+	tests := []struct {
+		name    string
+		json    string
+		wantErr string
+	}{
+		{"no types", `{` + tower + `}`, "no creep types"},
+		{"missing sprite", `{` + tower + `, "creep": {"types": [{"name": "Grunt", "attackCooldown": 5}]}}`, "has no sprite"},
+		{"duplicate", `{` + tower + `, "creep": {"types": [{"name": "Grunt", "sprite": "creep1", "attackCooldown": 5}, {"name": "Grunt", "sprite": "creep2", "attackCooldown": 5}]}}`, "more than once"},
+		{"area attack", `{` + tower + `, "creep": {"types": [{"name": "Grunt", "sprite": "creep1", "attackCooldown": 5, "attackType": "RangedArea"}]}}`, "unknown attack type"},
+		{"unknown resistance", `{` + tower + `, "creep": {"types": [{"name": "Grunt", "sprite": "creep1", "attackCooldown": 5, "resistances": {"Sonic": 50}}]}}`, "unknown damage type"},
+		{"resistance over 100", `{` + tower + `, "creep": {"types": [{"name": "Grunt", "sprite": "creep1", "attackCooldown": 5, "resistances": {"Energy": 150}}]}}`, "at most 100"},
+		{"unknown split", `{` + tower + `, "creep": {"types": [{"name": "Blob", "sprite": "creep1", "attackCooldown": 5, "split": [{"type": "Dragon", "count": 2}]}]}}`, "split type"},
+		{"split count", `{` + tower + `, "creep": {"types": [{"name": "Blob", "sprite": "creep1", "attackCooldown": 5, "split": [{"type": "Blob", "count": 0}]}]}}`, "must be positive"},
+		{"split cycle", `{` + tower + `, "creep": {"types": [{"name": "Blob", "sprite": "creep1", "attackCooldown": 5, "split": [{"type": "Goo", "count": 2}]}, {"name": "Goo", "sprite": "creep1", "attackCooldown": 5, "split": [{"type": "Blob", "count": 1}]}]}}`, "splits into itself"},
+		{"flying path around", `{` + tower + `, "creep": {"types": [{"name": "Wasp", "sprite": "creep1", "attackCooldown": 5, "flying": true, "pathAround": true}]}}`, "can't path around"},
+		{"missing super creep", `{` + tower + `, "creep": {"types": [{"name": "Grunt", "sprite": "creep1", "attackCooldown": 5}]}, "multiplayer": {"superCreepType": "Super"}}`, "super creep type"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseBalanceFile("test", []byte(tt.json))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("parseBalanceFile() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_validateBoss(t *testing.T) {
+	tests := []struct {
+		name    string
+		boss    BossBalance
+		wantErr string
+	}{
+		{"unknown type", BossBalance{WaveInterval: 5, Type: "Dragon", HealthMultiplier: 2}, "boss creep type"},
+		{"no health multiplier", BossBalance{WaveInterval: 5, Type: "Brute"}, "health multiplier"},
+		{"phases out of order", BossBalance{Phases: []BossPhaseBalance{{HealthPercent: 50}, {HealthPercent: 60}}}, "must be below 50"},
+		{"unknown minion", BossBalance{Phases: []BossPhaseBalance{{HealthPercent: 50, MinionType: "Dragon", MinionCount: 1}}}, "minion type"},
+		{"spread shot without interval", BossBalance{Phases: []BossPhaseBalance{{HealthPercent: 50, SpreadShot: SpreadShotBalance{Bullets: 3}}}}, "spread shot interval"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			balance := *DefaultBalance()
+			balance.Boss = tt.boss
+			err := balance.validate()
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("validate() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_validateCreepBehavior(t *testing.T) {
+	tests := []struct {
+		name     string
+		behavior CreepBehaviorBalance
+		wantErr  string
+	}{
+		{"unknown kind", CreepBehaviorBalance{Kind: "Dance", Interval: 5}, "unknown behavior kind"},
+		{"no interval", CreepBehaviorBalance{Kind: "Heal"}, "must be positive"},
+		{"unknown minion", CreepBehaviorBalance{Kind: "Summon", Interval: 5, MinionType: "Dragon"}, "minion type"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := DefaultBalance().validateCreepBehavior(tt.behavior)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("validateCreepBehavior() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -8,6 +8,14 @@ type ConfigData struct {
 	GridLines bool
 	ShowStats bool
 	Sound     bool
+	// ScriptedWaves plays the wave script instead of random waves
+	ScriptedWaves bool
+	// Seed fixes the random seed of every battle, 0 generates a new seed for each battle
+	Seed uint64
+	// ReplayPath is where each battle's replay is saved when it ends, empty to not save replays
+	ReplayPath string
+	// SavePath is where the battle in progress is saved when the game quits, to resume it from the title screen, empty to not save battles
+	SavePath string
 
 	ClientHostPort string
 	ServerPort     string
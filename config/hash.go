@@ -0,0 +1,28 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+)
+
+// hashJSON fingerprints the data by its JSON, so files that only differ in formatting or field order hash the same
+func hashJSON(data any) string {
+	bytes, err := json.Marshal(data)
+	if err != nil {
+		// the config types always marshal, they were parsed from JSON
+		panic(err)
+	}
+	sum := sha256.Sum256(bytes)
+	return hex.EncodeToString(sum[:])
+}
+
+// Hash fingerprints the balance, replays carry it to check they are played with the balance they were recorded with
+func (b *BalanceData) Hash() string {
+	return hashJSON(b)
+}
+
+// Hash fingerprints the wave script, replays carry it to check they are played with the script they were recorded with
+func (w *WaveScriptData) Hash() string {
+	return hashJSON(w)
+}
@@ -0,0 +1,209 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// SweepSpec is a balance parameter sweep, every combination of the parameters' values is played on top of the base balance
+type SweepSpec struct {
+	Params []SweepParam `json:"params"`
+}
+
+// SweepParam is one balance value to vary. Path is the value's place in the balance JSON, dot separated,
+// with list entries picked by index or by name, like tower.types.Ranged.attackPower.
+// Values lists the values to try, or From, To and Step give an evenly spaced range instead.
+type SweepParam struct {
+	Path   string    `json:"path"`
+	Values []float64 `json:"values"`
+	From   float64   `json:"from"`
+	To     float64   `json:"to"`
+	Step   float64   `json:"step"`
+}
+
+// maxSweepCombinations keeps a mistyped range from queueing up games for days
+const maxSweepCombinations = 1000
+
+func LoadSweepSpec(path string) (*SweepSpec, error) {
+	bytes, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var spec SweepSpec
+	if err := json.Unmarshal(bytes, &spec); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	if err := spec.validate(); err != nil {
+		return nil, fmt.Errorf("validate %s: %w", path, err)
+	}
+	return &spec, nil
+}
+
+func (s *SweepSpec) validate() error {
+	if len(s.Params) == 0 {
+		return errors.New("sweep must have at least one param")
+	}
+	combinations := 1
+	for i, param := range s.Params {
+		if param.Path == "" {
+			return fmt.Errorf("param %d must have a path", i)
+		}
+		if len(param.Values) == 0 {
+			if param.Step <= 0 {
+				return fmt.Errorf("param %s must have values or a positive step", param.Path)
+			}
+			if param.To < param.From {
+				return fmt.Errorf("param %s range ends at %v before it starts at %v", param.Path, param.To, param.From)
+			}
+		}
+		combinations *= len(param.Range())
+		if combinations > maxSweepCombinations {
+			return fmt.Errorf("sweep has more than %d combinations", maxSweepCombinations)
+		}
+	}
+	return nil
+}
+
+// Range returns the values to try, in order
+func (p SweepParam) Range() []float64 {
+	if len(p.Values) > 0 {
+		return p.Values
+	}
+	if p.Step <= 0 {
+		return []float64{p.From}
+	}
+	values := make([]float64, 0)
+	// count steps rather than adding them up so fractional steps don't drift past the end
+	for i := 0; ; i++ {
+		value := p.From + float64(i)*p.Step
+		if value > p.To+p.Step/1e6 {
+			break
+		}
+		values = append(values, value)
+	}
+	return values
+}
+
+// Combinations returns every combination of the param values, the last param changing fastest
+func (s *SweepSpec) Combinations() [][]float64 {
+	combinations := [][]float64{{}}
+	for _, param := range s.Params {
+		next := make([][]float64, 0, len(combinations)*len(param.Range()))
+		for _, combination := range combinations {
+			for _, value := range param.Range() {
+				next = append(next, append(append([]float64{}, combination...), value))
+			}
+		}
+		combinations = next
+	}
+	return combinations
+}
+
+// Paths returns the param paths in order
+func (s *SweepSpec) Paths() []string {
+	paths := make([]string, len(s.Params))
+	for i, param := range s.Params {
+		paths[i] = param.Path
+	}
+	return paths
+}
+
+// WithValues returns a copy of the balance with the value at each path changed, validated like a loaded balance file
+func (b *BalanceData) WithValues(paths []string, values []float64) (*BalanceData, error) {
+	tree, err := b.jsonTree()
+	if err != nil {
+		return nil, err
+	}
+	for i, path := range paths {
+		err := walkJSONPath(tree, strings.Split(path, "."), func(float64) float64 {
+			return values[i]
+		})
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	bytes, err := json.Marshal(tree)
+	if err != nil {
+		return nil, err
+	}
+	return parseBalanceFile("swept balance", bytes)
+}
+
+// Values returns the balance's values at the paths, the same paths WithValues changes
+func (b *BalanceData) Values(paths []string) ([]float64, error) {
+	tree, err := b.jsonTree()
+	if err != nil {
+		return nil, err
+	}
+	values := make([]float64, len(paths))
+	for i, path := range paths {
+		// setting the path to itself finds the value and checks the path leads to a number
+		err := walkJSONPath(tree, strings.Split(path, "."), func(value float64) float64 {
+			values[i] = value
+			return value
+		})
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	return values, nil
+}
+
+// jsonTree decodes the balance's JSON into maps and lists to walk by path
+func (b *BalanceData) jsonTree() (any, error) {
+	bytes, err := json.Marshal(b)
+	if err != nil {
+		return nil, err
+	}
+	var tree any
+	err = json.Unmarshal(bytes, &tree)
+	return tree, err
+}
+
+// walkJSONPath replaces the number at the path in a decoded JSON tree with what update returns for it,
+// refusing paths that don't lead to a number
+func walkJSONPath(node any, path []string, update func(float64) float64) error {
+	key := path[0]
+	var child any
+	var set func(any)
+	switch n := node.(type) {
+	case map[string]any:
+		var ok bool
+		child, ok = n[key]
+		if !ok {
+			return fmt.Errorf("no field %q", key)
+		}
+		set = func(v any) { n[key] = v }
+	case []any:
+		index, err := strconv.Atoi(key)
+		if err != nil {
+			// list entries with a name can be picked by it, like tower and creep types
+			index = slices.IndexFunc(n, func(entry any) bool {
+				fields, ok := entry.(map[string]any)
+				return ok && fields["name"] == key
+			})
+		}
+		if index < 0 || index >= len(n) {
+			return fmt.Errorf("no list entry %q", key)
+		}
+		child = n[index]
+		set = func(v any) { n[index] = v }
+	default:
+		return fmt.Errorf("%q is past the end of the path", key)
+	}
+
+	if len(path) > 1 {
+		return walkJSONPath(child, path[1:], update)
+	}
+	value, ok := child.(float64)
+	if !ok {
+		return fmt.Errorf("%q is not a number", key)
+	}
+	set(update(value))
+	return nil
+}
@@ -0,0 +1,132 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSweepParamRange(t *testing.T) {
+	tests := []struct {
+		name  string
+		param SweepParam
+		want  []float64
+	}{
+		{"values", SweepParam{Values: []float64{3, 1}, From: 0, To: 10, Step: 5}, []float64{3, 1}},
+		{"range", SweepParam{From: 50, To: 150, Step: 50}, []float64{50, 100, 150}},
+		{"fractional step", SweepParam{From: 0, To: 0.3, Step: 0.1}, []float64{0, 0.1, 0.2, 0.30000000000000004}},
+		{"step past the end", SweepParam{From: 1, To: 4, Step: 2}, []float64{1, 3}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.param.Range(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Range() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSweepSpecCombinations(t *testing.T) {
+	spec := SweepSpec{Params: []SweepParam{{Path: "a", Values: []float64{1, 2}}, {Path: "b", Values: []float64{10, 20, 30}}}}
+	want := [][]float64{{1, 10}, {1, 20}, {1, 30}, {2, 10}, {2, 20}, {2, 30}}
+	if got := spec.Combinations(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Combinations() = %v, want %v", got, want)
+	}
+}
+
+func TestLoadSweepSpecValidates(t *testing.T) {
+	tests := []struct {
+		name    string
+		json    string
+		wantErr string
+	}{
+		{"valid", `{"params": [{"path": "player.startingMoney", "from": 50, "to": 150, "step": 50}]}`, ""},
+		{"no params", `{"params": []}`, "at least one param"},
+		{"no path", `{"params": [{"values": [1]}]}`, "must have a path"},
+		{"no step", `{"params": [{"path": "player.health", "from": 1, "to": 5}]}`, "positive step"},
+		{"backwards", `{"params": [{"path": "player.health", "from": 5, "to": 1, "step": 1}]}`, "before it starts"},
+		{"too many", `{"params": [{"path": "a", "from": 0, "to": 99, "step": 1}, {"path": "b", "from": 0, "to": 99, "step": 1}]}`, "combinations"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "sweep.json")
+			if err := os.WriteFile(path, []byte(tt.json), 0644); err != nil {
+				t.Fatal(err)
+			}
+			_, err := LoadSweepSpec(path)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("LoadSweepSpec() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("LoadSweepSpec() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestBalanceDataWithValues(t *testing.T) {
+	base := DefaultBalance()
+	balance, err := base.WithValues([]string{"player.startingMoney", "tower.types.Sniper.attackRange", "creep.types.1.health.base", "tower.costs.Ranged"}, []float64{999, 321, 77, 12})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if balance.Player.StartingMoney != 999 {
+		t.Errorf("StartingMoney = %v, want 999", balance.Player.StartingMoney)
+	}
+	if got := balance.Tower.GetType("Sniper").AttackRange; got != 321 {
+		t.Errorf("Sniper AttackRange = %v, want 321", got)
+	}
+	if got := balance.Creep.Types[1].Health.Base; got != 77 {
+		t.Errorf("creep type 1 health base = %v, want 77", got)
+	}
+	if got := balance.Tower.Costs["Ranged"]; got != 12 {
+		t.Errorf("Ranged cost = %v, want 12", got)
+	}
+	if base.Player.StartingMoney == 999 {
+		t.Error("WithValues() changed the base balance")
+	}
+}
+
+func TestBalanceDataWithValuesRefusesBadPaths(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string
+		value   float64
+		wantErr string
+	}{
+		{"unknown field", "player.gold", 1, "no field"},
+		{"unknown entry", "tower.types.Laser.attackPower", 1, "no list entry"},
+		{"not a number", "tower.defaultType", 1, "not a number"},
+		{"past the end", "player.health.max", 1, "past the end"},
+		{"fraction for an int", "player.health", 1.5, "swept balance"},
+		{"invalid balance", "tower.types.Splash.areaFalloff", 2, "validate"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := DefaultBalance().WithValues([]string{tt.path}, []float64{tt.value})
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("WithValues(%v) error = %v, want %q", tt.path, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestBalanceDataValues(t *testing.T) {
+	balance := DefaultBalance()
+	got, err := balance.Values([]string{"player.startingMoney", "tower.types.Sniper.attackRange"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []float64{float64(balance.Player.StartingMoney), float64(balance.Tower.GetType("Sniper").AttackRange)}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Values() = %v, want %v", got, want)
+	}
+	if _, err := balance.Values([]string{"player.gold"}); err == nil {
+		t.Error("Values() found a field that isn't in the balance")
+	}
+}
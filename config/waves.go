@@ -0,0 +1,187 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/yohamta/donburi"
+)
+
+// WaveScriptData is a hand authored list of waves that replaces the random wave timer when scripted waves are turned on
+type WaveScriptData struct {
+	Name    string         `json:"name"`
+	Waves   []ScriptedWave `json:"waves"`
+	Endless EndlessWaves   `json:"endless"`
+}
+
+type ScriptedWave struct {
+	// Delay is the number of ticks to wait after the previous wave finished spawning
+	Delay int `json:"delay"`
+	// LevelBonus is added to the player's creep level for every creep in the wave
+	LevelBonus int         `json:"levelBonus"`
+	Groups     []WaveGroup `json:"groups"`
+	Bosses     []WaveBoss  `json:"bosses"`
+}
+
+// WaveGroup is a number of creeps of one type spawned in a formation
+type WaveGroup struct {
+	Type  string `json:"type"`
+	Count int    `json:"count"`
+	// Formation is "line" to spawn side by side, "column" for one behind the other, "wedge" for a V with the point in front,
+	// or "random" for anywhere across the top of the board
+	Formation string `json:"formation"`
+	// Position is where the formation is centered as a percent of the board width, 50 is the middle
+	Position int `json:"position"`
+	Spacing  int `json:"spacing"`
+	// Delay is the number of ticks after the wave starts before the group spawns
+	Delay int `json:"delay"`
+	// Interval is the number of ticks between the rows of a formation
+	Interval int `json:"interval"`
+}
+
+// WaveBoss is a single creep spawned with its health multiplied
+type WaveBoss struct {
+	Type             string `json:"type"`
+	Position         int    `json:"position"`
+	Delay            int    `json:"delay"`
+	HealthMultiplier int    `json:"healthMultiplier"`
+}
+
+// EndlessWaves says what happens after the last scripted wave
+type EndlessWaves struct {
+	// Mode is "random" to go back to the random wave timer or "repeat" to play the waves again from RepeatFrom
+	Mode       string `json:"mode"`
+	RepeatFrom int    `json:"repeatFrom"`
+	// LevelBonusPerLoop is added to the creep level each time the waves repeat
+	LevelBonusPerLoop int `json:"levelBonusPerLoop"`
+}
+
+var WaveScript = donburi.NewComponentType[WaveScriptData]()
+
+var formations = []string{"line", "column", "wedge", "random"}
+var endlessModes = []string{"random", "repeat"}
+
+var defaultWaveScript = mustLoadDefaultWaveScript()
+
+// LoadWaveScript reads a wave script JSON file, an empty path loads the embedded default
+func LoadWaveScript(path string) (*WaveScriptData, error) {
+	if path == "" {
+		return parseWaveScriptFile("embedded default waves", mustReadDefaultWaveScript())
+	}
+
+	bytes, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return parseWaveScriptFile(path, bytes)
+}
+
+func DefaultWaveScript() *WaveScriptData {
+	return defaultWaveScript
+}
+
+func NewWaveScript(world donburi.World, script *WaveScriptData) *WaveScriptData {
+	if script == nil {
+		script = DefaultWaveScript()
+	}
+	entity := world.Create(WaveScript)
+	entry := world.Entry(entity)
+
+	WaveScript.Set(entry, script)
+	return WaveScript.Get(entry)
+}
+
+func GetWaveScript(world donburi.World) *WaveScriptData {
+	entry, ok := WaveScript.First(world)
+	if !ok {
+		return DefaultWaveScript()
+	}
+	return WaveScript.Get(entry)
+}
+
+func mustLoadDefaultWaveScript() *WaveScriptData {
+	script, err := parseWaveScriptFile("embedded default waves", mustReadDefaultWaveScript())
+	if err != nil {
+		panic(err)
+	}
+	if err := script.ValidateCreepTypes(DefaultBalance()); err != nil {
+		panic(err)
+	}
+	return script
+}
+
+func mustReadDefaultWaveScript() []byte {
+	bytes, err := balanceFS.ReadFile("default_waves.json")
+	if err != nil {
+		panic(err)
+	}
+	return bytes
+}
+
+func parseWaveScriptFile(name string, bytes []byte) (*WaveScriptData, error) {
+	var script WaveScriptData
+	if err := json.Unmarshal(bytes, &script); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", name, err)
+	}
+	if err := script.validate(); err != nil {
+		return nil, fmt.Errorf("validate %s: %w", name, err)
+	}
+	return &script, nil
+}
+
+func (w *WaveScriptData) validate() error {
+	if len(w.Waves) == 0 {
+		return errors.New("no waves defined")
+	}
+	for i, wave := range w.Waves {
+		if len(wave.Groups) == 0 && len(wave.Bosses) == 0 {
+			return fmt.Errorf("wave %v has no creeps", i+1)
+		}
+		for _, group := range wave.Groups {
+			if group.Count <= 0 {
+				return fmt.Errorf("wave %v group %q count %v must be positive", i+1, group.Type, group.Count)
+			}
+			if !slices.Contains(formations, group.Formation) {
+				return fmt.Errorf("wave %v group %q has unknown formation %q", i+1, group.Type, group.Formation)
+			}
+			if group.Position < 0 || group.Position > 100 {
+				return fmt.Errorf("wave %v group %q position %v must be between 0 and 100", i+1, group.Type, group.Position)
+			}
+		}
+		for _, boss := range wave.Bosses {
+			if boss.HealthMultiplier <= 0 {
+				return fmt.Errorf("wave %v boss %q health multiplier %v must be positive", i+1, boss.Type, boss.HealthMultiplier)
+			}
+			if boss.Position < 0 || boss.Position > 100 {
+				return fmt.Errorf("wave %v boss %q position %v must be between 0 and 100", i+1, boss.Type, boss.Position)
+			}
+		}
+	}
+	if !slices.Contains(endlessModes, w.Endless.Mode) {
+		return fmt.Errorf("unknown endless mode %q", w.Endless.Mode)
+	}
+	if w.Endless.RepeatFrom < 0 || w.Endless.RepeatFrom >= len(w.Waves) {
+		return fmt.Errorf("endless repeat from wave index %v is out of range", w.Endless.RepeatFrom)
+	}
+	return nil
+}
+
+// ValidateCreepTypes checks that every creep in the script is defined in the balance
+func (w *WaveScriptData) ValidateCreepTypes(balance *BalanceData) error {
+	for i, wave := range w.Waves {
+		for _, group := range wave.Groups {
+			if balance.Creep.GetType(group.Type) == nil {
+				return fmt.Errorf("wave %v creep type %q is not defined in the balance", i+1, group.Type)
+			}
+		}
+		for _, boss := range wave.Bosses {
+			if balance.Creep.GetType(boss.Type) == nil {
+				return fmt.Errorf("wave %v boss creep type %q is not defined in the balance", i+1, boss.Type)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDefaultWaveScriptLoads(t *testing.T) {
+	script, err := LoadWaveScript("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(script.Waves) == 0 {
+		t.Error("default wave script has no waves")
+	}
+	if err := script.ValidateCreepTypes(DefaultBalance()); err != nil {
+		t.Error(err)
+	}
+}
+
+func Test_parseWaveScriptFileValidates(t *testing.T) {
+	const endless = `"endless": {"mode": "random"}`
+	tests := []struct {
+		name    string
+		json    string
+		wantErr string
+	}{
+		{"no waves", `{` + endless + `}`, "no waves"},
+		{"empty wave", `{"waves": [{"delay": 5}], ` + endless + `}`, "has no creeps"},
+		{"zero count", `{"waves": [{"groups": [{"type": "Grunt", "formation": "line"}]}], ` + endless + `}`, "must be positive"},
+		{"unknown formation", `{"waves": [{"groups": [{"type": "Grunt", "count": 1, "formation": "circle"}]}], ` + endless + `}`, "unknown formation"},
+		{"position off board", `{"waves": [{"groups": [{"type": "Grunt", "count": 1, "formation": "line", "position": 120}]}], ` + endless + `}`, "between 0 and 100"},
+		{"boss without health", `{"waves": [{"bosses": [{"type": "Brute"}]}], ` + endless + `}`, "health multiplier"},
+		{"unknown endless", `{"waves": [{"groups": [{"type": "Grunt", "count": 1, "formation": "line"}]}], "endless": {"mode": "stop"}}`, "unknown endless mode"},
+		{"repeat out of range", `{"waves": [{"groups": [{"type": "Grunt", "count": 1, "formation": "line"}]}], "endless": {"mode": "repeat", "repeatFrom": 1}}`, "out of range"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseWaveScriptFile("test", []byte(tt.json))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("parseWaveScriptFile() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestWaveScriptData_ValidateCreepTypes(t *testing.T) {
+	script := &WaveScriptData{Waves: []ScriptedWave{{Bosses: []WaveBoss{{Type: "Dragon", HealthMultiplier: 2}}}}}
+	if err := script.ValidateCreepTypes(DefaultBalance()); err == nil || !strings.Contains(err.Error(), "Dragon") {
+		t.Errorf("ValidateCreepTypes() error = %v, want unknown Dragon", err)
+	}
+}
@@ -1,10 +1,14 @@
 package game
 
 import (
+	"fmt"
+	"os"
+
 	"tower-defense/assets"
 	"tower-defense/config"
 	"tower-defense/network"
 	"tower-defense/scenes"
+	"tower-defense/sim"
 
 	comp "tower-defense/components"
 
@@ -18,6 +22,11 @@ type Scene interface {
 	Update() error
 	Draw(screen *ebiten.Image)
 }
+
+// Quitter is a scene with work to finish before the game exits
+type Quitter interface {
+	Quit() error
+}
 type GameData struct {
 	world                donburi.World
 	scenes               []Scene
@@ -26,7 +35,7 @@ type GameData struct {
 	startingTowerLevel   int
 }
 
-func NewGame(width, height, speed int, startingTowerLevel int, debug, computer, nosound bool, balance *config.BalanceData) (*GameData, error) {
+func NewGame(width, height, speed int, startingTowerLevel int, debug, computer, nosound, scriptedWaves bool, seed uint64, replayPath, savePath string, balance *config.BalanceData, waves *config.WaveScriptData) (*GameData, error) {
 	err := assets.LoadAssets()
 	if err != nil {
 		return nil, err
@@ -38,25 +47,81 @@ func NewGame(width, height, speed int, startingTowerLevel int, debug, computer,
 
 	game := &GameData{world: donburi.NewWorld(), width: width, height: height, speed: speed, gameStats: gameStats, startingTowerLevel: startingTowerLevel}
 	config.NewBalance(game.world, balance)
+	config.NewWaveScript(game.world, waves)
+
+	gameOptions := config.NewConfig(game.world, debug, computer, !nosound)
+	gameOptions.ScriptedWaves = scriptedWaves
+	gameOptions.Seed = seed
+	gameOptions.ReplayPath = replayPath
+	gameOptions.SavePath = savePath
+	err = game.switchToTitle(gameStats, gameOptions)
+	if err != nil {
+		return nil, err
+	}
+	return game, nil
+}
 
-	err = game.switchToTitle(gameStats, config.NewConfig(game.world, debug, computer, !nosound))
+// NewReplayGame watches a recorded battle instead of playing, starting at the jump frame
+func NewReplayGame(replay *sim.ReplayData, jump int, nosound bool, balance *config.BalanceData, waves *config.WaveScriptData) (*GameData, error) {
+	err := assets.LoadAssets()
+	if err != nil {
+		return nil, err
+	}
+
+	ebiten.SetWindowTitle("Tower Defense Replay")
+
+	replayer, err := sim.NewReplayer(replay, balance, waves)
+	if err != nil {
+		return nil, err
+	}
+	replayer.Battle().Config().Sound = !nosound
+	err = replayer.JumpTo(jump)
 	if err != nil {
 		return nil, err
 	}
+
+	// the replay plays in its own world and its stats are never merged, quitting saves the loaded stats back unchanged
+	game := &GameData{world: replayer.Battle().World(), width: replay.Width, height: replay.Height, gameStats: comp.LoadStats()}
+	game.scenes = []Scene{scenes.NewReplayScene(replayer)}
+	ebiten.SetWindowSize(replay.Width, replay.Height)
 	return game, nil
 }
 
 func (g *GameData) switchToBattle(broadcast bool, controller *scenes.Controller, gameOptions *config.ConfigData) error {
-	if broadcast {
-		router.Broadcast(network.StartGameMessage{})
+	return g.startBattle(broadcast, controller, gameOptions, nil)
+}
+
+// loadBattle resumes the battle saved when the game last quit. A save that can't be resumed is only a warning, the title screen stays up.
+func (g *GameData) loadBattle(controller *scenes.Controller, gameOptions *config.ConfigData) error {
+	save, err := sim.LoadBattleSave(gameOptions.SavePath)
+	if err != nil {
+		fmt.Printf("WARN saved battle not loaded %v\n", err)
+		return nil
 	}
+	return g.startBattle(true, controller, gameOptions, save)
+}
+
+// startBattle starts a new battle, or resumes the saved one when there is a save
+func (g *GameData) startBattle(broadcast bool, controller *scenes.Controller, gameOptions *config.ConfigData, save *sim.BattleSave) error {
 	clientWorld := controller.GetClientWorld()
 	multiplayer := clientWorld != nil
 	battle, err := scenes.NewBattleScene(g.world, g.width, g.height, g.speed, g.gameStats, multiplayer, gameOptions, g.startingTowerLevel, g.switchToTitle)
 	if err != nil {
 		return err
 	}
-	battle.Init()
+	if save != nil {
+		if err := battle.Resume(save); err != nil {
+			fmt.Printf("WARN saved battle not resumed %v\n", err)
+			return nil
+		}
+		// a save is only resumed once, quitting saves the battle again
+		_ = os.Remove(gameOptions.SavePath)
+	} else {
+		battle.Init()
+	}
+	if broadcast {
+		router.Broadcast(network.StartGameMessage{})
+	}
 
 	g.scenes = []Scene{battle}
 	if multiplayer {
@@ -90,7 +155,7 @@ func (g *GameData) switchToTitle(gameStats *comp.GameStats, gameOptions *config.
 		g.gameStats.SaveStats()
 	}
 
-	title, err := scenes.NewTitleScene(g.world, g.width, g.height, g.gameStats, gameOptions, g.switchToBattle)
+	title, err := scenes.NewTitleScene(g.world, g.width, g.height, g.gameStats, gameOptions, g.switchToBattle, g.loadBattle)
 	if err != nil {
 		return err
 	}
@@ -106,6 +171,13 @@ func (g *GameData) Update() error {
 		if err := g.gameStats.SaveStats(); err != nil {
 			return err
 		}
+		for _, scene := range g.scenes {
+			if quitter, ok := scene.(Quitter); ok {
+				if err := quitter.Quit(); err != nil {
+					return err
+				}
+			}
+		}
 
 		return ebiten.Termination
 	}
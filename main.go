@@ -3,14 +3,26 @@ package main
 import (
 	"flag"
 	"log"
+	"os"
 	"tower-defense/config"
 	"tower-defense/game"
+	"tower-defense/sim"
 	"tower-defense/strategy"
 
 	"github.com/hajimehoshi/ebiten/v2"
 )
 
 func main() {
+	// the sim and sweep commands play headless games, they have their own flags
+	if len(os.Args) > 1 && os.Args[1] == "sim" {
+		runSim(os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "sweep" {
+		runSweep(os.Args[2:])
+		return
+	}
+
 	width := flag.Int("width", 600, "Board width in pixels")
 	height := flag.Int("height", 800, "Board height in pixels")
 	speed := flag.Int("speed", 60, "Ticks per second, min 0 max 60, + or - to adjust in game")
@@ -20,6 +32,13 @@ func main() {
 	computerLevel := flag.Int("complevel", 3, "Computer player difficulty level [1 slowest, 2 slow, 3 normal, 4 fast, 5 fastest]")
 	nosound := flag.Bool("nosound", false, "Turn off sound effects, S to toggle in game")
 	balancePath := flag.String("balance", "", "Path to game balance JSON config, empty for default")
+	scripted := flag.Bool("scripted", false, "Play scripted waves instead of random waves, can be changed in game options")
+	wavesPath := flag.String("waves", "", "Path to wave script JSON, empty for default, implies -scripted")
+	record := flag.String("record", "score/replay.json", "Path to save each battle's replay to when it ends, empty to not save replays")
+	savePath := flag.String("save", "score/save.json", "Path to save the battle in progress to when quitting, resumed with Load Game on the title screen, empty to not save battles")
+	replayPath := flag.String("replay", "", "Path to a replay to watch instead of playing")
+	jump := flag.Int("jump", 0, "Frame to start watching a replay from")
+	seed := flag.Uint64("seed", 0, "Random seed for every battle to replay the same game, 0 for a new seed each battle")
 
 	flag.Parse()
 
@@ -28,7 +47,29 @@ func main() {
 	if err != nil {
 		log.Fatal(err)
 	}
-	g, err := game.NewGame(*width, *height, *speed, *towerLevel, *debug, *computer, *nosound, balance)
+	waves, err := config.LoadWaveScript(*wavesPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := waves.ValidateCreepTypes(balance); err != nil {
+		log.Fatal(err)
+	}
+	if *replayPath != "" {
+		replay, err := sim.LoadReplay(*replayPath)
+		if err != nil {
+			log.Fatal(err)
+		}
+		g, err := game.NewReplayGame(replay, *jump, *nosound, balance, waves)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := ebiten.RunGame(g); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	g, err := game.NewGame(*width, *height, *speed, *towerLevel, *debug, *computer, *nosound, *scripted || *wavesPath != "", *seed, *record, *savePath, balance, waves)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -22,6 +22,17 @@ func RegisterComponenets() {
 	_ = esync.RegisterComponent(22, comp.BulletRenderData{}, comp.BulletRender)
 	_ = esync.RegisterComponent(23, comp.LevelData{}, comp.Level)
 	_ = esync.RegisterComponent(24, comp.BattleSceneState{}, comp.BattleState)
+	_ = esync.RegisterComponent(25, comp.StatusEffectsData{}, comp.StatusEffects)
+	_ = esync.RegisterComponent(26, comp.HazardData{}, comp.Hazard)
+	_ = esync.RegisterComponent(27, comp.HazardRenderData{}, comp.HazardRender)
+	_ = esync.RegisterComponent(28, comp.BossData{}, comp.Boss)
+	_ = esync.RegisterComponent(29, comp.FlyingData{}, comp.Flying)
+	_ = esync.RegisterComponent(30, comp.DefenseData{}, comp.Defense)
+	_ = esync.RegisterComponent(31, comp.VeterancyData{}, comp.Veterancy)
+	_ = esync.RegisterComponent(32, comp.AbilitiesData{}, comp.Abilities)
+	_ = esync.RegisterComponent(33, comp.AmmoData{}, comp.Ammo)
+	_ = esync.RegisterComponent(34, comp.AuraBuffData{}, comp.AuraBuff)
+	_ = esync.RegisterComponent(35, comp.BeamData{}, comp.Beam)
 }
 
 type ClientConnectMessage struct {
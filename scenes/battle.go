@@ -2,123 +2,98 @@ package scenes
 
 import (
 	"fmt"
-	"math"
-	"math/rand/v2"
+	"sync"
 
 	"tower-defense/assets"
 	comp "tower-defense/components"
 	"tower-defense/config"
 	"tower-defense/network"
-	"tower-defense/strategy"
+	"tower-defense/sim"
 	"tower-defense/util"
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/inpututil"
 	"github.com/hajimehoshi/ebiten/v2/text/v2"
-	"github.com/leap-fish/necs/esync/srvsync"
 	"github.com/leap-fish/necs/router"
 	"github.com/yohamta/donburi"
-	"github.com/yohamta/donburi/filter"
 )
 
 type EndGameCallBack func(*comp.GameStats, *config.ConfigData) error
+
+// BattleScene handles input, multiplayer and drawing for a battle, the game itself is run by the sim battle
 type BattleScene struct {
 	world              donburi.World
 	width              int
 	height             int
-	speed              int
-	creepTimer         int
-	tickCounter        int
-	computerTicker     int
-	startingTowerLevel int
 	multiplayer        bool
 	config             *config.ConfigData
+	battle             *sim.Battle
 	battleState        *comp.BattleSceneState
 	gameStats          *comp.GameStats
 	gameOptions        *config.ConfigData
 	endGameCallback    EndGameCallBack
 	superCreepCooldown *util.CooldownTimer
+	// replaySaved is set once this battle's replay has been written, so it is only saved once
+	replaySaved bool
+	// pendingSuperCreeps counts super creeps received from the network and not yet spawned,
+	// they arrive on the router's goroutine and are spawned on the game loop
+	pendingSuperCreeps int
+	pendingLock        sync.Mutex
 }
 
-const minSpeed = 0
-const maxSpeed = 60
-
 func NewBattleScene(world donburi.World, width, height, speed int, gameStats *comp.GameStats, multiplayer bool, gameOptions *config.ConfigData, startingTowerLevel int, endGameCallback EndGameCallBack) (*BattleScene, error) {
-	_, err := comp.NewBoard(world, width, height)
+	battle, err := sim.NewBattle(world, width, height, speed, gameStats, gameOptions, startingTowerLevel)
 	if err != nil {
 		return nil, err
 	}
-	bss := &comp.BattleSceneState{}
-
-	if speed < minSpeed {
-		speed = max(1, minSpeed)
-	} else if speed > maxSpeed {
-		speed = maxSpeed
-	}
 
 	balance := config.GetBalance(world)
 	return &BattleScene{
 		world:              world,
 		width:              width,
 		height:             height,
-		speed:              speed,
-		creepTimer:         balance.Wave.MaxCreepTimer - balance.Wave.StartCreepTimer,
 		multiplayer:        multiplayer,
 		config:             gameOptions,
-		battleState:        bss,
-		gameStats:          comp.NewGameStats(gameStats),
+		battle:             battle,
+		battleState:        battle.State(),
+		gameStats:          battle.Stats(),
 		gameOptions:        gameOptions,
 		endGameCallback:    endGameCallback,
 		superCreepCooldown: util.NewCooldownTimer(balance.Multiplayer.SuperCreepCooldown),
-		startingTowerLevel: startingTowerLevel,
 	}, nil
 }
 
 func (b *BattleScene) Init() error {
-	b.Clear()
-
-	entity := b.world.Create(comp.BattleState)
-	err := srvsync.NetworkSync(b.world, &entity, comp.BattleState)
+	err := b.battle.Init()
 	if err != nil {
 		return err
 	}
-	comp.BattleState.Set(b.world.Entry(entity), b.battleState)
+	b.listenForSuperCreeps()
+	return nil
+}
 
-	err = comp.NewPlayer(b.world, b.startingTowerLevel)
+// Resume plays on from a saved battle instead of starting a new one
+func (b *BattleScene) Resume(save *sim.BattleSave) error {
+	err := b.battle.Resume(save)
 	if err != nil {
 		return err
 	}
+	b.listenForSuperCreeps()
+	return nil
+}
 
+func (b *BattleScene) listenForSuperCreeps() {
 	if b.multiplayer && len(router.Peers()) > 0 {
 		router.On(func(sender *router.NetworkClient, message network.CreepMessage) {
-			comp.NewSuperCreep(b.world, 0, 0)
+			b.pendingLock.Lock()
+			defer b.pendingLock.Unlock()
+			b.pendingSuperCreeps++
 		})
 	}
-	return nil
 }
 
 func (b *BattleScene) Clear() error {
-	b.battleState.GameOver = false
-	b.battleState.Paused = false
-	balance := config.GetBalance(b.world)
-	b.creepTimer = balance.Wave.MaxCreepTimer - balance.Wave.StartCreepTimer
-	b.tickCounter = 0
-	b.computerTicker = 0
-
-	b.gameStats.Reset()
-	// HACK remove gloabal variable gameStats
-	comp.SetGameStats(b.gameStats)
-
-	query := donburi.NewQuery(filter.Or(
-		filter.Contains(comp.Bullet),
-		filter.Contains(comp.Player),
-		filter.Contains(comp.Tower),
-		filter.Contains(comp.Creep),
-		filter.Contains(comp.BattleState),
-	))
-	query.Each(b.world, func(e *donburi.Entry) {
-		e.Remove()
-	})
+	b.battle.Clear()
 	return nil
 }
 
@@ -128,18 +103,26 @@ func (b *BattleScene) Update() error {
 
 	if inpututil.IsKeyJustPressed(ebiten.KeyR) {
 		b.gameStats.FinalizeTime()
+		b.saveReplay()
 		b.endGameCallback(b.gameStats, b.gameOptions)
 	}
 
 	if b.battleState.GameOver {
+		b.saveReplay()
 		return nil
 	}
 
 	if inpututil.IsKeyJustPressed(ebiten.KeyEqual) {
-		b.speed = (min(b.speed+5, maxSpeed))
+		err := b.battle.Act(comp.PlayerAction{Kind: comp.SpeedAction, Value: min(b.battle.Speed()+5, sim.MaxSpeed)})
+		if err != nil {
+			return err
+		}
 	}
 	if inpututil.IsKeyJustPressed(ebiten.KeyMinus) {
-		b.speed = (max(b.speed-5, minSpeed))
+		err := b.battle.Act(comp.PlayerAction{Kind: comp.SpeedAction, Value: max(b.battle.Speed()-5, sim.MinSpeed)})
+		if err != nil {
+			return err
+		}
 	}
 	if inpututil.IsKeyJustPressed(ebiten.KeyL) {
 		b.config.GridLines = !b.config.GridLines
@@ -155,7 +138,10 @@ func (b *BattleScene) Update() error {
 	}
 
 	if inpututil.IsKeyJustPressed(ebiten.KeyP) || inpututil.IsKeyJustPressed(ebiten.KeySpace) {
-		b.battleState.Paused = !b.battleState.Paused
+		err := b.battle.Act(comp.PlayerAction{Kind: comp.PauseAction})
+		if err != nil {
+			return err
+		}
 	}
 
 	if b.battleState.Paused {
@@ -164,9 +150,11 @@ func (b *BattleScene) Update() error {
 
 	if !b.config.Computer {
 		// update player separately from other entities to allow user interactions outside of speed controls
-		err := player.UserSpeedUpdate(pe)
-		if err != nil {
-			return err
+		for _, action := range comp.ReadPlayerInput() {
+			err := b.battle.Act(action)
+			if err != nil {
+				return err
+			}
 		}
 	}
 	if b.multiplayer {
@@ -180,6 +168,10 @@ func (b *BattleScene) Update() error {
 				if player.Money >= cost {
 					peers[0].SendMessage(network.CreepMessage{Count: 1})
 					b.superCreepCooldown.StartCooldown()
+					err := b.battle.Act(comp.PlayerAction{Kind: comp.SendSuperCreepAction})
+					if err != nil {
+						return err
+					}
 				} else {
 					if b.config.Debug {
 						fmt.Printf("Not enough money to send a creep %v, remaining %v\n", cost, player.Money)
@@ -192,164 +184,64 @@ func (b *BattleScene) Update() error {
 		}
 		b.superCreepCooldown.IncrementTicker()
 	}
-	if b.config.Computer {
-		// TODO scale with game speed? or a difficulty setting
-		if b.computerTicker%strategy.TimeScaler == 0 {
-			acted, err := strategy.Update(b.world)
-			if err != nil {
-				return err
-			}
-			if acted {
-				b.computerTicker = 1
-			} else {
-				b.computerTicker = 0
-			}
-		} else {
-			b.computerTicker++
-		}
+
+	err := b.spawnSuperCreeps()
+	if err != nil {
+		return err
 	}
+	// the computer player, entities and waves all run in the sim battle
+	return b.battle.Update(ebiten.TPS())
+}
 
-	if b.speed != 0 && float32(b.tickCounter) > float32(ebiten.TPS())/float32(b.speed) {
-		b.tickCounter = 0
-		err := b.UpdateEntities()
-		if err != nil {
-			return err
-		}
-		// have player attack at game speed
-		err = player.GameSpeedUpdate(pe)
+// spawnSuperCreeps spawns the super creeps received since the last update, recorded like the player's own actions
+// so the replay gets the same creeps on the same frame
+func (b *BattleScene) spawnSuperCreeps() error {
+	b.pendingLock.Lock()
+	pending := b.pendingSuperCreeps
+	b.pendingSuperCreeps = 0
+	b.pendingLock.Unlock()
+
+	for range pending {
+		err := b.battle.Act(comp.PlayerAction{Kind: comp.SuperCreepAction})
 		if err != nil {
 			return err
 		}
-
-	} else {
-		b.tickCounter++
 	}
-
-	balance := config.GetBalance(b.world)
-	b.gameStats.UpdateHighs(player.GetScore(), player.GetCreepLevel(balance), player.GetMaxTowerLevel(balance))
-
 	return nil
 }
-func (b *BattleScene) UpdateEntities() error {
-	query := donburi.NewQuery(
-		filter.And(
-			filter.Or(
-				filter.Contains(comp.Creep),
-				filter.Contains(comp.Tower),
-				filter.Contains(comp.Bullet),
-			),
-		),
-	)
-	var err error = nil
-	entries := make([]*donburi.Entry, 0, query.Count(b.world))
-	query.Each(b.world, func(entry *donburi.Entry) {
-		entries = append(entries, entry)
-	})
-
-	for _, entry := range entries {
-		if !entry.Valid() {
-			continue
-		}
-		if entry.HasComponent(comp.Creep) {
-			creep := comp.Creep.Get(entry)
-			err = creep.Update(entry)
-			if err != nil {
-				return err
-			}
-
-		}
-		if entry.HasComponent(comp.Tower) {
-			tower := comp.Tower.Get(entry)
-			err = tower.Update(entry)
-			if err != nil {
-				return err
-			}
-
-		}
 
-		if entry.HasComponent(comp.Bullet) {
-			b := comp.Bullet.Get(entry)
-			err = b.Update(entry)
-			if err != nil {
-				return err
-			}
-		}
-	}
-	// if the player's health drops to 0 then it is dead and the game is over
-	pe := comp.Player.MustFirst(b.world)
-	player := comp.Player.Get(pe)
-	playerHealth := comp.Health.Get(pe)
-	if playerHealth.Health <= 0 {
-		player.Kill()
-		b.gameStats.IncrementStat("PlayerDeaths")
-		b.End()
+// saveReplay writes the battle's replay to the configured path the first time it is called for the battle.
+// A replay that can't be written is only a warning, it shouldn't end the game.
+func (b *BattleScene) saveReplay() {
+	if b.replaySaved || b.config.ReplayPath == "" {
+		return
 	}
-
-	balance := config.GetBalance(b.world)
-	creepLevel := player.GetCreepLevel(balance)
-	wave := balance.Wave
-	b.creepTimer += max((creepLevel/wave.TimerLevelDivisor)+1, wave.MinCreepTick)
-	if b.creepTimer >= wave.MaxCreepTimer-creepLevel {
-		query := donburi.NewQuery(filter.Contains(comp.Creep))
-		count := query.Count(b.world)
-		if count <= wave.MaxCreepCount {
-			count, err := b.SpawnCreeps(creepLevel)
-			if err != nil {
-				return err
-			}
-			b.gameStats.UpdateStat("CreepsSpawned", count)
-			player.AddMoney(wave.SpawnIncomePerCreep * count)
-			b.creepTimer = 0
-		} else {
-			player.AddMoney(wave.OverflowIncome)
-		}
+	b.replaySaved = true
+	if err := b.battle.Replay().Save(b.config.ReplayPath); err != nil {
+		fmt.Printf("WARN replay not saved to %s %v\n", b.config.ReplayPath, err)
 	}
-
-	return err
 }
 
-func (b *BattleScene) SpawnCreeps(creepLevel int) (int, error) {
-	b.gameStats.IncrementStat("CreepWaves")
-	balance := config.GetBalance(b.world).Wave
-	// every configured number of waves, creep level increases by 1 without giving extra tower levels to the player
-	extraCreepLevel := int(math.Floor(float64(b.gameStats.GetStat("CreepWaves")) / float64(balance.ExtraCreepLevelWaves)))
-
-	levelBump := float32(creepLevel+extraCreepLevel) / float32(balance.LevelBumpDivisor)
-
-	val := rand.Float32() - levelBump
-	var count = 1
-	for _, spawnChance := range balance.SpawnChances {
-		if val < spawnChance.Chance {
-			count = spawnChance.Count
-			break
-		}
+// saveBattle writes the battle in progress to the configured path so it can be resumed, a battle that is over isn't saved.
+// Like replays a battle that can't be saved is only a warning.
+func (b *BattleScene) saveBattle() {
+	if b.config.SavePath == "" || b.battleState.GameOver {
+		return
 	}
-
-	for i := 0; i < count; i++ {
-		be := comp.Board.MustFirst(b.world)
-		board := comp.Board.Get(be)
-
-		x := rand.IntN(board.Width/count) + board.Width/count*(i)
-		y := balance.SpawnBorder
-		if x < balance.SpawnBorder {
-			x = balance.SpawnBorder
-		} else if x > board.Width-balance.SpawnBorder {
-			x = board.Width - balance.SpawnBorder
-		}
-		_, err := comp.NewCreep(b.world, x, y, creepLevel)
-		if err != nil {
-			return i, err
-		}
+	save, err := b.battle.SaveState()
+	if err == nil {
+		err = save.Save(b.config.SavePath)
+	}
+	if err != nil {
+		fmt.Printf("WARN battle not saved to %s %v\n", b.config.SavePath, err)
 	}
-	return count, nil
 }
 
-func (b *BattleScene) End() {
-	if b.config.Sound {
-		assets.PlaySound("killed")
-	}
-	b.battleState.GameOver = true
-	b.gameStats.FinalizeTime()
+// Quit saves the battle being played and its replay when the game exits
+func (b *BattleScene) Quit() error {
+	b.saveBattle()
+	b.saveReplay()
+	return nil
 }
 
 func (b *BattleScene) Draw(screen *ebiten.Image) {
@@ -368,6 +260,7 @@ func (b *BattleScene) DrawText(screen *ebiten.Image) {
 	_ = comp.DrawTextLines(screen, assets.InfoFace, str, width, nextY, text.AlignEnd, text.AlignStart)
 
 	b.battleState.Draw(screen, width, height, b.config, b.gameStats)
+	comp.DrawBossBars(screen, b.world, width)
 
 	if b.multiplayer && b.superCreepCooldown.InCooldown {
 		str := fmt.Sprintf("Super Creep CD %d", b.superCreepCooldown.GetDisplay())
@@ -375,6 +268,6 @@ func (b *BattleScene) DrawText(screen *ebiten.Image) {
 	}
 
 	if b.config.Debug {
-		comp.DrawTextLines(screen, assets.InfoFace, fmt.Sprintf("Speed %v\nTPS %2.1f\nCreep Timer %d", b.speed, ebiten.ActualTPS(), b.creepTimer), comp.TextBorder, 400, text.AlignStart, text.AlignStart)
+		comp.DrawTextLines(screen, assets.InfoFace, fmt.Sprintf("Speed %v\nTPS %2.1f\nCreep Timer %d\nScripted Waves %v", b.battle.Speed(), ebiten.ActualTPS(), b.battle.CreepTimer(), b.battle.ScriptedWavesRunning()), comp.TextBorder, 400, text.AlignStart, text.AlignStart)
 	}
 }
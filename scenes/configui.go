@@ -24,7 +24,7 @@ func IsModalOpen() bool {
 
 type GameOptionsCallback func(gameOptions *config.ConfigData)
 
-func initUI(gameOptions *config.ConfigData, newGameCallback NewGameCallback, gameOptionsCallback GameOptionsCallback) *ebitenui.UI {
+func initUI(gameOptions *config.ConfigData, newGameCallback NewGameCallback, loadGameCallback LoadGameCallback, gameOptionsCallback GameOptionsCallback) *ebitenui.UI {
 	ui := &ebitenui.UI{}
 	buttonImage := loadButtonImage()
 	face := assets.GoFace
@@ -58,6 +58,19 @@ func initUI(gameOptions *config.ConfigData, newGameCallback NewGameCallback, gam
 		}),
 	)
 	buttonContainer.AddChild(buttonStart)
+	if loadGameCallback != nil {
+		buttonLoad := widget.NewButton(
+			widget.ButtonOpts.Image(buttonImage),
+			widget.ButtonOpts.Text("Load Game", &face, &widget.ButtonTextColor{
+				Idle: color.NRGBA{0xdf, 0xf4, 0xff, 0xff},
+			}),
+			widget.ButtonOpts.TextPadding(widget.NewInsetsSimple(5)),
+			widget.ButtonOpts.ClickedHandler(func(args *widget.ButtonClickedEventArgs) {
+				loadGameCallback(&controller, gameOptions)
+			}),
+		)
+		buttonContainer.AddChild(buttonLoad)
+	}
 	buttonMultiplayer := widget.NewButton(
 		widget.ButtonOpts.Image(buttonImage),
 		widget.ButtonOpts.Text("Game Options", &face, &widget.ButtonTextColor{
@@ -114,7 +127,7 @@ func openWindow(ui *ebitenui.UI, gameOptions *config.ConfigData, callback GameOp
 		widget.ContainerOpts.Layout(
 			widget.NewGridLayout(
 				widget.GridLayoutOpts.Columns(2),
-				widget.GridLayoutOpts.Stretch([]bool{false, true}, []bool{false, false, false, false}),
+				widget.GridLayoutOpts.Stretch([]bool{false, true}, []bool{false, false, false, false, false}),
 				widget.GridLayoutOpts.Padding(padding),
 				widget.GridLayoutOpts.Spacing(0, 10),
 			),
@@ -224,8 +237,22 @@ func openWindow(ui *ebitenui.UI, gameOptions *config.ConfigData, callback GameOp
 		widget.CheckboxOpts.InitialState(initialState),
 		widget.CheckboxOpts.Text("GridLines", &face, &widget.LabelColor{Idle: color.NRGBA{254, 255, 255, 255}}),
 	)
+	if gameOptions.ScriptedWaves {
+		initialState = widget.WidgetChecked
+	} else {
+		initialState = widget.WidgetUnchecked
+	}
+	chkScriptedWaves := widget.NewCheckbox(
+		widget.CheckboxOpts.Spacing(10),
+		widget.CheckboxOpts.Image(loadCheckboxGraphicImage()),
+		widget.CheckboxOpts.InitialState(initialState),
+		widget.CheckboxOpts.Text("Scripted Waves", &face, &widget.LabelColor{Idle: color.NRGBA{254, 255, 255, 255}}),
+	)
 	windowContainer.AddChild(chkDebug)
 	windowContainer.AddChild(chkGridLines)
+	windowContainer.AddChild(chkScriptedWaves)
+	// keep the buttons in the first column
+	windowContainer.AddChild(widget.NewContainer())
 
 	bc := widget.NewContainer(
 		widget.ContainerOpts.Layout(widget.NewRowLayout(
@@ -246,6 +273,7 @@ func openWindow(ui *ebitenui.UI, gameOptions *config.ConfigData, callback GameOp
 			}
 			gameOptions.Debug = chkDebug.State() == widget.WidgetChecked
 			gameOptions.GridLines = chkGridLines.State() == widget.WidgetChecked
+			gameOptions.ScriptedWaves = chkScriptedWaves.State() == widget.WidgetChecked
 			callback(gameOptions)
 			rw()
 		}),
@@ -278,7 +306,7 @@ func openWindow(ui *ebitenui.UI, gameOptions *config.ConfigData, callback GameOp
 		widget.WindowOpts.Modal(),
 		widget.WindowOpts.Contents(windowContainer),
 		widget.WindowOpts.TitleBar(titleContainer, 30),
-		widget.WindowOpts.MinSize(400, 220),
+		widget.WindowOpts.MinSize(400, 250),
 		widget.WindowOpts.MaxSize(500, 400),
 		widget.WindowOpts.ClosedHandler(func(args *widget.WindowClosedEventArgs) {
 			isModalOpen = false
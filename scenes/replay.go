@@ -0,0 +1,101 @@
+package scenes
+
+import (
+	"fmt"
+
+	"tower-defense/assets"
+	comp "tower-defense/components"
+	"tower-defense/sim"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+)
+
+// replayRates are how many frames each playback speed steps per update
+var replayRates = []int{1, 2, 4, 8, 16, 32}
+
+// replayJumpFrames is how far the arrow keys jump, 10 seconds of play
+const replayJumpFrames = 600
+
+// ReplayScene plays back a recorded battle with playback controls instead of live input
+type ReplayScene struct {
+	replayer *sim.Replayer
+	paused   bool
+	rate     int
+}
+
+func NewReplayScene(replayer *sim.Replayer) *ReplayScene {
+	return &ReplayScene{replayer: replayer}
+}
+
+func (r *ReplayScene) Update() error {
+	battle := r.replayer.Battle()
+	config := battle.Config()
+
+	if inpututil.IsKeyJustPressed(ebiten.KeyL) {
+		config.GridLines = !config.GridLines
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyD) {
+		config.Debug = !config.Debug
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyS) {
+		config.Sound = !config.Sound
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyT) {
+		config.ShowStats = !config.ShowStats
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyP) || inpututil.IsKeyJustPressed(ebiten.KeySpace) {
+		r.paused = !r.paused
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyEqual) {
+		r.rate = min(r.rate+1, len(replayRates)-1)
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyMinus) {
+		r.rate = max(r.rate-1, 0)
+	}
+
+	if inpututil.IsKeyJustPressed(ebiten.KeyR) || inpututil.IsKeyJustPressed(ebiten.KeyHome) {
+		return r.replayer.Restart()
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyArrowRight) {
+		return r.replayer.JumpTo(battle.Frame() + replayJumpFrames)
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyArrowLeft) {
+		return r.replayer.JumpTo(max(battle.Frame()-replayJumpFrames, 0))
+	}
+
+	if r.paused {
+		return nil
+	}
+	for range replayRates[r.rate] {
+		err := r.replayer.Step()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *ReplayScene) Draw(screen *ebiten.Image) {
+	battle := r.replayer.Battle()
+	comp.DrawBoard(screen, battle.World(), battle.Config(), r.DrawText)
+}
+
+func (r *ReplayScene) DrawText(screen *ebiten.Image) {
+	battle := r.replayer.Battle()
+	board := comp.Board.Get(comp.Board.MustFirst(battle.World()))
+	width, height := float64(board.Width), float64(board.Height)
+
+	battle.State().Draw(screen, width, height, battle.Config(), battle.Stats())
+	comp.DrawBossBars(screen, battle.World(), width)
+
+	str := fmt.Sprintf("REPLAY %d/%d x%d", battle.Frame(), r.replayer.Replay().Frames, replayRates[r.rate])
+	if r.paused {
+		str += " PAUSED"
+	} else if r.replayer.Done() {
+		str += " ENDED"
+	}
+	str += "\nP pause, +/- speed, arrows jump, R restart"
+	_ = comp.DrawTextLines(screen, assets.InfoFace, str, width, comp.TextBorder, text.AlignEnd, text.AlignStart)
+}
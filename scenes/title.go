@@ -2,12 +2,15 @@ package scenes
 
 import (
 	"fmt"
+	"os"
+	"strings"
 
 	"tower-defense/assets"
 	comp "tower-defense/components"
 	"tower-defense/config"
 
 	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
 	"github.com/hajimehoshi/ebiten/v2/text/v2"
 	"github.com/yohamta/donburi"
 
@@ -20,17 +23,26 @@ type TitleScene struct {
 	gameStats       *comp.GameStats
 	gameOptions     *config.ConfigData
 	newGameCallback NewGameCallback
-	world           donburi.World
-	ui              *ebitenui.UI
+	// loadGameCallback resumes the saved battle, nil when there isn't one
+	loadGameCallback LoadGameCallback
+	world            donburi.World
+	ui               *ebitenui.UI
 }
 
 var controller = Controller{}
 
 type NewGameCallback func(broadcast bool, controller *Controller, gameOptions *config.ConfigData) error
 
-func NewTitleScene(world donburi.World, width, height int, gameStats *comp.GameStats, gameOptions *config.ConfigData, newGameCallback NewGameCallback) (*TitleScene, error) {
+// LoadGameCallback resumes the battle saved when the game last quit
+type LoadGameCallback func(controller *Controller, gameOptions *config.ConfigData) error
+
+func NewTitleScene(world donburi.World, width, height int, gameStats *comp.GameStats, gameOptions *config.ConfigData, newGameCallback NewGameCallback, loadGameCallback LoadGameCallback) (*TitleScene, error) {
 	title := &TitleScene{world: world, width: width, height: height, gameStats: gameStats, gameOptions: gameOptions, newGameCallback: newGameCallback}
-	title.ui = initUI(title.gameOptions, newGameCallback, title.handleOptions)
+	// only offer to load a game when there is a saved battle
+	if _, err := os.Stat(gameOptions.SavePath); gameOptions.SavePath != "" && err == nil {
+		title.loadGameCallback = loadGameCallback
+	}
+	title.ui = initUI(title.gameOptions, newGameCallback, title.loadGameCallback, title.handleOptions)
 	return title, nil
 }
 
@@ -49,6 +61,9 @@ func (t *TitleScene) Update() error {
 	if !IsModalOpen() && ebiten.IsKeyPressed(ebiten.KeySpace) {
 		return t.newGameCallback(true, &controller, t.gameOptions)
 	}
+	if !IsModalOpen() && t.loadGameCallback != nil && inpututil.IsKeyJustPressed(ebiten.KeyL) {
+		return t.loadGameCallback(&controller, t.gameOptions)
+	}
 
 	return nil
 }
@@ -72,26 +87,36 @@ func (t *TitleScene) Draw(screen *ebiten.Image) {
 
 	balance := config.GetBalance(t.world)
 	tower := balance.Tower
-	defaultTowerCost := tower.Costs[tower.DefaultType]
-	healCost := defaultTowerCost / tower.HealCostDivisor
-	str = fmt.Sprintf("Click to place towers Cost: $%d", defaultTowerCost)
+	str = "Click to place towers"
 	nextY = comp.DrawTextLines(screen, assets.ScoreFace, str, width, 250, text.AlignCenter, text.AlignStart)
 
-	str = fmt.Sprintf("Mouse over a tower\nPress H to heal to full Cost: $%d\nPress U to upgrade and heal to full Cost: $%d\nMax upgrade level is %d (+1 for every %d upgrades)", healCost, defaultTowerCost, balance.Player.MaxTowerInitialLevel, balance.Player.MaxTowerLevelsPerBonus)
+	types := make([]string, len(tower.Types))
+	for i, towerType := range tower.Types {
+		types[i] = fmt.Sprintf("%d %s $%d", i+1, towerType.Name, tower.Costs[towerType.Name])
+	}
+	str = fmt.Sprintf("Press 1-%d to choose the tower type\n%s", len(types), strings.Join(types, "  "))
 	nextY = comp.DrawTextLines(screen, assets.InfoFace, str, width, nextY, text.AlignCenter, text.AlignStart)
 
-	towerImage := assets.GetImage("tower")
-	opts = &ebiten.DrawImageOptions{}
-	opts.GeoM.Translate(halfWidth-float64(towerImage.Bounds().Dx()/2), nextY)
-	screen.DrawImage(towerImage, opts)
-	nextY += float64(towerImage.Bounds().Dy()) + 10
+	str = fmt.Sprintf("Mouse over a tower\nPress H to heal to full Cost: 1/%d of the tower cost\nPress U to upgrade and heal to full Cost: the tower cost\nPress G to change who the tower targets\nMax upgrade level is %d (+1 for every %d upgrades)", tower.HealCostDivisor, balance.Player.MaxTowerInitialLevel, balance.Player.MaxTowerLevelsPerBonus)
+	nextY = comp.DrawTextLines(screen, assets.InfoFace, str, width, nextY, text.AlignCenter, text.AlignStart)
+
+	const towerSize = 48
+	x := halfWidth - float64(towerSize*len(tower.Types)/2)
+	for _, towerType := range tower.Types {
+		towerImage := assets.GetImage(towerType.Sprite)
+		opts = &ebiten.DrawImageOptions{}
+		opts.GeoM.Translate(x, nextY)
+		screen.DrawImage(towerImage, opts)
+		x += float64(towerImage.Bounds().Dx())
+	}
+	nextY += towerSize + 10
 
 	str = "Protect your base from aliens and earn $$"
 	nextY = comp.DrawTextLines(screen, assets.ScoreFace, str, width, nextY, text.AlignCenter, text.AlignStart)
 
 	const creepCount = 4
 	const creepSize = 48
-	x := halfWidth - creepSize*creepCount/2
+	x = halfWidth - creepSize*creepCount/2
 	for i := 1; i <= creepCount; i++ {
 		creepImage := assets.GetImage(fmt.Sprintf("creep%v", i))
 		opts = &ebiten.DrawImageOptions{}
@@ -102,7 +127,11 @@ func (t *TitleScene) Draw(screen *ebiten.Image) {
 
 	nextY = 600
 	str = "Click 'Start Game' or press space to start"
-	_ = comp.DrawTextLines(screen, assets.ScoreFace, str, width, nextY, text.AlignCenter, text.AlignStart)
+	nextY = comp.DrawTextLines(screen, assets.ScoreFace, str, width, nextY, text.AlignCenter, text.AlignStart)
+	if t.loadGameCallback != nil {
+		str = "Click 'Load Game' or press L to resume your saved battle"
+		_ = comp.DrawTextLines(screen, assets.InfoFace, str, width, nextY, text.AlignCenter, text.AlignStart)
+	}
 
 	// draw UI elements
 	t.ui.Draw(screen)
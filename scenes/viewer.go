@@ -70,4 +70,5 @@ func (v *ViewerScene) DrawText(image *ebiten.Image) {
 		bss := comp.BattleState.Get(bssEntry)
 		bss.Draw(image, float64(v.width), float64(v.height), v.config, nil)
 	}
+	comp.DrawBossBars(image, v.world, float64(v.width))
 }
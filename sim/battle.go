@@ -0,0 +1,432 @@
+// Package sim runs the battle loop without a window, input or audio, so the battle scene and headless games share the same rules
+package sim
+
+import (
+	"math"
+
+	"tower-defense/assets"
+	comp "tower-defense/components"
+	"tower-defense/config"
+	"tower-defense/strategy"
+
+	"github.com/leap-fish/necs/esync/srvsync"
+	"github.com/yohamta/donburi"
+	"github.com/yohamta/donburi/filter"
+)
+
+// DefaultTPS is the frame rate the game runs at, headless games step frames at this rate without waiting on a clock
+const DefaultTPS = 60
+
+const MinSpeed = 0
+const MaxSpeed = 60
+
+// Battle is one battle's game state and rules: waves, entities, the player, and the computer strategy when enabled
+type Battle struct {
+	world          donburi.World
+	speed          int
+	creepTimer     int
+	tickCounter    int
+	computerTicker int
+	// computerTimeScaler is how often the computer player acts, taken from its difficulty level when the battle is created
+	computerTimeScaler int
+	ticks              int
+	// frame counts the battle updates that ran, actions are recorded at the frame they happened on
+	frame int
+	// startSpeed is the game speed the battle started at, for its replay
+	startSpeed         int
+	startingTowerLevel int
+	config             *config.ConfigData
+	battleState        *comp.BattleSceneState
+	gameStats          *comp.GameStats
+	// waves plays the wave script when scripted waves are on, nil for random waves
+	waves *comp.WaveRunner
+	// actions is everything the player did this battle, for its replay
+	actions []RecordedAction
+}
+
+func NewBattle(world donburi.World, width, height, speed int, gameStats *comp.GameStats, gameOptions *config.ConfigData, startingTowerLevel int) (*Battle, error) {
+	_, err := comp.NewBoard(world, width, height)
+	if err != nil {
+		return nil, err
+	}
+	if speed < MinSpeed {
+		speed = max(1, MinSpeed)
+	} else if speed > MaxSpeed {
+		speed = MaxSpeed
+	}
+
+	balance := config.GetBalance(world)
+	return &Battle{
+		world:              world,
+		speed:              speed,
+		creepTimer:         balance.Wave.MaxCreepTimer - balance.Wave.StartCreepTimer,
+		config:             gameOptions,
+		battleState:        &comp.BattleSceneState{},
+		gameStats:          comp.NewGameStats(gameStats),
+		startingTowerLevel: startingTowerLevel,
+		computerTimeScaler: strategy.TimeScaler,
+	}, nil
+}
+
+// Init clears any earlier battle and creates the synced battle state and the player
+func (b *Battle) Init() error {
+	b.Clear()
+
+	err := b.newBattleState()
+	if err != nil {
+		return err
+	}
+	return comp.NewPlayer(b.world, b.startingTowerLevel)
+}
+
+func (b *Battle) newBattleState() error {
+	entity := b.world.Create(comp.BattleState)
+	err := srvsync.NetworkSync(b.world, &entity, comp.BattleState)
+	if err != nil {
+		return err
+	}
+	comp.BattleState.Set(b.world.Entry(entity), b.battleState)
+	return nil
+}
+
+func (b *Battle) Clear() {
+	b.battleState.GameOver = false
+	b.battleState.Paused = false
+	b.battleState.Wave = 0
+	b.battleState.Seed = b.config.Seed
+	if b.battleState.Seed == 0 {
+		b.battleState.Seed = comp.NewSeed()
+	}
+	comp.SeedRandom(b.world, b.battleState.Seed)
+	balance := config.GetBalance(b.world)
+	b.creepTimer = balance.Wave.MaxCreepTimer - balance.Wave.StartCreepTimer
+	b.waves = nil
+	if b.config.ScriptedWaves {
+		b.waves = comp.NewWaveRunner(config.GetWaveScript(b.world))
+	}
+	b.tickCounter = 0
+	b.computerTicker = 0
+	b.ticks = 0
+	b.frame = 0
+	b.startSpeed = b.speed
+	b.actions = nil
+
+	b.gameStats.Reset()
+	// HACK remove gloabal variable gameStats
+	comp.SetGameStats(b.gameStats)
+
+	query := donburi.NewQuery(filter.Or(
+		filter.Contains(comp.Bullet),
+		filter.Contains(comp.Beam),
+		filter.Contains(comp.Hazard),
+		filter.Contains(comp.Player),
+		filter.Contains(comp.Tower),
+		filter.Contains(comp.Creep),
+		filter.Contains(comp.BattleState),
+	))
+	query.Each(b.world, func(e *donburi.Entry) {
+		e.Remove()
+	})
+	comp.MarkNavGridDirty(b.world)
+}
+
+func (b *Battle) World() donburi.World {
+	return b.world
+}
+
+func (b *Battle) State() *comp.BattleSceneState {
+	return b.battleState
+}
+
+func (b *Battle) Stats() *comp.GameStats {
+	return b.gameStats
+}
+
+func (b *Battle) Config() *config.ConfigData {
+	return b.config
+}
+
+// Seed is the random seed the battle is playing
+func (b *Battle) Seed() uint64 {
+	return b.battleState.Seed
+}
+
+func (b *Battle) Speed() int {
+	return b.speed
+}
+
+// SetSpeed changes the game speed, kept between MinSpeed and MaxSpeed
+func (b *Battle) SetSpeed(speed int) {
+	b.speed = max(MinSpeed, min(speed, MaxSpeed))
+}
+
+// Frame is the number of battle updates that have run, paused and game over frames don't count
+func (b *Battle) Frame() int {
+	return b.frame
+}
+
+// Ticks is the number of game speed ticks the battle has run
+func (b *Battle) Ticks() int {
+	return b.ticks
+}
+
+func (b *Battle) CreepTimer() int {
+	return b.creepTimer
+}
+
+// ScriptedWavesRunning reports whether the wave script is still playing
+func (b *Battle) ScriptedWavesRunning() bool {
+	return b.waves != nil && !b.waves.Done()
+}
+
+// Update runs one frame at tps frames per second: the computer player when it is on,
+// then the entities whenever the game speed says a tick is due
+func (b *Battle) Update(tps int) error {
+	if b.battleState.GameOver || b.battleState.Paused {
+		return nil
+	}
+	pe := comp.Player.MustFirst(b.world)
+	player := comp.Player.Get(pe)
+
+	if b.config.Computer {
+		// TODO scale with game speed? or a difficulty setting
+		if b.computerTicker%b.computerTimeScaler == 0 {
+			acted, err := strategy.Update(b.world)
+			if err != nil {
+				return err
+			}
+			if acted {
+				b.computerTicker = 1
+			} else {
+				b.computerTicker = 0
+			}
+		} else {
+			b.computerTicker++
+		}
+	}
+
+	if b.speed != 0 && float32(b.tickCounter) > float32(tps)/float32(b.speed) {
+		b.tickCounter = 0
+		err := b.UpdateEntities()
+		if err != nil {
+			return err
+		}
+		// have player attack at game speed
+		err = player.GameSpeedUpdate(pe)
+		if err != nil {
+			return err
+		}
+		b.ticks++
+	} else {
+		b.tickCounter++
+	}
+
+	balance := config.GetBalance(b.world)
+	b.gameStats.UpdateHighs(player.GetScore(), player.GetCreepLevel(balance), player.GetMaxTowerLevel(balance))
+	b.frame++
+	return nil
+}
+
+// Act records the action at the current frame and carries it out, actions must come before the frame's Update
+func (b *Battle) Act(action comp.PlayerAction) error {
+	b.actions = append(b.actions, RecordedAction{Frame: b.frame, PlayerAction: action})
+	switch action.Kind {
+	case comp.PauseAction:
+		b.battleState.Paused = !b.battleState.Paused
+	case comp.SpeedAction:
+		b.SetSpeed(action.Value)
+	case comp.SendSuperCreepAction:
+		// the scene sends it over the network, it is only recorded so the replay shows it
+	case comp.SuperCreepAction:
+		player := comp.Player.Get(comp.Player.MustFirst(b.world))
+		_, err := comp.NewSuperCreep(b.world, 0, 0, player.GetCreepLevel(config.GetBalance(b.world)))
+		return err
+	default:
+		pe := comp.Player.MustFirst(b.world)
+		return comp.Player.Get(pe).ApplyAction(pe, action)
+	}
+	return nil
+}
+
+func (b *Battle) UpdateEntities() error {
+	// put the aura buffs back in line with the towers placed, removed or changed since the last tick
+	comp.UpdateAuras(b.world)
+
+	query := donburi.NewQuery(
+		filter.And(
+			filter.Or(
+				filter.Contains(comp.Creep),
+				filter.Contains(comp.Tower),
+				filter.Contains(comp.Bullet),
+				filter.Contains(comp.Beam),
+				filter.Contains(comp.Hazard),
+			),
+		),
+	)
+	var err error = nil
+	entries := make([]*donburi.Entry, 0, query.Count(b.world))
+	query.Each(b.world, func(entry *donburi.Entry) {
+		entries = append(entries, entry)
+	})
+
+	for _, entry := range entries {
+		if !entry.Valid() {
+			continue
+		}
+		if entry.HasComponent(comp.StatusEffects) {
+			effects := comp.StatusEffects.Get(entry)
+			err = effects.Update(entry)
+			if err != nil {
+				return err
+			}
+			// poison may have killed it
+			if !entry.Valid() {
+				continue
+			}
+		}
+		if entry.HasComponent(comp.Creep) {
+			creep := comp.Creep.Get(entry)
+			err = creep.Update(entry)
+			if err != nil {
+				return err
+			}
+
+		}
+		if entry.HasComponent(comp.Boss) {
+			boss := comp.Boss.Get(entry)
+			err = boss.Update(entry)
+			if err != nil {
+				return err
+			}
+		}
+		if entry.HasComponent(comp.Tower) {
+			tower := comp.Tower.Get(entry)
+			err = tower.Update(entry)
+			if err != nil {
+				return err
+			}
+
+		}
+
+		if entry.HasComponent(comp.Bullet) {
+			b := comp.Bullet.Get(entry)
+			err = b.Update(entry)
+			if err != nil {
+				return err
+			}
+		}
+
+		if entry.HasComponent(comp.Hazard) {
+			h := comp.Hazard.Get(entry)
+			err = h.Update(entry)
+			if err != nil {
+				return err
+			}
+		}
+
+		if entry.HasComponent(comp.Beam) {
+			beam := comp.Beam.Get(entry)
+			err = beam.Update(entry)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	// if the player's health drops to 0 then it is dead and the game is over
+	pe := comp.Player.MustFirst(b.world)
+	player := comp.Player.Get(pe)
+	playerHealth := comp.Health.Get(pe)
+	if playerHealth.Health <= 0 {
+		player.Kill()
+		b.gameStats.IncrementStat("PlayerDeaths")
+		b.End()
+	}
+
+	balance := config.GetBalance(b.world)
+	creepLevel := player.GetCreepLevel(balance)
+	wave := balance.Wave
+	if b.waves != nil && !b.waves.Done() {
+		count, err := b.waves.Update(b.world, creepLevel)
+		if err != nil {
+			return err
+		}
+		b.battleState.Wave = b.waves.Wave()
+		b.gameStats.UpdateStat("CreepsSpawned", count)
+		player.AddMoney(wave.SpawnIncomePerCreep * count)
+		return nil
+	}
+	b.creepTimer += max((creepLevel/wave.TimerLevelDivisor)+1, wave.MinCreepTick)
+	if b.creepTimer >= wave.MaxCreepTimer-creepLevel {
+		query := donburi.NewQuery(filter.Contains(comp.Creep))
+		count := query.Count(b.world)
+		if count <= wave.MaxCreepCount {
+			count, err := b.SpawnCreeps(creepLevel)
+			if err != nil {
+				return err
+			}
+			b.gameStats.UpdateStat("CreepsSpawned", count)
+			player.AddMoney(wave.SpawnIncomePerCreep * count)
+			b.creepTimer = 0
+		} else {
+			player.AddMoney(wave.OverflowIncome)
+		}
+	}
+
+	return err
+}
+
+func (b *Battle) SpawnCreeps(creepLevel int) (int, error) {
+	b.gameStats.IncrementStat("CreepWaves")
+	balance := config.GetBalance(b.world).Wave
+	// every configured number of waves, creep level increases by 1 without giving extra tower levels to the player
+	extraCreepLevel := int(math.Floor(float64(b.gameStats.GetStat("CreepWaves")) / float64(balance.ExtraCreepLevelWaves)))
+
+	levelBump := float32(creepLevel+extraCreepLevel) / float32(balance.LevelBumpDivisor)
+
+	rng := comp.GetRandom(b.world)
+	val := rng.Float32() - levelBump
+	var count = 1
+	for _, spawnChance := range balance.SpawnChances {
+		if val < spawnChance.Chance {
+			count = spawnChance.Count
+			break
+		}
+	}
+
+	for i := 0; i < count; i++ {
+		be := comp.Board.MustFirst(b.world)
+		board := comp.Board.Get(be)
+
+		x := rng.IntN(board.Width/count) + board.Width/count*(i)
+		y := balance.SpawnBorder
+		if x < balance.SpawnBorder {
+			x = balance.SpawnBorder
+		} else if x > board.Width-balance.SpawnBorder {
+			x = board.Width - balance.SpawnBorder
+		}
+		_, err := comp.NewCreep(b.world, x, y, creepLevel)
+		if err != nil {
+			return i, err
+		}
+	}
+
+	// every so many waves a boss joins in the middle
+	boss := config.GetBalance(b.world).Boss
+	if boss.WaveInterval > 0 && b.gameStats.GetStat("CreepWaves")%boss.WaveInterval == 0 {
+		board := comp.Board.Get(comp.Board.MustFirst(b.world))
+		_, err := comp.NewBoss(b.world, board.Width/2, balance.SpawnBorder, boss.Type, creepLevel, boss.HealthMultiplier)
+		if err != nil {
+			return count, err
+		}
+		count++
+	}
+	return count, nil
+}
+
+func (b *Battle) End() {
+	if b.config.Sound {
+		assets.PlaySound("killed")
+	}
+	b.battleState.GameOver = true
+	b.gameStats.FinalizeTime()
+}
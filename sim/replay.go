@@ -0,0 +1,207 @@
+package sim
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	comp "tower-defense/components"
+	"tower-defense/config"
+)
+
+// ReplayVersion is bumped whenever a change to the replay file or the rules would play old replays differently
+const ReplayVersion = 1
+
+// RecordedAction is a player action and the battle frame it happened on
+type RecordedAction struct {
+	Frame int `json:"frame"`
+	comp.PlayerAction
+}
+
+// ReplayData is everything needed to play a battle again: how it was set up and what the player did.
+// The game rolls everything else from the seed, so the same balance and wave script replay it exactly.
+type ReplayData struct {
+	Version     int    `json:"version"`
+	Seed        uint64 `json:"seed"`
+	BalanceHash string `json:"balanceHash"`
+	WavesHash   string `json:"wavesHash"`
+	Width       int    `json:"width"`
+	Height      int    `json:"height"`
+	// Speed is the game speed the battle started at
+	Speed              int  `json:"speed"`
+	StartingTowerLevel int  `json:"startingTowerLevel"`
+	ScriptedWaves      bool `json:"scriptedWaves"`
+	Computer           bool `json:"computer"`
+	// ComputerTimeScaler is how often the computer player acts, from its difficulty level
+	ComputerTimeScaler int `json:"computerTimeScaler,omitempty"`
+	// Frames is how many frames the battle ran before it ended or the replay was saved
+	Frames  int              `json:"frames"`
+	Actions []RecordedAction `json:"actions"`
+}
+
+// Replay captures the battle so far, it can be saved at any time and plays back up to the current frame
+func (b *Battle) Replay() *ReplayData {
+	board := comp.Board.Get(comp.Board.MustFirst(b.world))
+	replay := &ReplayData{
+		Version:            ReplayVersion,
+		Seed:               b.battleState.Seed,
+		BalanceHash:        config.GetBalance(b.world).Hash(),
+		WavesHash:          config.GetWaveScript(b.world).Hash(),
+		Width:              board.Width,
+		Height:             board.Height,
+		Speed:              b.startSpeed,
+		StartingTowerLevel: b.startingTowerLevel,
+		ScriptedWaves:      b.waves != nil,
+		Computer:           b.config.Computer,
+		Frames:             b.frame,
+		Actions:            append([]RecordedAction{}, b.actions...),
+	}
+	if replay.Computer {
+		replay.ComputerTimeScaler = b.computerTimeScaler
+	}
+	return replay
+}
+
+// Save writes the replay as compact JSON, creating its directory if needed
+func (r *ReplayData) Save(path string) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	bytes, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, bytes, 0644)
+}
+
+// LoadReplay reads a replay file, refusing versions this build can't play the same way
+func LoadReplay(path string) (*ReplayData, error) {
+	bytes, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var replay ReplayData
+	if err := json.Unmarshal(bytes, &replay); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if replay.Version != ReplayVersion {
+		return nil, fmt.Errorf("%s: replay version %v can't be played, want version %v", path, replay.Version, ReplayVersion)
+	}
+	if replay.Computer && replay.ComputerTimeScaler <= 0 {
+		return nil, fmt.Errorf("%s: computer replay has no computer time scaler", path)
+	}
+	return &replay, nil
+}
+
+// Replayer plays a replay back in its own world, feeding the recorded actions to the battle at their frames
+type Replayer struct {
+	replay  *ReplayData
+	options Options
+	battle  *Battle
+	// next is the index of the next action to play
+	next int
+}
+
+// NewReplayer checks the replay is played with the balance and wave script it was recorded with and starts it from the beginning
+func NewReplayer(replay *ReplayData, balance *config.BalanceData, waves *config.WaveScriptData) (*Replayer, error) {
+	if balance.Hash() != replay.BalanceHash {
+		return nil, fmt.Errorf("replay was recorded with a different balance, load the same balance with -balance")
+	}
+	if replay.ScriptedWaves && waves.Hash() != replay.WavesHash {
+		return nil, fmt.Errorf("replay was recorded with a different wave script, load the same script with -waves")
+	}
+	r := &Replayer{
+		replay: replay,
+		options: Options{
+			Width:              replay.Width,
+			Height:             replay.Height,
+			Speed:              replay.Speed,
+			StartingTowerLevel: replay.StartingTowerLevel,
+			ScriptedWaves:      replay.ScriptedWaves,
+			Seed:               replay.Seed,
+			Balance:            balance,
+			Waves:              waves,
+		},
+	}
+	return r, r.Restart()
+}
+
+// Restart plays the replay again from the first frame in a fresh world
+func (r *Replayer) Restart() error {
+	world, gameOptions := NewWorld(r.options)
+	gameOptions.Computer = r.replay.Computer
+	if r.battle != nil {
+		// keep the display toggles across restarts
+		old := r.battle.Config()
+		gameOptions.Debug, gameOptions.GridLines, gameOptions.ShowStats, gameOptions.Sound = old.Debug, old.GridLines, old.ShowStats, old.Sound
+	}
+	battle, err := NewBattle(world, r.options.Width, r.options.Height, r.options.Speed, nil, gameOptions, r.options.StartingTowerLevel)
+	if err != nil {
+		return err
+	}
+	if r.replay.Computer {
+		// the computer plays at the recorded difficulty without changing the game's own
+		battle.computerTimeScaler = r.replay.ComputerTimeScaler
+	}
+	err = battle.Init()
+	if err != nil {
+		return err
+	}
+	r.battle = battle
+	r.next = 0
+	return nil
+}
+
+func (r *Replayer) Battle() *Battle {
+	return r.battle
+}
+
+func (r *Replayer) Replay() *ReplayData {
+	return r.replay
+}
+
+// Done reports whether the battle ended or has played every recorded frame
+func (r *Replayer) Done() bool {
+	return r.battle.State().GameOver || (r.battle.Frame() >= r.replay.Frames && r.next >= len(r.replay.Actions))
+}
+
+// Step plays the actions recorded at the current frame, then updates the battle one frame
+func (r *Replayer) Step() error {
+	if r.Done() {
+		return nil
+	}
+	frame := r.battle.Frame()
+	for r.next < len(r.replay.Actions) && r.replay.Actions[r.next].Frame <= frame {
+		err := r.battle.Act(r.replay.Actions[r.next].PlayerAction)
+		if err != nil {
+			return err
+		}
+		r.next++
+	}
+	// pausing doesn't use up frames, so a recorded pause is unpaused within the same frame unless the replay was saved while paused
+	return r.battle.Update(DefaultTPS)
+}
+
+// JumpTo plays forward to the frame, restarting first when the frame has already been played
+func (r *Replayer) JumpTo(frame int) error {
+	if frame < r.battle.Frame() {
+		err := r.Restart()
+		if err != nil {
+			return err
+		}
+	}
+	for r.battle.Frame() < frame && !r.Done() {
+		before := r.battle.Frame()
+		err := r.Step()
+		if err != nil {
+			return err
+		}
+		if r.battle.Frame() == before {
+			// left paused with actions still to come, the replay can't go any further
+			break
+		}
+	}
+	return nil
+}
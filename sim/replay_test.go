@@ -0,0 +1,186 @@
+package sim
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	comp "tower-defense/components"
+	"tower-defense/config"
+	"tower-defense/strategy"
+)
+
+// playRecordedGame plays a player's game in a fresh world, doing each action at its frame, and returns the battle
+func playRecordedGame(t *testing.T, options Options, frames int, actions []RecordedAction) *Battle {
+	t.Helper()
+	world, gameOptions := NewWorld(options)
+	gameOptions.Computer = false
+	battle, err := NewBattle(world, options.Width, options.Height, options.Speed, nil, gameOptions, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := battle.Init(); err != nil {
+		t.Fatal(err)
+	}
+	next := 0
+	for battle.Frame() < frames && !battle.State().GameOver {
+		for next < len(actions) && actions[next].Frame == battle.Frame() {
+			if err := battle.Act(actions[next].PlayerAction); err != nil {
+				t.Fatal(err)
+			}
+			next++
+		}
+		if err := battle.Update(DefaultTPS); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return battle
+}
+
+func testActions() []RecordedAction {
+	return []RecordedAction{
+		{Frame: 0, PlayerAction: comp.PlayerAction{Kind: comp.ClickAction, X: 150, Y: 500}},
+		{Frame: 0, PlayerAction: comp.PlayerAction{Kind: comp.SelectTowerAction, Value: 3}},
+		{Frame: 30, PlayerAction: comp.PlayerAction{Kind: comp.ClickAction, X: 300, Y: 500}},
+		{Frame: 30, PlayerAction: comp.PlayerAction{Kind: comp.PauseAction}},
+		{Frame: 30, PlayerAction: comp.PlayerAction{Kind: comp.SpeedAction, Value: 30}},
+		{Frame: 30, PlayerAction: comp.PlayerAction{Kind: comp.PauseAction}},
+		{Frame: 200, PlayerAction: comp.PlayerAction{Kind: comp.UpgradeAction, X: 155, Y: 505}},
+		{Frame: 400, PlayerAction: comp.PlayerAction{Kind: comp.SpeedAction, Value: 60}},
+		{Frame: 450, PlayerAction: comp.PlayerAction{Kind: comp.ClickAction, X: 450, Y: 500}},
+	}
+}
+
+func TestReplayPlaysRecordedGameAgain(t *testing.T) {
+	options := newTestOptions(t)
+	options.Seed = 7
+	recorded := playRecordedGame(t, options, 900, testActions())
+	if recorded.Stats().GetStat("TowersBuilt") == 0 {
+		t.Fatal("TowersBuilt = 0, want the recorded clicks to place towers")
+	}
+
+	path := filepath.Join(t.TempDir(), "replay.json")
+	if err := recorded.Replay().Save(path); err != nil {
+		t.Fatal(err)
+	}
+	replay, err := LoadReplay(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(replay.Actions) != len(testActions()) || replay.Frames != recorded.Frame() {
+		t.Fatalf("replay has %v actions over %v frames, want %v over %v", len(replay.Actions), replay.Frames, len(testActions()), recorded.Frame())
+	}
+
+	replayer, err := NewReplayer(replay, options.Balance, options.Waves)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := replayer.JumpTo(replay.Frames); err != nil {
+		t.Fatal(err)
+	}
+	if !replayer.Done() {
+		t.Errorf("Done() = false after playing every frame")
+	}
+	assertSameBattle(t, recorded, replayer.Battle())
+
+	// jumping back plays it again from the start
+	if err := replayer.JumpTo(100); err != nil {
+		t.Fatal(err)
+	}
+	if replayer.Battle().Frame() != 100 {
+		t.Errorf("Frame() = %v after jumping back, want 100", replayer.Battle().Frame())
+	}
+	if err := replayer.JumpTo(replay.Frames); err != nil {
+		t.Fatal(err)
+	}
+	assertSameBattle(t, recorded, replayer.Battle())
+}
+
+func assertSameBattle(t *testing.T, want, got *Battle) {
+	t.Helper()
+	if !reflect.DeepEqual(want.Stats().Counters(), got.Stats().Counters()) {
+		t.Errorf("replayed stats = %v, want %v", got.Stats().Counters(), want.Stats().Counters())
+	}
+	if want.Speed() != got.Speed() {
+		t.Errorf("replayed speed = %v, want %v", got.Speed(), want.Speed())
+	}
+	wantPlayer := comp.Player.Get(comp.Player.MustFirst(want.World()))
+	gotPlayer := comp.Player.Get(comp.Player.MustFirst(got.World()))
+	if wantPlayer.Money != gotPlayer.Money || wantPlayer.Score != gotPlayer.Score {
+		t.Errorf("replayed money %v score %v, want money %v score %v", gotPlayer.Money, gotPlayer.Score, wantPlayer.Money, wantPlayer.Score)
+	}
+}
+
+func TestNewReplayerRefusesDifferentBalance(t *testing.T) {
+	options := newTestOptions(t)
+	recorded := playRecordedGame(t, options, 10, nil)
+
+	balance := *config.DefaultBalance()
+	balance.Player.StartingMoney++
+	_, err := NewReplayer(recorded.Replay(), &balance, options.Waves)
+	if err == nil || !strings.Contains(err.Error(), "balance") {
+		t.Errorf("NewReplayer() with a changed balance error = %v, want a balance mismatch", err)
+	}
+}
+
+func TestLoadReplayRefusesOtherVersions(t *testing.T) {
+	options := newTestOptions(t)
+	replay := playRecordedGame(t, options, 10, nil).Replay()
+	replay.Version = ReplayVersion + 1
+	path := filepath.Join(t.TempDir(), "replay.json")
+	if err := replay.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadReplay(path); err == nil {
+		t.Errorf("LoadReplay() of version %v returned no error", replay.Version)
+	}
+}
+
+func TestLoadReplayRefusesComputerReplayWithoutTimeScaler(t *testing.T) {
+	replay := &ReplayData{Version: ReplayVersion, Computer: true}
+	path := filepath.Join(t.TempDir(), "replay.json")
+	if err := replay.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadReplay(path); err == nil {
+		t.Error("LoadReplay() of a computer replay without a time scaler returned no error")
+	}
+}
+
+func TestReplayPlaysComputerGameAgain(t *testing.T) {
+	options := newTestOptions(t)
+	options.Seed = 11
+	world, gameOptions := NewWorld(options)
+	recorded, err := NewBattle(world, options.Width, options.Height, options.Speed, nil, gameOptions, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := recorded.Init(); err != nil {
+		t.Fatal(err)
+	}
+	for recorded.Frame() < 1500 && !recorded.State().GameOver {
+		if err := recorded.Update(DefaultTPS); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// the game's difficulty changing since doesn't change how the replay plays, and replaying doesn't change it back
+	scaler := strategy.TimeScaler
+	t.Cleanup(func() { strategy.TimeScaler = scaler })
+	strategy.TimeScaler = scaler * 2
+	replayer, err := NewReplayer(recorded.Replay(), options.Balance, options.Waves)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := replayer.JumpTo(recorded.Frame()); err != nil {
+		t.Fatal(err)
+	}
+	if !replayer.Battle().Config().Computer {
+		t.Errorf("replay of a computer game played without the computer player")
+	}
+	assertSameBattle(t, recorded, replayer.Battle())
+	if strategy.TimeScaler != scaler*2 {
+		t.Errorf("strategy.TimeScaler = %v after replaying, want the game's own %v", strategy.TimeScaler, scaler*2)
+	}
+}
@@ -0,0 +1,86 @@
+package sim
+
+import (
+	"fmt"
+
+	comp "tower-defense/components"
+	"tower-defense/config"
+
+	"github.com/yohamta/donburi"
+)
+
+// Options set up a headless game played by the computer player
+type Options struct {
+	Width, Height      int
+	Speed              int
+	StartingTowerLevel int
+	ScriptedWaves      bool
+	// Seed fixes the game's random seed, 0 generates one
+	Seed uint64
+	// MaxTicks stops a game that is still going after this many game speed ticks, 0 for no limit
+	MaxTicks int
+	Balance  *config.BalanceData
+	Waves    *config.WaveScriptData
+}
+
+// Result is how a headless game went, written out as one JSON line per game
+type Result struct {
+	Game       int    `json:"game"`
+	Seed       uint64 `json:"seed"`
+	Score      int    `json:"score"`
+	CreepLevel int    `json:"creepLevel"`
+	// Ticks is how many game speed ticks the game lasted
+	Ticks int `json:"ticks"`
+	// TimedOut is set when the game hit the tick limit before the player died
+	TimedOut bool           `json:"timedOut"`
+	Stats    map[string]int `json:"stats"`
+}
+
+// NewWorld creates a world with the balance, wave script and options for a headless game, sound is always off
+func NewWorld(options Options) (donburi.World, *config.ConfigData) {
+	world := donburi.NewWorld()
+	config.NewBalance(world, options.Balance)
+	config.NewWaveScript(world, options.Waves)
+	gameOptions := config.NewConfig(world, false, true, false)
+	gameOptions.ScriptedWaves = options.ScriptedWaves
+	gameOptions.Seed = options.Seed
+	return world, gameOptions
+}
+
+// Run plays one game with the computer player as fast as it can step frames, until the player dies or the tick limit is hit.
+// The images must already be loaded with assets.LoadHeadlessAssets.
+func Run(options Options) (*Result, error) {
+	// at speed 0 the game is paused and would never end
+	if options.Speed <= 0 {
+		return nil, fmt.Errorf("headless game speed must be at least 1, got %v", options.Speed)
+	}
+	world, gameOptions := NewWorld(options)
+	battle, err := NewBattle(world, options.Width, options.Height, options.Speed, nil, gameOptions, options.StartingTowerLevel)
+	if err != nil {
+		return nil, err
+	}
+	err = battle.Init()
+	if err != nil {
+		return nil, err
+	}
+
+	for !battle.State().GameOver {
+		if options.MaxTicks > 0 && battle.Ticks() >= options.MaxTicks {
+			break
+		}
+		err = battle.Update(DefaultTPS)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	player := comp.Player.Get(comp.Player.MustFirst(world))
+	return &Result{
+		Seed:       battle.Seed(),
+		Score:      player.GetScore(),
+		CreepLevel: player.GetCreepLevel(config.GetBalance(world)),
+		Ticks:      battle.Ticks(),
+		TimedOut:   !battle.State().GameOver,
+		Stats:      battle.Stats().Counters(),
+	}, nil
+}
@@ -0,0 +1,106 @@
+package sim
+
+import (
+	"reflect"
+	"testing"
+
+	"tower-defense/assets"
+	"tower-defense/config"
+)
+
+func newTestOptions(t *testing.T) Options {
+	t.Helper()
+	if err := assets.LoadHeadlessAssets(); err != nil {
+		t.Fatal(err)
+	}
+	waves, err := config.LoadWaveScript("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return Options{Width: 600, Height: 800, Speed: 60, Balance: config.DefaultBalance(), Waves: waves}
+}
+
+func TestRunStopsAtMaxTicks(t *testing.T) {
+	options := newTestOptions(t)
+	options.MaxTicks = 50
+
+	result, err := Run(options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.TimedOut {
+		t.Errorf("TimedOut = false, want true for a game stopped at %v ticks", options.MaxTicks)
+	}
+	if result.Ticks != options.MaxTicks {
+		t.Errorf("Ticks = %v, want %v", result.Ticks, options.MaxTicks)
+	}
+	if result.Stats["CreepsSpawned"] == 0 {
+		t.Errorf("CreepsSpawned = 0, want creeps spawned in %v ticks", options.MaxTicks)
+	}
+}
+
+func TestRunRefusesPausedSpeed(t *testing.T) {
+	options := newTestOptions(t)
+	options.Speed = 0
+	options.MaxTicks = 50
+
+	if _, err := Run(options); err == nil {
+		t.Error("Run() at speed 0 = nil error, want an error instead of a game that never ends")
+	}
+}
+
+func TestRunPlaysUntilGameOver(t *testing.T) {
+	options := newTestOptions(t)
+	// seed 7 loses after about 1600 ticks, the limit fails a game that holds out instead of hanging
+	options.Seed = 7
+	options.MaxTicks = 20000
+
+	result, err := Run(options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.TimedOut {
+		t.Fatalf("TimedOut = true after %v ticks, want the game played until the player dies", result.Ticks)
+	}
+	if result.Stats["PlayerDeaths"] != 1 {
+		t.Errorf("PlayerDeaths = %v, want 1", result.Stats["PlayerDeaths"])
+	}
+	if result.Stats["TowersBuilt"] == 0 {
+		t.Errorf("TowersBuilt = 0, want the computer player to build towers")
+	}
+}
+
+func TestRunWithoutBalanceUsesDefault(t *testing.T) {
+	options := newTestOptions(t)
+	options.Balance = nil
+	options.MaxTicks = 50
+
+	result, err := Run(options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.CreepLevel == 0 {
+		t.Errorf("CreepLevel = 0, want the default balance's creep level")
+	}
+}
+
+func TestRunSameSeedPlaysSameGame(t *testing.T) {
+	options := newTestOptions(t)
+	options.Seed = 42
+	options.MaxTicks = 600
+
+	first, err := Run(options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := Run(options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(first, second) {
+		t.Errorf("games with seed %v differ:\n%+v\n%+v", options.Seed, first, second)
+	}
+	if first.Seed != options.Seed {
+		t.Errorf("Seed = %v, want %v", first.Seed, options.Seed)
+	}
+}
@@ -0,0 +1,143 @@
+package sim
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	comp "tower-defense/components"
+	"tower-defense/config"
+)
+
+// SaveVersion is bumped whenever a change to the save file or the rules means older saves can't be resumed
+const SaveVersion = 1
+
+// BattleSave is a battle in progress, everything needed to carry on playing it from where it was saved
+type BattleSave struct {
+	Version     int    `json:"version"`
+	BalanceHash string `json:"balanceHash"`
+	WavesHash   string `json:"wavesHash"`
+	Width       int    `json:"width"`
+	Height      int    `json:"height"`
+	// Speed is the game speed when the battle was saved, StartSpeed the one it started at for its replay
+	Speed              int  `json:"speed"`
+	StartSpeed         int  `json:"startSpeed"`
+	StartingTowerLevel int  `json:"startingTowerLevel"`
+	ScriptedWaves      bool `json:"scriptedWaves"`
+	// the battle's timers, so waves and game speed ticks come when they would have
+	CreepTimer     int                   `json:"creepTimer"`
+	TickCounter    int                   `json:"tickCounter"`
+	ComputerTicker int                   `json:"computerTicker"`
+	Ticks          int                   `json:"ticks"`
+	Frame          int                   `json:"frame"`
+	State          comp.BattleSceneState `json:"state"`
+	Waves          *comp.WaveRunnerSave  `json:"waves,omitempty"`
+	// Stats are the battle's counters so far and GameTime how long it has been played
+	Stats    map[string]int  `json:"stats"`
+	GameTime time.Duration   `json:"gameTime"`
+	World    *comp.WorldSave `json:"world"`
+	// Actions are the player's actions so far, so the replay of a resumed battle still covers all of it
+	Actions []RecordedAction `json:"actions"`
+}
+
+// SaveState captures the battle as it is now, to resume it later
+func (b *Battle) SaveState() (*BattleSave, error) {
+	world, err := comp.SaveWorld(b.world)
+	if err != nil {
+		return nil, err
+	}
+	board := comp.Board.Get(comp.Board.MustFirst(b.world))
+	save := &BattleSave{
+		Version:            SaveVersion,
+		BalanceHash:        config.GetBalance(b.world).Hash(),
+		WavesHash:          config.GetWaveScript(b.world).Hash(),
+		Width:              board.Width,
+		Height:             board.Height,
+		Speed:              b.speed,
+		StartSpeed:         b.startSpeed,
+		StartingTowerLevel: b.startingTowerLevel,
+		ScriptedWaves:      b.waves != nil,
+		CreepTimer:         b.creepTimer,
+		TickCounter:        b.tickCounter,
+		ComputerTicker:     b.computerTicker,
+		Ticks:              b.ticks,
+		Frame:              b.frame,
+		State:              *b.battleState,
+		Stats:              b.gameStats.Counters(),
+		GameTime:           b.gameStats.RunningTime(),
+		World:              world,
+		Actions:            append([]RecordedAction{}, b.actions...),
+	}
+	if b.waves != nil {
+		save.Waves = b.waves.Save()
+	}
+	return save, nil
+}
+
+// Resume clears the battle and puts the saved one in its place, refusing saves made with a different balance, wave script or board
+func (b *Battle) Resume(save *BattleSave) error {
+	if config.GetBalance(b.world).Hash() != save.BalanceHash {
+		return fmt.Errorf("battle was saved with a different balance, load the same balance with -balance")
+	}
+	if save.ScriptedWaves && config.GetWaveScript(b.world).Hash() != save.WavesHash {
+		return fmt.Errorf("battle was saved with a different wave script, load the same script with -waves")
+	}
+	board := comp.Board.Get(comp.Board.MustFirst(b.world))
+	if board.Width != save.Width || board.Height != save.Height {
+		return fmt.Errorf("battle was saved on a %vx%v board, start the game with -width %v -height %v", save.Width, save.Height, save.Width, save.Height)
+	}
+
+	b.Clear()
+	*b.battleState = save.State
+	b.speed = save.Speed
+	b.startSpeed = save.StartSpeed
+	b.startingTowerLevel = save.StartingTowerLevel
+	b.creepTimer = save.CreepTimer
+	b.tickCounter = save.TickCounter
+	b.computerTicker = save.ComputerTicker
+	b.ticks = save.Ticks
+	b.frame = save.Frame
+	b.actions = append([]RecordedAction{}, save.Actions...)
+	b.waves = nil
+	if save.Waves != nil {
+		b.waves = comp.LoadWaveRunner(config.GetWaveScript(b.world), save.Waves)
+	}
+	b.gameStats.Resume(save.Stats, save.GameTime)
+
+	err := b.newBattleState()
+	if err != nil {
+		return err
+	}
+	return comp.LoadWorld(b.world, save.State.Seed, save.World)
+}
+
+// Save writes the battle save as compact JSON, creating its directory if needed
+func (s *BattleSave) Save(path string) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	bytes, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, bytes, 0644)
+}
+
+// LoadBattleSave reads a battle save, refusing versions this build can't resume
+func LoadBattleSave(path string) (*BattleSave, error) {
+	bytes, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var save BattleSave
+	if err := json.Unmarshal(bytes, &save); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if save.Version != SaveVersion {
+		return nil, fmt.Errorf("%s: save version %v can't be resumed, want version %v", path, save.Version, SaveVersion)
+	}
+	return &save, nil
+}
@@ -0,0 +1,119 @@
+package sim
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"tower-defense/config"
+)
+
+// saveGame writes the battle's save to a file and returns its path
+func saveGame(t *testing.T, battle *Battle) string {
+	t.Helper()
+	save, err := battle.SaveState()
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "save.json")
+	if err := save.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// resumeGame loads the save into a fresh world, the way the game resumes it from the title screen
+func resumeGame(t *testing.T, options Options, path string) *Battle {
+	t.Helper()
+	save, err := LoadBattleSave(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	world, gameOptions := NewWorld(options)
+	gameOptions.Computer = false
+	resumed, err := NewBattle(world, options.Width, options.Height, options.Speed, nil, gameOptions, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := resumed.Resume(save); err != nil {
+		t.Fatal(err)
+	}
+	return resumed
+}
+
+func TestResumedBattlePlaysOnTheSame(t *testing.T) {
+	options := newTestOptions(t)
+	options.Seed = 11
+	battle := playRecordedGame(t, options, 500, testActions())
+	path := saveGame(t, battle)
+	frame, ticks := battle.Frame(), battle.Ticks()
+
+	// the stats are global so the battles take turns rather than running side by side
+	for range 1500 {
+		if err := battle.Update(DefaultTPS); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	resumed := resumeGame(t, options, path)
+	if resumed.Frame() != frame || resumed.Ticks() != ticks || resumed.Seed() != battle.Seed() {
+		t.Fatalf("resumed at frame %v tick %v seed %v, want frame %v tick %v seed %v", resumed.Frame(), resumed.Ticks(), resumed.Seed(), frame, ticks, battle.Seed())
+	}
+	for range 1500 {
+		if err := resumed.Update(DefaultTPS); err != nil {
+			t.Fatal(err)
+		}
+	}
+	assertSameBattle(t, battle, resumed)
+	if len(resumed.Replay().Actions) != len(testActions()) {
+		t.Fatalf("resumed replay has %v actions, want the %v from before the save", len(resumed.Replay().Actions), len(testActions()))
+	}
+
+	// the replay of the resumed battle plays the whole battle from the start
+	replayer, err := NewReplayer(resumed.Replay(), options.Balance, options.Waves)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := replayer.JumpTo(resumed.Frame()); err != nil {
+		t.Fatal(err)
+	}
+	assertSameBattle(t, resumed, replayer.Battle())
+}
+
+func TestResumeRefusesDifferentBalance(t *testing.T) {
+	options := newTestOptions(t)
+	battle := playRecordedGame(t, options, 10, nil)
+	save, err := battle.SaveState()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	balance := *config.DefaultBalance()
+	balance.Player.StartingMoney++
+	options.Balance = &balance
+	world, gameOptions := NewWorld(options)
+	resumed, err := NewBattle(world, options.Width, options.Height, options.Speed, nil, gameOptions, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = resumed.Resume(save)
+	if err == nil || !strings.Contains(err.Error(), "different balance") {
+		t.Errorf("Resume() error = %v, want a different balance error", err)
+	}
+}
+
+func TestLoadBattleSaveRefusesOtherVersions(t *testing.T) {
+	options := newTestOptions(t)
+	save, err := playRecordedGame(t, options, 10, nil).SaveState()
+	if err != nil {
+		t.Fatal(err)
+	}
+	save.Version = SaveVersion + 1
+	path := filepath.Join(t.TempDir(), "save.json")
+	if err := save.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadBattleSave(path); err == nil {
+		t.Error("LoadBattleSave() loaded a save from another version")
+	}
+}
@@ -0,0 +1,198 @@
+package sim
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	comp "tower-defense/components"
+	"tower-defense/config"
+)
+
+// SweepResult is how the games of one sweep setting went, averaged over its games
+type SweepResult struct {
+	// Values are the setting's value for each swept path
+	Values   []float64
+	Games    int
+	TimedOut int
+	// MeanTicks is how long the base survived in game speed ticks
+	MeanTicks      float64
+	MeanScore      float64
+	MeanCreepLevel float64
+}
+
+// SweepReport is the result of every setting of a balance sweep
+type SweepReport struct {
+	Paths []string
+	// Seed is the seed of each setting's first game, each game after adds 1
+	Seed  uint64
+	Games int
+	// Results has the base balance first, then every combination of the sweep in order
+	Results []SweepResult
+}
+
+// Sweep plays games with the computer player for the options' balance and then for every combination of the sweep's values on top of it.
+// Every setting plays the same seeds so they are compared on the same games. Progress is called after each setting when it isn't nil.
+func Sweep(options Options, spec *config.SweepSpec, games int, progress func(setting int, result SweepResult)) (*SweepReport, error) {
+	if options.Balance == nil {
+		options.Balance = config.DefaultBalance()
+	}
+	paths := spec.Paths()
+	base, err := options.Balance.Values(paths)
+	if err != nil {
+		return nil, err
+	}
+	// build every balance before playing so a bad path or value fails straight away
+	settings := [][]float64{base}
+	balances := []*config.BalanceData{options.Balance}
+	for _, values := range spec.Combinations() {
+		balance, err := options.Balance.WithValues(paths, values)
+		if err != nil {
+			return nil, fmt.Errorf("sweep setting %v: %w", formatSweepValues(values, " "), err)
+		}
+		settings = append(settings, values)
+		balances = append(balances, balance)
+	}
+
+	seed := options.Seed
+	if seed == 0 {
+		seed = comp.NewSeed()
+	}
+	report := &SweepReport{Paths: paths, Seed: seed, Games: games}
+	for i, balance := range balances {
+		settingOptions := options
+		settingOptions.Balance = balance
+		result := SweepResult{Values: settings[i], Games: games}
+		for game := range games {
+			settingOptions.Seed = seed + uint64(game)
+			played, err := Run(settingOptions)
+			if err != nil {
+				return nil, err
+			}
+			if played.TimedOut {
+				result.TimedOut++
+			}
+			result.MeanTicks += float64(played.Ticks) / float64(games)
+			result.MeanScore += float64(played.Score) / float64(games)
+			result.MeanCreepLevel += float64(played.CreepLevel) / float64(games)
+		}
+		report.Results = append(report.Results, result)
+		if progress != nil {
+			progress(i, result)
+		}
+	}
+	return report, nil
+}
+
+// SettingName is how reports name a setting, base for the base balance and the combination's number for the rest
+func SettingName(setting int) string {
+	if setting == 0 {
+		return "base"
+	}
+	return strconv.Itoa(setting)
+}
+
+func formatSweepValue(value float64) string {
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+func formatSweepValues(values []float64, sep string) string {
+	formatted := make([]string, len(values))
+	for i, value := range values {
+		formatted[i] = formatSweepValue(value)
+	}
+	return strings.Join(formatted, sep)
+}
+
+// WriteCSV writes one row per setting with its values and mean results
+func (r *SweepReport) WriteCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+	header := append(append([]string{"setting"}, r.Paths...), "games", "timedOut", "meanTicks", "meanScore", "meanCreepLevel")
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+	for i, result := range r.Results {
+		row := []string{SettingName(i)}
+		for _, value := range result.Values {
+			row = append(row, formatSweepValue(value))
+		}
+		row = append(row,
+			strconv.Itoa(result.Games),
+			strconv.Itoa(result.TimedOut),
+			strconv.FormatFloat(result.MeanTicks, 'f', 1, 64),
+			strconv.FormatFloat(result.MeanScore, 'f', 1, 64),
+			strconv.FormatFloat(result.MeanCreepLevel, 'f', 2, 64),
+		)
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// WriteSummary writes a table of the settings with each mean's change from the base balance, then the best setting for each mean
+func (r *SweepReport) WriteSummary(w io.Writer) error {
+	if len(r.Results) == 0 {
+		return nil
+	}
+	fmt.Fprintf(w, "Balance sweep of %d settings, %d games each, seeds %d to %d\n\n", len(r.Results), r.Games, r.Seed, r.Seed+uint64(max(r.Games-1, 0)))
+
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(table, "setting\t%s\tsurvival ticks\tscore\tcreep level\ttimed out\n", strings.Join(r.Paths, "\t"))
+	base := r.Results[0]
+	for i, result := range r.Results {
+		fmt.Fprintf(table, "%s\t%s\t%s\t%s\t%s\t%d\n",
+			SettingName(i),
+			formatSweepValues(result.Values, "\t"),
+			formatSweepMean(result.MeanTicks, base.MeanTicks, 0, i == 0),
+			formatSweepMean(result.MeanScore, base.MeanScore, 0, i == 0),
+			formatSweepMean(result.MeanCreepLevel, base.MeanCreepLevel, 2, i == 0),
+			result.TimedOut,
+		)
+	}
+	if err := table.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(w)
+	best := []struct {
+		name string
+		mean func(SweepResult) float64
+	}{
+		{"Longest survival", func(r SweepResult) float64 { return r.MeanTicks }},
+		{"Highest score", func(r SweepResult) float64 { return r.MeanScore }},
+		{"Highest creep level", func(r SweepResult) float64 { return r.MeanCreepLevel }},
+	}
+	for _, b := range best {
+		top := 0
+		for i, result := range r.Results {
+			if b.mean(result) > b.mean(r.Results[top]) {
+				top = i
+			}
+		}
+		fmt.Fprintf(w, "%s: setting %s %s\n", b.name, SettingName(top), r.describeSetting(top))
+	}
+	return nil
+}
+
+// formatSweepMean shows the mean with its percent change from the base, the base itself shows just the mean
+func formatSweepMean(mean, base float64, decimals int, isBase bool) string {
+	str := strconv.FormatFloat(mean, 'f', decimals, 64)
+	if isBase || base == 0 {
+		return str
+	}
+	return fmt.Sprintf("%s (%+.1f%%)", str, (mean-base)/base*100)
+}
+
+func (r *SweepReport) describeSetting(setting int) string {
+	values := r.Results[setting].Values
+	parts := make([]string, len(values))
+	for i, value := range values {
+		parts[i] = r.Paths[i] + "=" + formatSweepValue(value)
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,66 @@
+package sim
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"tower-defense/config"
+)
+
+func TestSweepPlaysEverySettingOnTheSameSeeds(t *testing.T) {
+	options := newTestOptions(t)
+	options.Seed = 5
+	options.MaxTicks = 2000
+	money := float64(options.Balance.Player.StartingMoney)
+	spec := &config.SweepSpec{Params: []config.SweepParam{{Path: "player.startingMoney", Values: []float64{money, money * 3}}}}
+
+	settings := 0
+	report, err := Sweep(options, spec, 2, func(setting int, result SweepResult) {
+		settings++
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Results) != 3 || settings != 3 {
+		t.Fatalf("%v results with %v progress calls, want the base and 2 settings", len(report.Results), settings)
+	}
+	base, same := report.Results[0], report.Results[1]
+	if base.Values[0] != money {
+		t.Errorf("base value = %v, want the base balance's %v", base.Values[0], money)
+	}
+	if same.MeanTicks != base.MeanTicks || same.MeanScore != base.MeanScore || same.MeanCreepLevel != base.MeanCreepLevel {
+		t.Errorf("setting with the base value = %+v, want the same games as the base %+v", same, base)
+	}
+	if base.Games != 2 || base.MeanTicks == 0 {
+		t.Errorf("base played %v games lasting %v ticks, want 2 games", base.Games, base.MeanTicks)
+	}
+
+	var csv, summary bytes.Buffer
+	if err := report.WriteCSV(&csv); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(csv.String()), "\n")
+	if len(lines) != 4 || lines[0] != "setting,player.startingMoney,games,timedOut,meanTicks,meanScore,meanCreepLevel" || !strings.HasPrefix(lines[1], "base,") {
+		t.Errorf("CSV =\n%v\nwant a header and a row per setting starting with the base", csv.String())
+	}
+	if err := report.WriteSummary(&summary); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"3 settings, 2 games each, seeds 5 to 6", "survival ticks", "Longest survival: setting"} {
+		if !strings.Contains(summary.String(), want) {
+			t.Errorf("summary =\n%v\nwant it to contain %q", summary.String(), want)
+		}
+	}
+}
+
+func TestSweepRefusesBadSettingsBeforePlaying(t *testing.T) {
+	options := newTestOptions(t)
+	spec := &config.SweepSpec{Params: []config.SweepParam{{Path: "player.health", Values: []float64{10, 1.5}}}}
+	_, err := Sweep(options, spec, 1, func(setting int, result SweepResult) {
+		t.Fatalf("played setting %v before checking every setting", setting)
+	})
+	if err == nil || !strings.Contains(err.Error(), "sweep setting 1.5") {
+		t.Errorf("Sweep() error = %v, want the bad setting named", err)
+	}
+}
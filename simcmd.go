@@ -0,0 +1,108 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"io"
+	"log"
+	"os"
+
+	"tower-defense/assets"
+	"tower-defense/config"
+	"tower-defense/sim"
+	"tower-defense/strategy"
+)
+
+// simFlags are the flags for how headless games are played, shared by the sim and sweep commands
+type simFlags struct {
+	width, height, speed, towerLevel, computerLevel, maxTicks *int
+	balancePath, wavesPath                                    *string
+	scripted                                                  *bool
+}
+
+func addSimFlags(flags *flag.FlagSet) *simFlags {
+	return &simFlags{
+		width:         flags.Int("width", 600, "Board width in pixels"),
+		height:        flags.Int("height", 800, "Board height in pixels"),
+		speed:         flags.Int("speed", 60, "Game speed, min 1 max 60, frames are stepped as fast as possible either way"),
+		towerLevel:    flags.Int("level", 0, "Starting tower level to increase difficulty, 0 for default"),
+		computerLevel: flags.Int("complevel", 3, "Computer player difficulty level [1 slowest, 2 slow, 3 normal, 4 fast, 5 fastest]"),
+		maxTicks:      flags.Int("maxticks", 200000, "Stop a game still going after this many game ticks, 0 for no limit"),
+		balancePath:   flags.String("balance", "", "Path to game balance JSON config, empty for default"),
+		scripted:      flags.Bool("scripted", false, "Play scripted waves instead of random waves"),
+		wavesPath:     flags.String("waves", "", "Path to wave script JSON, empty for default, implies -scripted"),
+	}
+}
+
+// options loads the balance, waves and headless assets the flags ask for
+func (f *simFlags) options() sim.Options {
+	if *f.speed <= 0 {
+		log.Fatal("speed must be at least 1, a headless game at speed 0 is paused and never ends")
+	}
+	strategy.SetComputerLevel(*f.computerLevel)
+	balance, err := config.LoadBalance(*f.balancePath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	waves, err := config.LoadWaveScript(*f.wavesPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := waves.ValidateCreepTypes(balance); err != nil {
+		log.Fatal(err)
+	}
+	if err := assets.LoadHeadlessAssets(); err != nil {
+		log.Fatal(err)
+	}
+	return sim.Options{
+		Width:              *f.width,
+		Height:             *f.height,
+		Speed:              *f.speed,
+		StartingTowerLevel: *f.towerLevel,
+		ScriptedWaves:      *f.scripted || *f.wavesPath != "",
+		MaxTicks:           *f.maxTicks,
+		Balance:            balance,
+		Waves:              waves,
+	}
+}
+
+// createOutput opens the file to write to, stdout when the path is empty
+func createOutput(path string) (io.Writer, func()) {
+	if path == "" {
+		return os.Stdout, func() {}
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return file, func() { file.Close() }
+}
+
+// runSim plays headless games with the computer player and writes each game's result as a JSON line
+func runSim(args []string) {
+	flags := flag.NewFlagSet("sim", flag.ExitOnError)
+	games := flags.Int("games", 10, "Number of games to play")
+	outPath := flags.String("out", "", "Path to write the JSON lines to, empty for stdout")
+	seed := flags.Uint64("seed", 0, "Random seed of the first game, each game after adds 1, 0 for a new seed each game")
+	gameFlags := addSimFlags(flags)
+	_ = flags.Parse(args)
+
+	options := gameFlags.options()
+	out, closeOut := createOutput(*outPath)
+	defer closeOut()
+
+	encoder := json.NewEncoder(out)
+	for game := 1; game <= *games; game++ {
+		if *seed != 0 {
+			options.Seed = *seed + uint64(game-1)
+		}
+		result, err := sim.Run(options)
+		if err != nil {
+			log.Fatal(err)
+		}
+		result.Game = game
+		if err := encoder.Encode(result); err != nil {
+			log.Fatal(err)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package strategy
 
 import (
 	"fmt"
+	"image"
 	"math"
 	comp "tower-defense/components"
 	"tower-defense/config"
@@ -19,6 +20,15 @@ const (
 	laneSpacing    = 41
 	printTries     = false
 	playSound      = false
+	// repair once this many towers are below half health
+	repairDamagedTowers = 3
+	// freeze once this many creeps are within freezeDistance pixels of the base
+	freezeNearCreeps = 4
+	freezeDistance   = 150
+	// airstrike once this many creeps are packed within the blast
+	airstrikeCreeps = 3
+	// support towers placed behind the front row
+	maxAuraTowers = 2
 )
 
 // laneSpacing pixels between towers, towersPerRow towers across starting
@@ -50,6 +60,8 @@ func Update(world donburi.World) (bool, error) {
 	be := comp.Board.MustFirst(world)
 	board := comp.Board.Get(be)
 	debug := config.GetConfig(world).Debug
+	towerType := player.TowerType
+	placeCost, _, _ := comp.GetTowerCosts(world, towerType)
 
 	query := donburi.NewQuery(
 		filter.Or(
@@ -78,7 +90,7 @@ func Update(world donburi.World) (bool, error) {
 		pt := util.MidpointRect(comp.GetRect(creepEntry))
 		lane := findLane(lanes, pt.X)
 		if lane != -1 {
-			placed, err := player.TryPlaceTower(world, lane, board.Height/2, playSound, printTries)
+			placed, err := player.TryPlaceTower(world, lane, board.Height/2, towerType, playSound, printTries)
 			if err != nil {
 				return false, err
 			}
@@ -91,13 +103,53 @@ func Update(world donburi.World) (bool, error) {
 		}
 	}
 
-	// while we have fewer than N towers, don't you dare upgrade
-	allowUpgrades := len(towers) >= towersPerRow && player.Money >= 75
+	// pick upgrade paths for towers waiting on a choice, choosing is free but still takes our action for the tick
+	if chooseBranch(towers) {
+		if debug {
+			fmt.Printf("Chose tower upgrade path\n")
+		}
+		return true, nil
+	}
+
+	// use the base abilities in an emergency, keeping enough back to place a tower
+	kind, used, err := useAbility(world, pe, player, towers, creeps, placeCost)
+	if err != nil {
+		return false, err
+	}
+	if used {
+		if debug {
+			fmt.Printf("Used %v ability\n", kind)
+		}
+		return true, nil
+	}
+
+	// point towers at the most dangerous creeps, changing targets is free but still takes our action for the tick
+	if retargetTower(world, board, towers, creeps) {
+		if debug {
+			fmt.Printf("Changed tower targeting\n")
+		}
+		return true, nil
+	}
+
+	// keep towers firing when ammo is separate from their health
+	if emptyTower := findEmptyTower(towers); emptyTower != nil && player.TryResupplyTower(emptyTower, playSound, printTries) {
+		if debug {
+			fmt.Printf("Resupplied emptiest tower\n")
+		}
+		return true, nil
+	}
 
 	// if we have towers, if any need healing badly then heal them if < N or upgrade if >=N (and we have enough money)
 	lowestHealthTower := findLowestHealthTower(towers)
 	lowestLevelTower := findLowestLevelTower(towers)
 
+	// while we have fewer than N towers, don't you dare upgrade, and keep some money in reserve for the upgrade
+	allowUpgrades := false
+	if lowestLevelTower != nil {
+		_, _, upgradeCost := comp.GetTowerCosts(world, comp.Tower.Get(lowestLevelTower).Type)
+		allowUpgrades = len(towers) >= towersPerRow && player.Money >= upgradeCost*3/2
+	}
+
 	if lowestHealthTower != nil {
 		// having found the lowest health and lowest level tower, they are the same the just upgrade, unless we don't have enough money
 		if lowestHealthTower.Entity() == lowestLevelTower.Entity() {
@@ -154,12 +206,26 @@ func Update(world donburi.World) (bool, error) {
 		}
 	}
 
+	// once the front row is up put support towers behind it to buff the towers in front
+	if len(towers) >= towersPerRow {
+		placed, err := placeAuraTower(world, board, player, towers)
+		if err != nil {
+			return false, err
+		}
+		if placed {
+			if debug {
+				fmt.Printf("Placed aura tower behind the front row\n")
+			}
+			return true, nil
+		}
+	}
+
 	// later game if we are full on towers and full on levels then start additional rows (up to 4) of towers to upgrade
-	if player.Money > 150 && len(towers) >= towersPerRow {
+	if player.Money > placeCost*3 && len(towers) >= towersPerRow {
 		for i := 1; i <= 3; i++ {
 			newY := board.Height/2 + i*(towerHeight+10)
 			for _, lane := range lanes {
-				placed, err := player.TryPlaceTower(world, lane, newY, playSound, printTries)
+				placed, err := player.TryPlaceTower(world, lane, newY, towerType, playSound, printTries)
 				if err != nil {
 					return false, err
 				}
@@ -181,6 +247,158 @@ func Update(world donburi.World) (bool, error) {
 	return false, nil
 }
 
+// placeAuraTower puts a support tower directly behind a front row tower, the middle lanes first, up to maxAuraTowers of them
+func placeAuraTower(world donburi.World, board *comp.BoardData, player *comp.PlayerData, towers []*donburi.Entry) (bool, error) {
+	auraType := ""
+	for _, towerType := range config.GetBalance(world).Tower.Types {
+		if towerType.Aura.Active() {
+			auraType = towerType.Name
+			break
+		}
+	}
+	if auraType == "" {
+		return false, nil
+	}
+	auraTowers := 0
+	for _, towerEntry := range towers {
+		if comp.Tower.Get(towerEntry).GetTypeBalance(world).Aura.Active() {
+			auraTowers++
+		}
+	}
+	auraCost, _, _ := comp.GetTowerCosts(world, auraType)
+	if auraTowers >= maxAuraTowers || player.Money < auraCost*2 {
+		return false, nil
+	}
+	for _, lane := range lanes {
+		placed, err := player.TryPlaceTower(world, lane, board.Height/2+towerHeight+10, auraType, playSound, printTries)
+		if err != nil || placed {
+			return placed, err
+		}
+	}
+	return false, nil
+}
+
+// useAbility repairs when towers are falling, freezes creeps closing in on the base and airstrikes the biggest pack of creeps
+func useAbility(world donburi.World, pe *donburi.Entry, player *comp.PlayerData, towers, creeps []*donburi.Entry, reserve int) (comp.AbilityKind, bool, error) {
+	abilities := config.GetBalance(world).Player.Abilities
+	canUse := func(kind comp.AbilityKind, cost int) bool {
+		return player.Money >= cost+reserve && player.CanUseAbility(pe, kind)
+	}
+
+	damaged := 0
+	for _, towerEntry := range towers {
+		health := comp.Health.Get(towerEntry)
+		if health.Health*2 < health.MaxHealth {
+			damaged++
+		}
+	}
+	if damaged >= repairDamagedTowers && canUse(comp.Repair, abilities.Repair.Cost) {
+		used, err := player.TryUseAbility(pe, comp.Repair, 0, 0, playSound, printTries)
+		if err != nil || used {
+			return comp.Repair, used, err
+		}
+	}
+
+	baseY := comp.GetRect(pe).Min.Y
+	near := 0
+	for _, creepEntry := range creeps {
+		if baseY-comp.GetRect(creepEntry).Max.Y < freezeDistance {
+			near++
+		}
+	}
+	if near >= freezeNearCreeps && canUse(comp.Freeze, abilities.Freeze.Cost) {
+		used, err := player.TryUseAbility(pe, comp.Freeze, 0, 0, playSound, printTries)
+		if err != nil || used {
+			return comp.Freeze, used, err
+		}
+	}
+
+	if !canUse(comp.Airstrike, abilities.Airstrike.Cost) {
+		return comp.Airstrike, false, nil
+	}
+	// aim at the creep with the most others within the blast
+	var target image.Point
+	hits := 0
+	for _, creepEntry := range creeps {
+		pt := util.MidpointRect(comp.GetRect(creepEntry))
+		count := 0
+		for _, other := range creeps {
+			if util.GapRects(image.Rect(pt.X, pt.Y, pt.X, pt.Y), comp.GetRect(other)) <= float64(abilities.Airstrike.Radius) {
+				count++
+			}
+		}
+		if count > hits {
+			target, hits = pt, count
+		}
+	}
+	if hits >= airstrikeCreeps {
+		used, err := player.TryUseAbility(pe, comp.Airstrike, target.X, target.Y, playSound, printTries)
+		return comp.Airstrike, used, err
+	}
+	return comp.Airstrike, false, nil
+}
+
+// chooseBranch takes the upgrade option with the most damage output for the first tower waiting on a choice
+func chooseBranch(towers []*donburi.Entry) bool {
+	for _, towerEntry := range towers {
+		tower := comp.Tower.Get(towerEntry)
+		branch := tower.PendingBranch(towerEntry)
+		if branch == nil {
+			continue
+		}
+		best, bestScore := 0, -1
+		for i, option := range branch.Options {
+			// favor power, then attack speed, then area, range and health
+			score := option.PowerAdd*10 + option.CooldownReduction*2 + option.AreaRadius/2 + option.RangeAdd/3 + option.MaxHealthAdd/3
+			if score > bestScore {
+				best, bestScore = i, score
+			}
+		}
+		return tower.ChooseBranch(towerEntry, best, printTries)
+	}
+	return false
+}
+
+// retargetTower changes the targeting of the first tower that isn't using the priority we want for it
+func retargetTower(world donburi.World, board *comp.BoardData, towers, creeps []*donburi.Entry) bool {
+	superCreep := false
+	for _, creepEntry := range creeps {
+		if comp.Creep.Get(creepEntry).IsSuper() {
+			superCreep = true
+			break
+		}
+	}
+
+	for _, towerEntry := range towers {
+		attack := comp.Attack.Get(towerEntry)
+		if attack.Power <= 0 {
+			continue
+		}
+		want := wantedTargeting(world, board, towerEntry, superCreep)
+		if attack.Targeting != want {
+			attack.Targeting = want
+			return true
+		}
+	}
+	return false
+}
+
+// wantedTargeting goes after super creeps when there are any, has back row towers protect the base and otherwise uses the tower type default
+func wantedTargeting(world donburi.World, board *comp.BoardData, towerEntry *donburi.Entry, superCreep bool) comp.TargetPriority {
+	if superCreep {
+		return comp.SuperFirst
+	}
+	if comp.GetRect(towerEntry).Min.Y > board.Height/2 {
+		return comp.Furthest
+	}
+	typeBalance := comp.Tower.Get(towerEntry).GetTypeBalance(world)
+	targeting, err := comp.ParseTargetPriority(typeBalance.Targeting)
+	if err != nil {
+		return comp.Closest
+	}
+	return targeting
+}
+
 func findLowestHealthTower(towers []*donburi.Entry) *donburi.Entry {
 	var lowestHealthTower *donburi.Entry
 	var lowestHealth int = math.MaxInt
@@ -196,6 +414,23 @@ func findLowestHealthTower(towers []*donburi.Entry) *donburi.Entry {
 	return lowestHealthTower
 }
 
+// findEmptyTower finds the tower with the least ammo left below 25% of its magazine, nil when ammo isn't separate
+func findEmptyTower(towers []*donburi.Entry) *donburi.Entry {
+	var emptyTower *donburi.Entry
+	var lowestAmmo int = math.MaxInt
+	for _, towerEntry := range towers {
+		if !towerEntry.HasComponent(comp.Ammo) {
+			continue
+		}
+		ammo := comp.Ammo.Get(towerEntry)
+		if ammo.Ammo*4 < ammo.MaxAmmo && ammo.Ammo < lowestAmmo {
+			emptyTower = towerEntry
+			lowestAmmo = ammo.Ammo
+		}
+	}
+	return emptyTower
+}
+
 func findLowestLevelTower(towers []*donburi.Entry) *donburi.Entry {
 	var lowestLevelTower *donburi.Entry
 	var lowestLevel int = math.MaxInt
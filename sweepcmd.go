@@ -0,0 +1,58 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"tower-defense/config"
+	"tower-defense/sim"
+)
+
+// runSweep plays headless games for every setting of a balance sweep and writes a CSV and a text summary comparing them
+func runSweep(args []string) {
+	flags := flag.NewFlagSet("sweep", flag.ExitOnError)
+	specPath := flags.String("spec", "", "Path to the sweep spec JSON listing the balance values to try, required")
+	games := flags.Int("games", 5, "Number of games to play for each setting")
+	csvPath := flags.String("csv", "", "Path to write the CSV report to, empty for none")
+	summaryPath := flags.String("summary", "", "Path to write the text summary to, empty for stdout")
+	seed := flags.Uint64("seed", 0, "Random seed of each setting's first game, each game after adds 1, 0 for a new seed")
+	gameFlags := addSimFlags(flags)
+	_ = flags.Parse(args)
+
+	if *specPath == "" {
+		log.Fatal("sweep needs a spec, pass it with -spec")
+	}
+	if *games < 1 {
+		log.Fatal("sweep needs at least 1 game for each setting")
+	}
+	spec, err := config.LoadSweepSpec(*specPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	options := gameFlags.options()
+	options.Seed = *seed
+
+	settings := len(spec.Combinations()) + 1
+	report, err := sim.Sweep(options, spec, *games, func(setting int, result sim.SweepResult) {
+		fmt.Fprintf(os.Stderr, "setting %s (%d/%d) %v: %.0f ticks, %.0f score, %.2f creep level\n",
+			sim.SettingName(setting), setting+1, settings, result.Values, result.MeanTicks, result.MeanScore, result.MeanCreepLevel)
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if *csvPath != "" {
+		out, closeOut := createOutput(*csvPath)
+		defer closeOut()
+		if err := report.WriteCSV(out); err != nil {
+			log.Fatal(err)
+		}
+	}
+	summary, closeSummary := createOutput(*summaryPath)
+	defer closeSummary()
+	if err := report.WriteSummary(summary); err != nil {
+		log.Fatal(err)
+	}
+}
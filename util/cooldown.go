@@ -34,3 +34,26 @@ func (c *CooldownTimer) GetDisplay() int {
 	}
 	return cd
 }
+
+// CooldownSave is the full state of a timer, for saving a battle in progress
+type CooldownSave struct {
+	Cooldown   int
+	Ticker     int
+	InCooldown bool
+}
+
+// Save returns the timer's state, nil for a nil timer
+func (c *CooldownTimer) Save() *CooldownSave {
+	if c == nil {
+		return nil
+	}
+	return &CooldownSave{Cooldown: c.Cooldown, Ticker: c.ticker, InCooldown: c.InCooldown}
+}
+
+// Load creates a timer from the saved state, nil for a nil save
+func (s *CooldownSave) Load() *CooldownTimer {
+	if s == nil {
+		return nil
+	}
+	return &CooldownTimer{Cooldown: s.Cooldown, ticker: s.Ticker, InCooldown: s.InCooldown}
+}
@@ -51,3 +51,20 @@ func TestCooldownTimerDoesNotTickWhenReady(t *testing.T) {
 		t.Fatalf("ready cooldown display after IncrementTicker = %v, want 0", got)
 	}
 }
+
+func TestCooldownTimerSaveKeepsProgress(t *testing.T) {
+	cooldown := NewCooldownTimer(5)
+	cooldown.StartCooldown()
+	cooldown.IncrementTicker()
+	cooldown.IncrementTicker()
+
+	loaded := cooldown.Save().Load()
+	if got := loaded.GetDisplay(); got != 3 {
+		t.Fatalf("loaded cooldown display = %v, want 3", got)
+	}
+
+	var none *CooldownTimer
+	if none.Save().Load() != nil {
+		t.Fatal("nil cooldown loaded as a timer, want nil")
+	}
+}
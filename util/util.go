@@ -20,6 +20,13 @@ func DistancePoints(pt1, pt2 image.Point) float64 {
 	return math.Sqrt(math.Pow(float64(pt3.X), 2) + math.Pow(float64(pt3.Y), 2))
 }
 
+// GapRects returns the distance between the closest points of two rectangles, 0 if they overlap
+func GapRects(rect1, rect2 image.Rectangle) float64 {
+	dx := max(rect1.Min.X-rect2.Max.X, rect2.Min.X-rect1.Max.X, 0)
+	dy := max(rect1.Min.Y-rect2.Max.Y, rect2.Min.Y-rect1.Max.Y, 0)
+	return math.Sqrt(float64(dx*dx + dy*dy))
+}
+
 func MidpointRect(rect image.Rectangle) image.Point {
 	midX := (rect.Max.X + rect.Min.X) / 2
 	midY := (rect.Max.Y + rect.Min.Y) / 2
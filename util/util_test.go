@@ -0,0 +1,28 @@
+package util
+
+import (
+	"image"
+	"testing"
+)
+
+func TestGapRects(t *testing.T) {
+	tests := []struct {
+		name         string
+		rect1, rect2 image.Rectangle
+		want         float64
+	}{
+		{"overlapping", image.Rect(0, 0, 10, 10), image.Rect(5, 5, 15, 15), 0},
+		{"touching", image.Rect(0, 0, 10, 10), image.Rect(10, 0, 20, 10), 0},
+		{"horizontal gap", image.Rect(0, 0, 10, 10), image.Rect(14, 0, 20, 10), 4},
+		{"vertical gap reversed", image.Rect(0, 20, 10, 30), image.Rect(0, 0, 10, 10), 10},
+		{"diagonal gap", image.Rect(0, 0, 10, 10), image.Rect(13, 14, 20, 20), 5},
+		{"point inside", image.Rect(0, 0, 10, 10), image.Rect(5, 5, 5, 5), 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := GapRects(tt.rect1, tt.rect2); got != tt.want {
+				t.Errorf("GapRects() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}